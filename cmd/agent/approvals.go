@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
+)
+
+const defaultApprovalStore = "ospa-approvals.json"
+
+const approvalsUsage = `Usage: agent approvals <command> [flags]
+
+Commands:
+  list                 List approval requests (--state pending|approved|rejected|executed)
+  approve <id>         Approve a pending remediation
+  reject <id>          Reject a pending remediation
+
+Flags:
+  --store PATH         Approval store file (default: ospa-approvals.json)
+  --reviewer NAME      Reviewer recorded with the decision (default: $USER)
+  --comment TEXT       Comment recorded with the decision
+`
+
+// runApprovals implements the "approvals" subcommand and returns the exit code.
+func runApprovals(args []string, out, errOut io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(errOut, approvalsUsage)
+		return 1
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("approvals "+command, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	storePath := fs.String("store", defaultApprovalStore, "Approval store file")
+	state := fs.String("state", approval.StatePending, "Only list items in this state (empty for all)")
+	reviewer := fs.String("reviewer", os.Getenv("USER"), "Reviewer name")
+	comment := fs.String("comment", "", "Decision comment")
+
+	// Allow flags both before and after the item ID.
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	var id string
+	if fs.NArg() > 0 {
+		id = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	store, err := approval.Open(*storePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "list":
+		printApprovals(out, store.List(*state))
+		return 0
+
+	case "approve", "reject":
+		if id == "" {
+			_, _ = fmt.Fprintf(errOut, "Error: %s requires an approval ID\n", command)
+			return 1
+		}
+		decide := store.Approve
+		if command == "reject" {
+			decide = store.Reject
+		}
+		if err := decide(id, *reviewer, *comment); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			return 1
+		}
+		item, _ := store.Get(id)
+		_, _ = fmt.Fprintf(out, "%s %s: %s on %s/%s %s\n", id, item.State, item.Action, item.Service, item.ResourceType, item.ResourceID)
+		return 0
+
+	default:
+		_, _ = fmt.Fprintf(errOut, "Error: unknown approvals command %q\n\n%s", command, approvalsUsage)
+		return 1
+	}
+}
+
+func printApprovals(out io.Writer, items []approval.Item) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTATE\tRULE\tACTION\tRESOURCE\tNAME\tPROJECT\tREQUESTED")
+	for _, item := range items {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s/%s/%s\t%s\t%s\t%s\n",
+			item.ID, item.State, item.RuleID, item.Action,
+			item.Service, item.ResourceType, item.ResourceID,
+			item.ResourceName, item.ProjectID, item.RequestedAt.Format(time.RFC3339))
+	}
+	_ = tw.Flush()
+}
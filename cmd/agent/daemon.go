@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/metrics"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/orchestrator"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/schedule"
+)
+
+// daemon runs scans repeatedly on a schedule with a single orchestrator, so
+// the authenticated session and the client cache are shared by all runs.
+type daemon struct {
+	orch       *orchestrator.Orchestrator
+	policy     *policy.Policy
+	policyPath string
+	// loader remembers the documents of the loaded policy so that watching
+	// only reloads on changes.
+	loader *policy.Loader
+	// watch is the interval at which the policy source is checked for
+	// changes; 0 disables watching.
+	watch    time.Duration
+	schedule schedule.Schedule
+	// runNow starts the first scan at startup instead of waiting for the
+	// first scheduled time.
+	runNow bool
+	jitter time.Duration
+	opts   scanOptions
+
+	// watchErr is the last error reported by watching, logged once.
+	watchErr string
+}
+
+// parseSchedule builds the schedule from the --interval and --schedule
+// flags, of which exactly one may be set. An interval starts the first run
+// immediately; a cron schedule waits for its first match.
+func parseSchedule(interval, cron string) (schedule.Schedule, bool, error) {
+	switch {
+	case interval != "" && cron != "":
+		return nil, false, errors.New("--interval and --schedule are mutually exclusive")
+	case interval != "":
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid --interval: %w", err)
+		}
+		if d <= 0 {
+			return nil, false, fmt.Errorf("invalid --interval: must be positive, got %s", interval)
+		}
+		return schedule.Every(d), true, nil
+	default:
+		c, err := schedule.ParseCron(cron)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid --schedule: %w", err)
+		}
+		return c, false, nil
+	}
+}
+
+// run loops until SIGINT or SIGTERM. SIGHUP, or a change found by watching
+// the policy source, reloads the policy before the next run; an invalid
+// policy is reported and the previous one kept.
+func (d *daemon) run() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var watch <-chan time.Time
+	if d.watch > 0 {
+		ticker := time.NewTicker(d.watch)
+		defer ticker.Stop()
+		watch = ticker.C
+	}
+
+	next := time.Now()
+	if !d.runNow {
+		next = d.schedule.Next(next)
+	}
+	for {
+		if next.IsZero() {
+			log.Printf("Schedule has no upcoming runs; exiting")
+			return
+		}
+		at := next
+		if d.jitter > 0 {
+			at = at.Add(rand.N(d.jitter))
+		}
+		metrics.SetNextRun(at)
+		_, _ = fmt.Fprintf(d.console(), "Next run at %s\n", at.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(at))
+	wait:
+		for {
+			select {
+			case <-d.orch.Done():
+				timer.Stop()
+				_, _ = fmt.Fprintln(d.console(), "Shutting down")
+				return
+			case <-hup:
+				d.reload()
+			case <-watch:
+				d.reloadIfChanged()
+			case <-timer.C:
+				break wait
+			}
+		}
+
+		start := time.Now()
+		if _, err := runScan(d.orch, d.policy, d.opts); err != nil {
+			metrics.RecordError(err)
+			log.Printf("Run failed: %v", err)
+		}
+
+		// Schedule from the unjittered time so that jitter does not drift
+		// the interval.
+		now := time.Now()
+		next = d.schedule.Next(next)
+		if !next.IsZero() && next.Before(now) {
+			log.Printf("Run took %s and overran its schedule; skipping missed runs", now.Sub(start).Round(time.Second))
+			next = d.schedule.Next(now)
+		}
+	}
+}
+
+// console returns where the daemon prints its messages for the current
+// policy; see console.
+func (d *daemon) console() io.Writer {
+	// An invalid output fails the run, which reports it.
+	outputs, _ := resolveOutputs(d.opts.outputSpecs, d.opts.outPath, d.opts.outFormat, d.policy.Defaults)
+	return console(outputs)
+}
+
+func (d *daemon) reload() {
+	_, _ = fmt.Fprintf(d.console(), "Reloading policy from %s...\n", policySource(d.policyPath, d.loader.Packs))
+	p, err := d.loader.Load(d.policyPath)
+	if err != nil {
+		metrics.RecordError(fmt.Errorf("reloading policy: %w", err))
+		log.Printf("Failed to reload policy, keeping the previous one: %v", err)
+		return
+	}
+	d.watchErr = ""
+	d.apply(p)
+}
+
+// reloadIfChanged applies the policy when its documents changed since the
+// last successful load. A failure is logged once until it changes, so a
+// broken policy does not flood the log at every check.
+func (d *daemon) reloadIfChanged() {
+	p, changed, err := d.loader.LoadIfChanged(d.policyPath)
+	if err != nil {
+		if err.Error() != d.watchErr {
+			d.watchErr = err.Error()
+			metrics.RecordError(fmt.Errorf("reloading policy: %w", err))
+			log.Printf("Changed policy is invalid, keeping the previous one: %v", err)
+		}
+		return
+	}
+	d.watchErr = ""
+	if changed {
+		_, _ = fmt.Fprintf(d.console(), "Policy source %q changed\n", d.policyPath)
+		d.apply(p)
+	}
+}
+
+func (d *daemon) apply(p *policy.Policy) {
+	d.policy = p
+	d.orch.SetPolicy(p)
+	_, _ = fmt.Fprintf(d.console(), "Policy reloaded: %d service policies\n", len(p.Policies))
+}
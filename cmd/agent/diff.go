@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+)
+
+const diffUsage = `Usage: agent diff [flags] <old.json> <new.json>
+
+Compares the violations in two JSON findings files by fingerprint and lists
+new, resolved and (with --show-existing) persisting findings.
+
+Flags:
+  --format text|json   Output format (default: text)
+  --show-existing      List persisting findings, not just their count
+  --exit-code          Exit with status 2 when there are new findings
+`
+
+// runDiff implements the "diff" subcommand and returns the exit code.
+func runDiff(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() { _, _ = fmt.Fprint(errOut, diffUsage) }
+	format := fs.String("format", "text", "Output format: text, json")
+	showExisting := fs.Bool("show-existing", false, "List persisting findings")
+	exitCode := fs.Bool("exit-code", false, "Exit with status 2 when there are new findings")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 1
+	}
+
+	old, err := report.LoadFindings(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+		return 1
+	}
+	current, err := report.LoadFindings(fs.Arg(1))
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+		return 1
+	}
+	diff := report.Diff(old, current)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diffJSON(diff, *showExisting)); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			return 1
+		}
+	case "text":
+		printDiff(out, diff, *showExisting)
+	default:
+		_, _ = fmt.Fprintf(errOut, "Error: unsupported format %q (want text or json)\n", *format)
+		return 1
+	}
+
+	if *exitCode && len(diff.New) > 0 {
+		return 2
+	}
+	return 0
+}
+
+type diffOutput struct {
+	New           []report.Finding `json:"new"`
+	Resolved      []report.Finding `json:"resolved"`
+	Existing      []report.Finding `json:"existing,omitempty"`
+	ExistingCount int              `json:"existing_count"`
+}
+
+func diffJSON(d report.DiffResult, showExisting bool) diffOutput {
+	out := diffOutput{
+		New:           d.New,
+		Resolved:      d.Resolved,
+		ExistingCount: len(d.Existing),
+	}
+	if out.New == nil {
+		out.New = []report.Finding{}
+	}
+	if out.Resolved == nil {
+		out.Resolved = []report.Finding{}
+	}
+	if showExisting {
+		out.Existing = d.Existing
+	}
+	return out
+}
+
+func printDiff(out io.Writer, d report.DiffResult, showExisting bool) {
+	section := func(title, marker string, findings []report.Finding) {
+		_, _ = fmt.Fprintf(out, "%s (%d):\n", title, len(findings))
+		for _, f := range findings {
+			name := f.ResourceID
+			if f.ResourceName != "" {
+				name = f.ResourceName + " (" + f.ResourceID + ")"
+			}
+			severity := f.Severity
+			if severity == "" {
+				severity = "-"
+			}
+			_, _ = fmt.Fprintf(out, "  %s [%s] %s %s/%s %s project=%s fingerprint=%s\n",
+				marker, severity, f.RuleID, f.Service, f.ResourceType, name, f.ProjectID, f.Fingerprint)
+		}
+	}
+	section("New", "+", d.New)
+	section("Resolved", "-", d.Resolved)
+	if showExisting {
+		section("Existing", "=", d.Existing)
+	} else {
+		_, _ = fmt.Fprintf(out, "Existing: %d\n", len(d.Existing))
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/history"
+)
+
+const defaultHistoryDir = "ospa-history"
+
+const historyUsage = `Usage: agent history <command> [flags]
+
+Commands:
+  runs                 List recorded runs
+  open                 List open violations and since when they occur
+  resource <id>        Show every recorded result for a resource
+  mttr                 Mean time to remediate per rule
+
+Flags:
+  --dir PATH           History directory written with --history-dir (default: ospa-history)
+  --rule NAME          Only show this rule (open, resource)
+  --project ID         Only show this project (open)
+  --format FORMAT      Output format: text, json (default: text)
+`
+
+// runHistory implements the "history" subcommand and returns the exit code.
+func runHistory(args []string, out, errOut io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(errOut, historyUsage)
+		return 1
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("history "+command, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	dir := fs.String("dir", defaultHistoryDir, "History directory")
+	rule := fs.String("rule", "", "Only show this rule")
+	project := fs.String("project", "", "Only show this project")
+	format := fs.String("format", "text", "Output format: text, json")
+
+	// Allow flags both before and after the resource ID.
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	var id string
+	if fs.NArg() > 0 {
+		id = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+	if *format != "text" && *format != "json" {
+		_, _ = fmt.Fprintf(errOut, "Error: unknown format %q\n", *format)
+		return 1
+	}
+
+	if _, err := os.Stat(*dir); err != nil {
+		_, _ = fmt.Fprintf(errOut, "Error: no history at %q: %v\n", *dir, err)
+		return 1
+	}
+	store, err := history.Open(*dir, history.Options{})
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+		return 1
+	}
+
+	var data any
+	var printText func(io.Writer)
+	switch command {
+	case "runs":
+		runs := store.Runs()
+		data, printText = runs, func(w io.Writer) { printRuns(w, runs) }
+
+	case "open":
+		var open []history.Incident
+		for _, inc := range store.Incidents(true) {
+			if (*rule == "" || inc.RuleID == *rule) && (*project == "" || inc.ProjectID == *project) {
+				open = append(open, inc)
+			}
+		}
+		data, printText = open, func(w io.Writer) { printIncidents(w, open, time.Now()) }
+
+	case "resource":
+		if id == "" {
+			_, _ = fmt.Fprintln(errOut, "Error: resource requires a resource ID")
+			return 1
+		}
+		all, err := store.ResourceHistory(id)
+		if err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			return 1
+		}
+		var entries []history.Entry
+		for _, e := range all {
+			if *rule == "" || e.RuleID == *rule {
+				entries = append(entries, e)
+			}
+		}
+		data, printText = entries, func(w io.Writer) { printResourceHistory(w, entries) }
+
+	case "mttr":
+		mttr := store.MTTR()
+		data, printText = mttr, func(w io.Writer) { printMTTR(w, mttr) }
+
+	default:
+		_, _ = fmt.Fprintf(errOut, "Error: unknown history command %q\n\n%s", command, historyUsage)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	printText(out)
+	return 0
+}
+
+func printRuns(out io.Writer, runs []history.Run) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTARTED\tDURATION\tSCOPE\tSCANNED\tVIOLATIONS\tERRORS")
+	for _, run := range runs {
+		scope := "all"
+		if run.Service != "" || run.ResourceType != "" {
+			scope = orAny(run.Service) + "/" + orAny(run.ResourceType)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n",
+			run.ID, run.StartedAt.Format(time.RFC3339), run.FinishedAt.Sub(run.StartedAt).Round(time.Second),
+			scope, run.Scanned, run.Violations, run.Errors)
+	}
+	_ = tw.Flush()
+}
+
+func printIncidents(out io.Writer, incidents []history.Incident, now time.Time) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tSEVERITY\tRESOURCE\tNAME\tPROJECT\tSINCE\tFOR")
+	for _, inc := range incidents {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s/%s/%s\t%s\t%s\t%s\t%s\n",
+			inc.RuleID, inc.Severity, inc.Service, inc.ResourceType, inc.ResourceID,
+			inc.ResourceName, inc.ProjectID, inc.OpenedAt.Format(time.RFC3339),
+			now.Sub(inc.OpenedAt).Round(time.Minute))
+	}
+	_ = tw.Flush()
+}
+
+func printResourceHistory(out io.Writer, entries []history.Entry) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RUN\tSCANNED\tRULE\tRESULT\tOBSERVATION")
+	for _, e := range entries {
+		result := "compliant"
+		switch {
+		case e.Error != "":
+			result = "error"
+		case !e.Compliant:
+			result = "violation"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			e.RunID, e.ScannedAt.Format(time.RFC3339), e.RuleID, result, e.Observation)
+	}
+	_ = tw.Flush()
+}
+
+func printMTTR(out io.Writer, mttr []history.RuleMTTR) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tRESOLVED\tOPEN\tMTTR")
+	for _, m := range mttr {
+		mean := "-"
+		if m.Resolved > 0 {
+			mean = m.Mean.Round(time.Minute).String()
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", m.RuleID, m.Resolved, m.Open, mean)
+	}
+	_ = tw.Flush()
+}
+
+func orAny(s string) string {
+	if s == "" {
+		return "*"
+	}
+	return s
+}
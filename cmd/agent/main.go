@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"log"
@@ -8,22 +11,48 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/auth"
 	_ "github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery/services" // Register discoverers
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/history"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/metrics"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/notify"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/orchestrator"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/rollout"
 	_ "github.com/OpenStack-Policy-Agent/OSPA/pkg/services"          // Register services
 	_ "github.com/OpenStack-Policy-Agent/OSPA/pkg/services/services" // Register service implementations
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/tracing"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "approvals":
+			os.Exit(runApprovals(os.Args[2:], os.Stdout, os.Stderr))
+		case "diff":
+			os.Exit(runDiff(os.Args[2:], os.Stdout, os.Stderr))
+		case "serve":
+			os.Exit(runServe(os.Args[2:], os.Stdout, os.Stderr))
+		case "history":
+			os.Exit(runHistory(os.Args[2:], os.Stdout, os.Stderr))
+		case "packs":
+			os.Exit(runPacks(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	cloudName := flag.String("cloud", "", "The name of the cloud in clouds.yaml")
-	policyPath := flag.String("policy", "", "Path to policies.yaml")
+	policyPath := flag.String("policy", "", "Policy file, directory of YAML files, glob pattern or HTTP(S) URL")
+	var packs packFlags
+	flag.Var(&packs, "pack", "Built-in policy pack as name@version, loaded before --policy (see the packs command); repeatable")
 	outPath := flag.String("out", "", "Write findings to this file (default: policy defaults.output if set)")
-	outFormat := flag.String("out-format", "json", "Output format: json, csv")
+	var outputSpecs outputFlags
+	flag.Var(&outputSpecs, "output", "Write findings as format=path[,severity=S][,category=C][,service=S]; repeatable")
+	outFormat := flag.String("out-format", "json", "Output format: json, csv, sarif, html, junit, cef, ecs")
 	workers := flag.Int("workers", runtime.NumCPU()*8, "Number of concurrent workers")
 	fix := flag.Bool("fix", false, "Apply remediations for enforce-mode rules (default: false, dry-run)")
 	allTenants := flag.Bool("all-tenants", false, "Scan all tenants/projects (requires admin). Default: false")
@@ -33,6 +62,25 @@ func main() {
 	metricsAddr := flag.String("metrics-addr", "", "Prometheus metrics listen address (e.g., :9090)")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
 	logFormat := flag.String("log-format", "text", "Log format: text, json")
+	approvalStore := flag.String("approval-store", defaultApprovalStore, "Approval store file for rules with approval: required")
+	baselinePath := flag.String("baseline", "", "JSON findings from a previous run; marks findings new/existing and reports resolved ones")
+	syslogAddr := flag.String("syslog-addr", "", "Stream findings to this syslog collector (host:port)")
+	syslogNetwork := flag.String("syslog-network", "udp", "Syslog transport: udp, tcp, tls")
+	syslogFormat := flag.String("syslog-format", report.SyslogFormatRFC5424, "Syslog message format: rfc5424, cef, ecs")
+	syslogCA := flag.String("syslog-tls-ca", "", "PEM CA bundle used to verify the syslog collector (tls only; default: system roots)")
+	rolloutState := flag.String("rollout-state", "ospa-rollout.json", "State file tracking the active stage of rules with a rollout block")
+	interval := flag.String("interval", "", "Keep running and scan at this interval (e.g., 1h); SIGHUP reloads the policy")
+	cronSchedule := flag.String("schedule", "", "Keep running and scan on this cron schedule (e.g., \"0 */6 * * *\" or @daily)")
+	jitter := flag.Duration("jitter", 0, "Delay each scheduled run by a random duration up to this value")
+	policyWatch := flag.Duration("policy-watch", 30*time.Second, "In daemon mode, check the policy source for changes at this interval (0 disables)")
+	historyDir := flag.String("history-dir", "", "Record every run and its results in this directory (see the history command)")
+	historyMaxAge := flag.Duration("history-max-age", 0, "Drop recorded runs older than this (0 keeps all)")
+	historyMaxRuns := flag.Int("history-max-runs", 0, "Keep at most this many recorded runs (0 keeps all)")
+	traceExporter := flag.String("trace-exporter", tracing.ExporterNone, "OpenTelemetry trace exporter: none, otlp, file")
+	traceEndpoint := flag.String("trace-endpoint", "", "OTLP/HTTP collector URL (default: OTEL_EXPORTER_OTLP_ENDPOINT or http://localhost:4318)")
+	traceFile := flag.String("trace-file", "ospa-traces.json", "File spans are appended to with --trace-exporter=file")
+	progressMode := flag.String("progress", progress.ModeAuto, "Scan progress: auto (tty when stderr is a terminal, else log), tty, log, off")
+	progressInterval := flag.Duration("progress-interval", 30*time.Second, "Interval between progress log events")
 	flag.Parse()
 
 	if *cloudName == "" {
@@ -42,49 +90,97 @@ func main() {
 		log.Fatal("Error: Please provide a cloud name via --cloud or OS_CLOUD env var")
 	}
 
-	if *policyPath == "" {
-		log.Fatal("Error: Please provide a policy file via --policy")
+	if *policyPath == "" && len(packs) == 0 {
+		log.Fatal("Error: Please provide a policy file via --policy or a built-in pack via --pack")
 	}
 
 	configureLogger(*logLevel, *logFormat)
 
-	fmt.Printf("Initializing Session for cloud: %q...\n", *cloudName)
+	progressOutput, err := progress.ParseMode(*progressMode, os.Stderr)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *progressInterval <= 0 {
+		log.Fatalf("Error: --progress-interval must be positive, got %s", *progressInterval)
+	}
 
-	session, err := auth.NewSession(*cloudName)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter: *traceExporter,
+		Endpoint: *traceEndpoint,
+		File:     *traceFile,
+	})
 	if err != nil {
-		log.Fatalf("Authentication failed: %v", err)
+		log.Fatalf("Failed to configure tracing: %v", err)
+	}
+	defer flushTraces(shutdownTracing)
+
+	// Start the metrics server first so /healthz answers while the agent
+	// authenticates; /readyz reports ready once the policy is loaded.
+	if *metricsAddr != "" {
+		go func() {
+			if err := metrics.StartServer(*metricsAddr); err != nil {
+				log.Printf("Metrics server error: %v", err)
+			}
+		}()
 	}
-	fmt.Println("Authentication successful!")
 
-	fmt.Printf("Loading policy from %q...\n", *policyPath)
-	p, err := policy.Load(*policyPath)
+	// The policy is loaded first: its outputs decide whether findings go to
+	// stdout, and with them where the messages below are printed.
+	loader := policy.NewLoader()
+	loader.Packs = packs
+	p, err := loader.Load(*policyPath)
 	if err != nil {
 		log.Fatalf("Failed to load policy: %v", err)
 	}
-	fmt.Printf("Policy loaded: %d service policies\n", len(p.Policies))
+	outputs, err := resolveOutputs(outputSpecs, *outPath, *outFormat, p.Defaults)
+	if err != nil {
+		log.Fatalf("Error: invalid output: %v", err)
+	}
+	out := console(outputs)
 
-	workersCount := p.EffectiveWorkers(*workers)
-	fmt.Printf("Using %d workers\n", workersCount)
+	_, _ = fmt.Fprintf(out, "Initializing Session for cloud: %q...\n", *cloudName)
 
-	if *outPath == "" && p.Defaults.Output != "" {
-		*outPath = p.Defaults.Output
+	session, err := auth.NewSession(*cloudName)
+	if err != nil {
+		log.Fatalf("Authentication failed: %v", err)
 	}
+	_, _ = fmt.Fprintln(out, "Authentication successful!")
 
-	var findingsWriter report.ResultWriter
-	var findingsFile *os.File
+	_, _ = fmt.Fprintf(out, "Policy loaded from %s: %d service policies\n", policySource(*policyPath, packs), len(p.Policies))
 
-	if *outPath != "" {
-		f, err := os.Create(*outPath)
+	workersCount := p.EffectiveWorkers(*workers)
+	_, _ = fmt.Fprintf(out, "Using %d workers\n", workersCount)
+
+	opts := scanOptions{
+		cloud:         *cloudName,
+		outputSpecs:   outputSpecs,
+		outPath:       *outPath,
+		outFormat:     *outFormat,
+		baselinePath:  *baselinePath,
+		approvalStore: *approvalStore,
+		rolloutState:  *rolloutState,
+		progress:      progressOutput,
+		progressEvery: *progressInterval,
+	}
+	if *syslogAddr != "" {
+		tlsConfig, err := syslogTLSConfig(*syslogCA)
 		if err != nil {
-			log.Fatalf("Failed to create output file %q: %v", *outPath, err)
+			log.Fatalf("Failed to configure syslog TLS: %v", err)
 		}
-		findingsFile = f
-		defer func() { _ = findingsFile.Close() }()
-		writer, err := report.NewWriter(*outFormat, findingsFile)
+		opts.syslog = &report.SyslogConfig{
+			Network:   *syslogNetwork,
+			Address:   *syslogAddr,
+			Format:    *syslogFormat,
+			TLSConfig: tlsConfig,
+		}
+	}
+
+	if *historyDir != "" {
+		store, err := history.Open(*historyDir, history.Options{MaxAge: *historyMaxAge, MaxRuns: *historyMaxRuns})
 		if err != nil {
-			log.Fatalf("Failed to create output writer: %v", err)
+			log.Fatalf("Failed to open history store: %v", err)
 		}
-		findingsWriter = writer
+		opts.history = store
 	}
 
 	// Create orchestrator
@@ -93,35 +189,227 @@ func main() {
 	orch.SetRemediationAllowlist(parseAllowlist(*allowActions))
 	defer orch.Stop()
 
-	fmt.Println("Starting policy audit...")
-	resultsChan, err := orch.Run()
+	metrics.SetProgressTracker(orch.Progress())
+	metrics.SetReady(true)
+
+	if *interval != "" || *cronSchedule != "" {
+		sched, runNow, err := parseSchedule(*interval, *cronSchedule)
+		if err != nil {
+			log.Fatalf("Invalid schedule: %v", err)
+		}
+		d := &daemon{
+			orch:       orch,
+			policy:     p,
+			policyPath: *policyPath,
+			loader:     loader,
+			watch:      *policyWatch,
+			schedule:   sched,
+			runNow:     runNow,
+			jitter:     *jitter,
+			opts:       opts,
+		}
+		d.run()
+		return
+	}
+
+	summary, err := runScan(orch, p, opts)
+	flushTraces(shutdownTracing)
 	if err != nil {
-		log.Fatalf("Failed to start orchestrator: %v", err)
+		log.Fatal(err)
+	}
+	if summary.Violations > 0 {
+		os.Exit(2)
 	}
+}
 
-	if *metricsAddr != "" {
-		go func() {
-			if err := metrics.StartServer(*metricsAddr); err != nil {
-				log.Printf("Metrics server error: %v", err)
-			}
-		}()
+// startProgress reports the progress of the run started on tracker's
+// orchestrator in the configured mode. The returned function stops it after
+// reporting the final counts.
+func startProgress(tracker *progress.Tracker, opts scanOptions) func() {
+	if opts.progress != progress.ModeTTY && opts.progress != progress.ModeLog {
+		return func() {}
 	}
+	interval := opts.progressEvery
+	if opts.progress == progress.ModeTTY {
+		interval = time.Second
+	}
+	// Drawn on stderr so it never mixes with findings written to stdout.
+	reporter := progress.NewReporter(tracker, opts.progress, os.Stderr, nil, interval)
+	reporter.Start()
+	return reporter.Stop
+}
+
+// flushTraces exports the spans still buffered by the tracer provider. It
+// runs before exiting since os.Exit skips deferred calls.
+func flushTraces(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+}
 
-	summaryChan := make(chan report.Summary, 1)
-	go func() {
-		summaryChan <- report.ConsumeResults(resultsChan, findingsWriter)
-	}()
+// scanOptions are the settings shared by every scan run.
+type scanOptions struct {
+	cloud         string
+	outputSpecs   []string
+	outPath       string
+	outFormat     string
+	baselinePath  string
+	approvalStore string
+	rolloutState  string
+	syslog        *report.SyslogConfig
+	history       *history.Store
+	progress      string        // progress mode resolved by progress.ParseMode
+	progressEvery time.Duration // interval between progress log events
+}
 
-	summary := <-summaryChan
-	report.PrintSummary(os.Stdout, summary)
+// runRecorder records a run in the history. The run is committed on Close,
+// after the results channel was drained, so the orchestrator knows by then
+// which discoveries completed.
+type runRecorder struct {
+	*history.Recorder
+	orch *orchestrator.Orchestrator
+}
+
+func (r runRecorder) Close() error {
+	r.SetDiscovered(r.orch.Discovered())
+	return r.Recorder.Close()
+}
+
+// runScan audits the cloud once with p, writes the findings to every
+// configured sink and prints the run summary. Sinks, the approval store and
+// the rollout state are opened afresh so that each run sees the latest
+// decisions and writes a complete set of findings.
+func runScan(orch *orchestrator.Orchestrator, p *policy.Policy, opts scanOptions) (report.Summary, error) {
+	outputs, err := resolveOutputs(opts.outputSpecs, opts.outPath, opts.outFormat, p.Defaults)
+	if err != nil {
+		return report.Summary{}, fmt.Errorf("invalid output: %w", err)
+	}
+	out := console(outputs)
+	writers, closeOutputs, err := openOutputs(outputs)
+	if err != nil {
+		return report.Summary{}, fmt.Errorf("failed to create output writer: %w", err)
+	}
+	defer func() { _ = closeOutputs() }()
+
+	if opts.syslog != nil {
+		sink, err := report.NewSyslogWriter(*opts.syslog)
+		if err != nil {
+			return report.Summary{}, fmt.Errorf("failed to create syslog output: %w", err)
+		}
+		writers = append(writers, sink)
+	}
+	if len(p.Notifications) > 0 {
+		notifiers, err := notify.FromPolicy(p)
+		if err != nil {
+			return report.Summary{}, fmt.Errorf("failed to configure notifications: %w", err)
+		}
+		for _, n := range notifiers {
+			writers = append(writers, n)
+		}
+	}
+	if opts.history != nil {
+		recorder, err := opts.history.Begin(history.Run{Rules: p.RuleNames()})
+		if err != nil {
+			return report.Summary{}, err
+		}
+		writers = append(writers, runRecorder{Recorder: recorder, orch: orch})
+	}
+
+	var findingsWriter report.ResultWriter
+	if len(writers) > 0 {
+		multi := report.NewMultiWriter(writers...)
+		multi.SetCloud(opts.cloud)
+		findingsWriter = multi
+	}
+	var baseline *report.BaselineWriter
+	if opts.baselinePath != "" && findingsWriter != nil {
+		previous, err := report.LoadFindings(opts.baselinePath)
+		if err != nil {
+			return report.Summary{}, fmt.Errorf("failed to load baseline: %w", err)
+		}
+		baseline = report.NewBaselineWriter(findingsWriter, previous)
+		findingsWriter = baseline
+	}
+
+	orch.SetApprovalStore(nil)
+	if p.RequiresApproval() {
+		store, err := approval.Open(opts.approvalStore)
+		if err != nil {
+			return report.Summary{}, fmt.Errorf("failed to open approval store: %w", err)
+		}
+		orch.SetApprovalStore(store)
+	}
+	orch.SetRolloutState(nil)
+	if p.HasRollouts() {
+		state, err := rollout.Open(opts.rolloutState)
+		if err != nil {
+			return report.Summary{}, fmt.Errorf("failed to open rollout state: %w", err)
+		}
+		orch.SetRolloutState(state)
+	}
+
+	_, _ = fmt.Fprintln(out, "Starting policy audit...")
+	start := time.Now()
+	resultsChan, err := orch.Run()
+	if err != nil {
+		return report.Summary{}, fmt.Errorf("failed to start orchestrator: %w", err)
+	}
+
+	stopProgress := startProgress(orch.Progress(), opts)
+	summary := report.ConsumeResults(resultsChan, findingsWriter)
+	stopProgress()
+	if err := closeOutputs(); err != nil && summary.CloseErr == nil {
+		summary.CloseErr = err
+	}
+	metrics.RecordRun(metrics.RunStats{
+		Start:      start,
+		Duration:   time.Since(start),
+		Scanned:    summary.Scanned,
+		Violations: summary.Violations,
+		Errors:     summary.Errors,
+	})
+
+	report.PrintSummary(out, summary)
 	if findingsWriter != nil {
-		fmt.Printf("Findings written: %d\nOutput: %s\n", summary.Written, *outPath)
+		_, _ = fmt.Fprintf(out, "Findings written: %d\n", summary.Written)
+		for _, o := range outputs {
+			_, _ = fmt.Fprintf(out, "Output: %s (%s)\n", o.Path, o.Format)
+		}
+		if opts.syslog != nil {
+			_, _ = fmt.Fprintf(out, "Syslog: %s://%s\n", opts.syslog.Network, opts.syslog.Address)
+		}
+		if opts.history != nil {
+			_, _ = fmt.Fprintf(out, "History: %s\n", opts.history.Dir())
+		}
 	} else {
-		fmt.Println("Findings written: 0 (no --out specified)")
+		_, _ = fmt.Fprintln(out, "Findings written: 0 (no --out or --output specified)")
 	}
-	if summary.Violations > 0 {
-		os.Exit(2)
+	if baseline != nil {
+		_, _ = fmt.Fprintf(out, "Baseline: %d new, %d existing, %d resolved\n", baseline.New, baseline.Existing, baseline.Resolved)
+	}
+	if summary.CloseErr != nil {
+		return summary, fmt.Errorf("failed to write findings: %w", summary.CloseErr)
+	}
+	return summary, nil
+}
+
+// syslogTLSConfig returns the TLS config for the syslog collector, trusting
+// caPath when set and the system roots otherwise.
+func syslogTLSConfig(caPath string) (*tls.Config, error) {
+	if caPath == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %q", caPath)
 	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
 }
 
 func parseAllowlist(raw string) []string {
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/auth"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/orchestrator"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/services"
+	"github.com/gophercloud/gophercloud"
+)
+
+const (
+	testService  = "agent-test-svc"
+	testResource = "thing"
+)
+
+type fakeDiscoverer struct{}
+
+func (fakeDiscoverer) ResourceType() string { return testResource }
+func (fakeDiscoverer) Discover(context.Context, *gophercloud.ServiceClient, bool) (<-chan discovery.Job, error) {
+	ch := make(chan discovery.Job, 2)
+	for _, id := range []string{"id-1", "id-2"} {
+		ch <- discovery.Job{Service: testService, ResourceType: testResource, ResourceID: id, Resource: id}
+	}
+	close(ch)
+	return ch, nil
+}
+
+type fakeAuditor struct{}
+
+func (fakeAuditor) ResourceType() string        { return testResource }
+func (fakeAuditor) ImplementedChecks() []string { return nil }
+func (fakeAuditor) Check(_ context.Context, resource interface{}, _ *policy.Rule) (*audit.Result, error) {
+	return &audit.Result{ResourceID: resource.(string), Compliant: false, Observation: "violates"}, nil
+}
+func (fakeAuditor) Fix(context.Context, interface{}, interface{}, *policy.Rule) error { return nil }
+
+type fakeService struct{}
+
+func (fakeService) Name() string { return testService }
+func (fakeService) GetClient(*auth.Session) (*gophercloud.ServiceClient, error) {
+	return &gophercloud.ServiceClient{}, nil
+}
+func (fakeService) GetResourceAuditor(string) (audit.Auditor, error) { return fakeAuditor{}, nil }
+func (fakeService) GetResourceDiscoverer(string) (discovery.Discoverer, error) {
+	return fakeDiscoverer{}, nil
+}
+
+// redirect points *f at a temporary file for the rest of the test and
+// returns a function that reads what was written to it.
+func redirect(t *testing.T, f **os.File) func() []byte {
+	t.Helper()
+	tmp, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := *f
+	*f = tmp
+	t.Cleanup(func() {
+		*f = prev
+		_ = tmp.Close()
+	})
+	return func() []byte {
+		data, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+}
+
+func TestRunScan_StdoutHoldsOnlyFindings(t *testing.T) {
+	services.RegisterResource(testService, testResource)
+	if err := services.Register(fakeService{}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: testService,
+			Rules: []policy.Rule{{
+				Name:     "r1",
+				Service:  testService,
+				Resource: testResource,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "log",
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	stdout := redirect(t, &os.Stdout)
+	stderr := redirect(t, &os.Stderr)
+
+	orch := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, false, false)
+	defer orch.Stop()
+	summary, err := runScan(orch, p, scanOptions{
+		cloud:         "test",
+		outPath:       "-",
+		outFormat:     "json",
+		approvalStore: filepath.Join(t.TempDir(), "approvals.json"),
+		progress:      progress.ModeOff,
+	})
+	if err != nil {
+		t.Fatalf("runScan() error = %v", err)
+	}
+
+	var findings []report.Finding
+	scanner := bufio.NewScanner(bytes.NewReader(stdout()))
+	for scanner.Scan() {
+		var f report.Finding
+		if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+			t.Fatalf("stdout line %q is not a finding: %v", scanner.Text(), err)
+		}
+		findings = append(findings, f)
+	}
+	if len(findings) != 2 || summary.Written != 2 {
+		t.Fatalf("stdout holds %d findings, summary.Written = %d; want 2", len(findings), summary.Written)
+	}
+	if !strings.Contains(string(stderr()), "Findings written: 2") {
+		t.Errorf("stderr = %q, want the run summary", stderr())
+	}
+}
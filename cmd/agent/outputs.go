@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+)
+
+// outputFlags collects repeated --output values.
+type outputFlags []string
+
+func (o *outputFlags) String() string { return strings.Join(*o, " ") }
+
+func (o *outputFlags) Set(v string) error {
+	*o = append(*o, v)
+	return nil
+}
+
+// parseOutputSpec parses an --output value:
+//
+//	format=path[,severity=S][,category=C][,service=S]
+//
+// Filter keys may be repeated to match several values.
+func parseOutputSpec(spec string) (policy.Output, error) {
+	parts := strings.Split(spec, ",")
+	format, path, ok := strings.Cut(parts[0], "=")
+	if !ok || strings.TrimSpace(format) == "" || strings.TrimSpace(path) == "" {
+		return policy.Output{}, fmt.Errorf("invalid --output %q (want format=path)", spec)
+	}
+	out := policy.Output{Format: strings.TrimSpace(format), Path: strings.TrimSpace(path)}
+
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return policy.Output{}, fmt.Errorf("invalid --output %q: bad filter %q", spec, part)
+		}
+		switch strings.TrimSpace(key) {
+		case "severity":
+			out.Filter.Severities = append(out.Filter.Severities, value)
+		case "category":
+			out.Filter.Categories = append(out.Filter.Categories, value)
+		case "service":
+			out.Filter.Services = append(out.Filter.Services, value)
+		default:
+			return policy.Output{}, fmt.Errorf("invalid --output %q: unknown filter %q (supported: severity, category, service)", spec, key)
+		}
+	}
+	if err := out.Validate(); err != nil {
+		return policy.Output{}, fmt.Errorf("invalid --output %q: %w", spec, err)
+	}
+	return out, nil
+}
+
+// resolveOutputs picks the outputs for this run. Outputs given on the
+// command line (--output and --out) take precedence over defaults.outputs
+// and defaults.output in the policy.
+func resolveOutputs(specs []string, outPath, outFormat string, defaults policy.Defaults) ([]policy.Output, error) {
+	var outputs []policy.Output
+	for _, spec := range specs {
+		out, err := parseOutputSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, out)
+	}
+	if outPath != "" {
+		outputs = append(outputs, policy.Output{Format: outFormat, Path: outPath})
+	}
+	if len(outputs) > 0 {
+		return outputs, nil
+	}
+
+	outputs = append(outputs, defaults.Outputs...)
+	if defaults.Output != "" {
+		outputs = append(outputs, policy.Output{Format: outFormat, Path: defaults.Output})
+	}
+	return outputs, nil
+}
+
+// console returns where informational messages and the run summary are
+// printed: stderr when an output writes findings to stdout ("-"), so that
+// stdout holds nothing but findings, and stdout otherwise.
+func console(outputs []policy.Output) io.Writer {
+	for _, out := range outputs {
+		if out.Path == "-" {
+			return os.Stderr
+		}
+	}
+	return os.Stdout
+}
+
+// openOutputs creates a writer for each output. The returned function
+// closes the underlying files and returns the first error; calling it again
+// does nothing.
+func openOutputs(outputs []policy.Output) ([]report.ResultWriter, func() error, error) {
+	var (
+		writers []report.ResultWriter
+		files   []*os.File
+	)
+	closeFiles := func() error {
+		var first error
+		for _, f := range files {
+			if err := f.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+		files = nil
+		return first
+	}
+
+	for _, out := range outputs {
+		var w io.Writer = os.Stdout
+		if out.Path != "-" {
+			f, err := os.Create(out.Path)
+			if err != nil {
+				_ = closeFiles()
+				return nil, nil, fmt.Errorf("creating output file %q: %w", out.Path, err)
+			}
+			files = append(files, f)
+			w = f
+		}
+		writer, err := report.NewWriter(out.Format, w)
+		if err != nil {
+			_ = closeFiles()
+			return nil, nil, fmt.Errorf("output %q: %w", out.Path, err)
+		}
+		writers = append(writers, report.NewFilteredWriter(writer, out.Filter))
+	}
+	return writers, closeFiles, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+const packsUsage = `Usage: agent packs <command> [flags]
+
+Commands:
+  list                 List the built-in policy packs
+  show <name[@version]>
+                       Show the rules of a pack (default: latest version)
+
+Flags:
+  --format FORMAT      Output format: text, json (list); text, yaml (show)
+
+Load packs with --pack name@version, or include: ["pack:name@version"] in a
+policy file. Change pack rules with overrides: in your own policy.
+`
+
+// packFlags collects repeated --pack values.
+type packFlags []string
+
+func (p *packFlags) String() string { return strings.Join(*p, " ") }
+
+func (p *packFlags) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+// policySource describes the policy file and packs for log messages.
+func policySource(path string, packs []string) string {
+	switch {
+	case len(packs) == 0:
+		return fmt.Sprintf("%q", path)
+	case path == "":
+		return "packs " + strings.Join(packs, ", ")
+	default:
+		return fmt.Sprintf("%q with packs %s", path, strings.Join(packs, ", "))
+	}
+}
+
+// runPacks implements the "packs" subcommand and returns the exit code.
+func runPacks(args []string, out, errOut io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(errOut, packsUsage)
+		return 1
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("packs "+command, flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	format := fs.String("format", "text", "Output format")
+
+	// Allow flags both before and after the pack reference.
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	var ref string
+	if fs.NArg() > 0 {
+		ref = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 1
+		}
+	}
+
+	switch command {
+	case "list":
+		packs := policy.Packs()
+		switch *format {
+		case "text":
+			printPacks(out, packs)
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(packs); err != nil {
+				_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+				return 1
+			}
+		default:
+			_, _ = fmt.Fprintf(errOut, "Error: unknown format %q\n", *format)
+			return 1
+		}
+
+	case "show":
+		if ref == "" {
+			_, _ = fmt.Fprintln(errOut, "Error: show requires a pack name")
+			return 1
+		}
+		info, data, err := policy.Pack(ref)
+		if err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			return 1
+		}
+		switch *format {
+		case "text":
+			p, err := policy.LoadPack(info.Ref())
+			if err != nil {
+				_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+				return 1
+			}
+			_, _ = fmt.Fprintf(out, "%s: %s\n\n", info.Ref(), info.Description)
+			printPackRules(out, p)
+		case "yaml":
+			_, _ = out.Write(data)
+		default:
+			_, _ = fmt.Fprintf(errOut, "Error: unknown format %q\n", *format)
+			return 1
+		}
+
+	default:
+		_, _ = fmt.Fprintf(errOut, "Error: unknown packs command %q\n\n%s", command, packsUsage)
+		return 1
+	}
+	return 0
+}
+
+func printPacks(out io.Writer, packs []policy.PackInfo) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PACK\tDESCRIPTION")
+	for _, info := range packs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", info.Ref(), info.Description)
+	}
+	_ = tw.Flush()
+}
+
+func printPackRules(out io.Writer, p *policy.Policy) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RULE\tSERVICE\tRESOURCE\tSEVERITY\tCATEGORY\tACTION")
+	for _, r := range p.GetAllRules() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, r.Service, r.Resource, orDash(r.Severity), orDash(r.Category), r.Action)
+	}
+	for _, r := range p.GetAllCompositeRules() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, r.Service, strings.Join(r.Resources, "+"), orDash(r.Severity), orDash(r.Category), r.Action)
+	}
+	_ = tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
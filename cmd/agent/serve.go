@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/api"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/auth"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/history"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/orchestrator"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/tracing"
+)
+
+// orchestratorScanner runs API scans on a shared orchestrator. The API
+// server never starts two scans at once.
+type orchestratorScanner struct {
+	orch *orchestrator.Orchestrator
+}
+
+func (s orchestratorScanner) Scan(p *policy.Policy) (<-chan *audit.Result, error) {
+	s.orch.SetPolicy(p)
+	return s.orch.Run()
+}
+
+func (s orchestratorScanner) Discovered() []string {
+	return s.orch.Discovered()
+}
+
+// runServe implements the "serve" subcommand and returns the exit code. It
+// audits without remediating and serves the results over HTTP until SIGINT
+// or SIGTERM.
+func runServe(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	cloudName := fs.String("cloud", os.Getenv("OS_CLOUD"), "The name of the cloud in clouds.yaml")
+	policyPath := fs.String("policy", "", "Policy file, directory of YAML files, glob pattern or HTTP(S) URL")
+	var packs packFlags
+	fs.Var(&packs, "pack", "Built-in policy pack as name@version, loaded before --policy; repeatable")
+	listen := fs.String("listen", "127.0.0.1:8080", "API listen address")
+	token := fs.String("token", os.Getenv("OSPA_API_TOKEN"), "Bearer token required on API requests (default: OSPA_API_TOKEN)")
+	workers := fs.Int("workers", runtime.NumCPU()*8, "Number of concurrent workers")
+	allTenants := fs.Bool("all-tenants", false, "Scan all tenants/projects (requires admin)")
+	scanOnStart := fs.Bool("scan-on-start", true, "Run a full scan at startup")
+	interval := fs.Duration("interval", 0, "Also run a full scan at this interval (0 disables)")
+	approvalStore := fs.String("approval-store", defaultApprovalStore, "Approval store file served under /v1/approvals (empty disables)")
+	historyLimit := fs.Int("history-limit", 100, "Results kept in memory per resource when --history-dir is not set")
+	historyDir := fs.String("history-dir", "", "Record every scan in this directory; enables /v1/incidents and /v1/mttr")
+	historyMaxAge := fs.Duration("history-max-age", 0, "Drop recorded runs older than this (0 keeps all)")
+	historyMaxRuns := fs.Int("history-max-runs", 0, "Keep at most this many recorded runs (0 keeps all)")
+	logLevel := fs.String("log-level", "info", "Log level: debug, info, warn, error")
+	logFormat := fs.String("log-format", "text", "Log format: text, json")
+	traceExporter := fs.String("trace-exporter", tracing.ExporterNone, "OpenTelemetry trace exporter: none, otlp, file")
+	traceEndpoint := fs.String("trace-endpoint", "", "OTLP/HTTP collector URL (default: OTEL_EXPORTER_OTLP_ENDPOINT or http://localhost:4318)")
+	traceFile := fs.String("trace-file", "ospa-traces.json", "File spans are appended to with --trace-exporter=file")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if *cloudName == "" || (*policyPath == "" && len(packs) == 0) {
+		_, _ = fmt.Fprintln(errOut, "Error: serve requires --cloud (or OS_CLOUD) and --policy or --pack")
+		return 1
+	}
+	configureLogger(*logLevel, *logFormat)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter: *traceExporter,
+		Endpoint: *traceEndpoint,
+		File:     *traceFile,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Failed to configure tracing: %v\n", err)
+		return 1
+	}
+	defer flushTraces(shutdownTracing)
+
+	session, err := auth.NewSession(*cloudName)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Authentication failed: %v\n", err)
+		return 1
+	}
+	loader := policy.NewLoader()
+	loader.Packs = packs
+	p, err := loader.Load(*policyPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(errOut, "Failed to load policy: %v\n", err)
+		return 1
+	}
+
+	var store *history.Store
+	if *historyDir != "" {
+		store, err = history.Open(*historyDir, history.Options{MaxAge: *historyMaxAge, MaxRuns: *historyMaxRuns})
+		if err != nil {
+			_, _ = fmt.Fprintf(errOut, "Failed to open history store: %v\n", err)
+			return 1
+		}
+	}
+
+	orch := orchestrator.NewOrchestrator(p, session, p.EffectiveWorkers(*workers), false, *allTenants)
+	defer orch.Stop()
+
+	server := api.NewServer(p, orchestratorScanner{orch: orch}, api.Options{
+		ApprovalStore: *approvalStore,
+		HistoryLimit:  *historyLimit,
+		History:       store,
+		Token:         *token,
+	})
+	if *token == "" && !isLoopback(*listen) {
+		_, _ = fmt.Fprintf(errOut, "Warning: serving the API on %s without authentication; set --token or OSPA_API_TOKEN\n", *listen)
+	}
+	httpServer := &http.Server{
+		Addr:              *listen,
+		Handler:           tracing.Handler(server.Handler(), "ospa.api"),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	_, _ = fmt.Fprintf(out, "Serving API on %s (OpenAPI description at /openapi.json)\n", *listen)
+
+	startScan := func() {
+		if _, err := server.StartScan(api.Scope{}); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Scan not started: %v\n", err)
+		}
+	}
+	if *scanOnStart {
+		startScan()
+	}
+	var tick <-chan time.Time
+	if *interval > 0 {
+		ticker := time.NewTicker(*interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case err := <-serveErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				_, _ = fmt.Fprintf(errOut, "API server error: %v\n", err)
+				return 1
+			}
+			return 0
+		case <-tick:
+			startScan()
+		case <-hup:
+			reloaded, err := loader.Load(*policyPath)
+			if err != nil {
+				_, _ = fmt.Fprintf(errOut, "Failed to reload policy, keeping the previous one: %v\n", err)
+				continue
+			}
+			server.SetPolicy(reloaded)
+			_, _ = fmt.Fprintf(out, "Policy reloaded: %d service policies\n", len(reloaded.Policies))
+		case <-orch.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = httpServer.Shutdown(ctx)
+			return 0
+		}
+	}
+}
+
+// isLoopback reports whether the listen address only accepts local
+// connections.
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
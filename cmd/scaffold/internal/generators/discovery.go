@@ -69,6 +69,10 @@ func (d *{{$.DisplayName}}{{.Name | Pascal}}Discoverer) Discover(ctx context.Con
 		// TODO: List {{.Name}} resources using gophercloud and send jobs.
 		// Example pattern:
 		//   pages, err := <resource>.List(client, <opts>).AllPages()
+		//   if err != nil {
+		//       discovery.Fail(ctx, ch, "{{$.ServiceName}}", "{{.Name}}", err)
+		//       return
+		//   }
 		//   resources, err := <resource>.ExtractResources(pages)
 		//   for _, r := range resources {
 		//       select {
@@ -163,6 +167,10 @@ func (d *{{$.DisplayName}}{{.Name | Pascal}}Discoverer) Discover(ctx context.Con
 		// TODO: List {{.Name}} resources using gophercloud and send jobs.
 		// Example pattern:
 		//   pages, err := <resource>.List(client, <opts>).AllPages()
+		//   if err != nil {
+		//       discovery.Fail(ctx, ch, "{{$.ServiceName}}", "{{.Name}}", err)
+		//       return
+		//   }
 		//   resources, err := <resource>.ExtractResources(pages)
 		//   for _, r := range resources {
 		//       select {
@@ -0,0 +1,638 @@
+// Package api serves findings, resource compliance history, on-demand scans
+// and approvals over HTTP for the "serve" command.
+package api
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/history"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// Scanner runs an audit with the given (possibly scoped) policy and streams
+// its results. The channel is closed when the audit finishes.
+type Scanner interface {
+	Scan(p *policy.Policy) (<-chan *audit.Result, error)
+
+	// Discovered returns the service/resource types whose discovery
+	// completed in the last scan; see history.Run.Discovered.
+	Discovered() []string
+}
+
+// Scan states.
+const (
+	ScanRunning   = "running"
+	ScanCompleted = "completed"
+)
+
+// ErrScanInProgress is returned by StartScan while another scan runs.
+var ErrScanInProgress = errors.New("a scan is already in progress")
+
+// Scope limits a scan to a service and optionally one resource type. The
+// zero value scans everything.
+type Scope struct {
+	Service      string `json:"service,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+}
+
+func (s Scope) matches(f report.Finding) bool {
+	return (s.Service == "" || f.Service == s.Service) &&
+		(s.ResourceType == "" || f.ResourceType == s.ResourceType)
+}
+
+// Scan is the status of a scan started through the API or at startup.
+type Scan struct {
+	ID         string     `json:"id"`
+	Scope      Scope      `json:"scope"`
+	State      string     `json:"state"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Scanned    int        `json:"scanned"`
+	Violations int        `json:"violations"`
+	Errors     int        `json:"errors"`
+}
+
+// Options configures a Server.
+type Options struct {
+	// ApprovalStore is the approval store file. Empty disables the approval
+	// endpoints.
+	ApprovalStore string
+
+	// HistoryLimit caps the entries kept per resource. Default 100.
+	HistoryLimit int
+
+	// ScanLimit caps the finished scans kept for GET /v1/scans. Default 50.
+	ScanLimit int
+
+	// History, when set, records every scan persistently. Resource history
+	// is then read from it, scan IDs are its run IDs, and the incidents and
+	// MTTR endpoints are enabled.
+	History *history.Store
+
+	// Token, when set, must be sent as "Authorization: Bearer <token>" on
+	// every request except GET /openapi.json.
+	Token string
+}
+
+// Server holds the findings of the latest scans in memory and serves them.
+type Server struct {
+	scanner Scanner
+	opts    Options
+	now     func() time.Time
+
+	mu       sync.RWMutex
+	policy   *policy.Policy
+	findings []report.Finding
+	history  map[string][]history.Entry
+	scans    []*Scan
+	running  *Scan
+	nextID   int
+	done     chan struct{}
+}
+
+// NewServer creates a server for p that runs scans with scanner.
+func NewServer(p *policy.Policy, scanner Scanner, opts Options) *Server {
+	if opts.HistoryLimit <= 0 {
+		opts.HistoryLimit = 100
+	}
+	if opts.ScanLimit <= 0 {
+		opts.ScanLimit = 50
+	}
+	return &Server{
+		scanner: scanner,
+		opts:    opts,
+		now:     time.Now,
+		policy:  p,
+		history: make(map[string][]history.Entry),
+	}
+}
+
+// SetPolicy replaces the policy used by subsequent scans.
+func (s *Server) SetPolicy(p *policy.Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = p
+}
+
+// StartScan starts a scan of scope in the background. It fails with
+// ErrScanInProgress while another scan runs, and when no rule of the policy
+// matches scope.
+func (s *Server) StartScan(scope Scope) (Scan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != nil {
+		return *s.running, ErrScanInProgress
+	}
+	scoped := s.policy.Scoped(scope.Service, scope.ResourceType)
+	if len(scoped.Policies) == 0 && len(scoped.Composites) == 0 {
+		return Scan{}, fmt.Errorf("no rules match service %q and resource type %q", scope.Service, scope.ResourceType)
+	}
+
+	var recorder *history.Recorder
+	if s.opts.History != nil {
+		var err error
+		recorder, err = s.opts.History.Begin(history.Run{
+			Service:      scope.Service,
+			ResourceType: scope.ResourceType,
+			Rules:        scoped.RuleNames(),
+		})
+		if err != nil {
+			return Scan{}, err
+		}
+	}
+	results, err := s.scanner.Scan(scoped)
+	if err != nil {
+		if recorder != nil {
+			_ = recorder.Close()
+		}
+		return Scan{}, fmt.Errorf("starting scan: %w", err)
+	}
+
+	s.nextID++
+	scan := &Scan{
+		ID:        strconv.Itoa(s.nextID),
+		Scope:     scope,
+		State:     ScanRunning,
+		StartedAt: s.now().UTC(),
+	}
+	if recorder != nil {
+		scan.ID = recorder.RunID()
+	}
+	s.running = scan
+	s.done = make(chan struct{})
+	go s.collect(scan, results, recorder, s.done)
+	return *scan, nil
+}
+
+// Wait blocks until the running scan, if any, has finished.
+func (s *Server) Wait() {
+	s.mu.RLock()
+	done := s.done
+	s.mu.RUnlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// collect records the results of scan. The latest findings in the scan's
+// scope are replaced once the scan completes, so readers never see a
+// partial run.
+func (s *Server) collect(scan *Scan, results <-chan *audit.Result, recorder *history.Recorder, done chan struct{}) {
+	defer close(done)
+
+	var findings []report.Finding
+	for r := range results {
+		f := report.NewFinding(r)
+		findings = append(findings, f)
+		if recorder != nil {
+			if err := recorder.WriteResult(r); err != nil {
+				slog.Error("Failed to record result", "scan", scan.ID, "error", err)
+			}
+		}
+
+		s.mu.Lock()
+		scan.Scanned++
+		if !r.Compliant {
+			scan.Violations++
+		}
+		if r.Error != nil || r.RemediationError != nil {
+			scan.Errors++
+		}
+		if recorder == nil {
+			s.addHistoryLocked(history.Entry{RunID: scan.ID, ScannedAt: scan.StartedAt, Finding: f})
+		}
+		s.mu.Unlock()
+	}
+	if recorder != nil {
+		recorder.SetDiscovered(s.scanner.Discovered())
+		if err := recorder.Close(); err != nil {
+			slog.Error("Failed to record scan", "scan", scan.ID, "error", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.findings[:0:0]
+	for _, f := range s.findings {
+		if !scan.Scope.matches(f) {
+			kept = append(kept, f)
+		}
+	}
+	s.findings = append(kept, findings...)
+
+	finished := s.now().UTC()
+	scan.FinishedAt = &finished
+	scan.State = ScanCompleted
+	s.running = nil
+	s.scans = append(s.scans, scan)
+	if len(s.scans) > s.opts.ScanLimit {
+		s.scans = s.scans[len(s.scans)-s.opts.ScanLimit:]
+	}
+}
+
+func (s *Server) addHistoryLocked(entry history.Entry) {
+	entries := append(s.history[entry.ResourceID], entry)
+	if len(entries) > s.opts.HistoryLimit {
+		entries = entries[len(entries)-s.opts.HistoryLimit:]
+	}
+	s.history[entry.ResourceID] = entries
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /v1/findings", s.handleFindings)
+	mux.HandleFunc("GET /v1/resources/{id}/history", s.handleHistory)
+	mux.HandleFunc("GET /v1/scans", s.handleListScans)
+	mux.HandleFunc("POST /v1/scans", s.handleStartScan)
+	mux.HandleFunc("GET /v1/scans/{id}", s.handleGetScan)
+	mux.HandleFunc("GET /v1/incidents", s.handleIncidents)
+	mux.HandleFunc("GET /v1/mttr", s.handleMTTR)
+	mux.HandleFunc("GET /v1/policy", s.handlePolicy)
+	mux.HandleFunc("GET /v1/approvals", s.handleListApprovals)
+	mux.HandleFunc("POST /v1/approvals/{id}/approve", s.handleDecide(approval.StateApproved))
+	mux.HandleFunc("POST /v1/approvals/{id}/reject", s.handleDecide(approval.StateRejected))
+	return s.authenticate(mux)
+}
+
+// authenticate requires the bearer token of Options.Token, if any.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openapi.json" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ospa"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
+
+// findingsResponse is the body of GET /v1/findings.
+type findingsResponse struct {
+	Total    int              `json:"total"`
+	Findings []report.Finding `json:"findings"`
+}
+
+func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := findingFilter{
+		projects:      queryValues(q["project"]),
+		severities:    queryValues(q["severity"]),
+		rules:         queryValues(q["rule"]),
+		services:      queryValues(q["service"]),
+		resourceTypes: queryValues(q["resource_type"]),
+	}
+	if v := q.Get("include_compliant"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid include_compliant %q", v))
+			return
+		}
+		filter.includeCompliant = include
+	}
+	limit, offset, err := pagination(q.Get("limit"), q.Get("offset"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.RLock()
+	matched := []report.Finding{}
+	for _, f := range s.findings {
+		if filter.match(f) {
+			matched = append(matched, f)
+		}
+	}
+	s.mu.RUnlock()
+
+	resp := findingsResponse{Total: len(matched)}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	resp.Findings = matched
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type findingFilter struct {
+	projects, severities, rules, services, resourceTypes []string
+	includeCompliant                                     bool
+}
+
+func (f findingFilter) match(finding report.Finding) bool {
+	if finding.Compliant && finding.Error == "" && finding.RemediationError == "" && !f.includeCompliant {
+		return false
+	}
+	return matchValue(f.projects, finding.ProjectID) &&
+		matchValue(f.severities, finding.Severity) &&
+		matchValue(f.rules, finding.RuleID) &&
+		matchValue(f.services, finding.Service) &&
+		matchValue(f.resourceTypes, finding.ResourceType)
+}
+
+func matchValue(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryValues accepts both repeated and comma-separated query parameters.
+func queryValues(raw []string) []string {
+	var values []string
+	for _, r := range raw {
+		for _, v := range strings.Split(r, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func pagination(rawLimit, rawOffset string) (int, int, error) {
+	var limit, offset int
+	var err error
+	if rawLimit != "" {
+		if limit, err = strconv.Atoi(rawLimit); err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("invalid limit %q", rawLimit)
+		}
+	}
+	if rawOffset != "" {
+		if offset, err = strconv.Atoi(rawOffset); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset %q", rawOffset)
+		}
+	}
+	return limit, offset, nil
+}
+
+// historyResponse is the body of GET /v1/resources/{id}/history.
+type historyResponse struct {
+	ResourceID string          `json:"resource_id"`
+	History    []history.Entry `json:"history"`
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	rules := queryValues(r.URL.Query()["rule"])
+
+	var entries []history.Entry
+	if s.opts.History != nil {
+		var err error
+		if entries, err = s.opts.History.ResourceHistory(id); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	} else {
+		s.mu.RLock()
+		entries = append(entries, s.history[id]...)
+		s.mu.RUnlock()
+	}
+	if len(entries) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("resource %q has not been scanned", id))
+		return
+	}
+
+	filtered := make([]history.Entry, 0, len(entries))
+	for _, e := range entries {
+		if matchValue(rules, e.RuleID) {
+			filtered = append(filtered, e)
+		}
+	}
+	writeJSON(w, http.StatusOK, historyResponse{ResourceID: id, History: filtered})
+}
+
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	if s.opts.History == nil {
+		writeError(w, http.StatusNotFound, errors.New("history is not enabled"))
+		return
+	}
+	q := r.URL.Query()
+	state := q.Get("state")
+	if state == "" {
+		state = "open"
+	}
+	if state != "open" && state != "resolved" && state != "all" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid state %q", state))
+		return
+	}
+	rules := queryValues(q["rule"])
+	projects := queryValues(q["project"])
+	resources := queryValues(q["resource_id"])
+
+	incidents := []history.Incident{}
+	for _, inc := range s.opts.History.Incidents(state == "open") {
+		if state == "resolved" && inc.Open() {
+			continue
+		}
+		if matchValue(rules, inc.RuleID) && matchValue(projects, inc.ProjectID) && matchValue(resources, inc.ResourceID) {
+			incidents = append(incidents, inc)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string][]history.Incident{"incidents": incidents})
+}
+
+func (s *Server) handleMTTR(w http.ResponseWriter, _ *http.Request) {
+	if s.opts.History == nil {
+		writeError(w, http.StatusNotFound, errors.New("history is not enabled"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]history.RuleMTTR{"rules": s.opts.History.MTTR()})
+}
+
+func (s *Server) handleListScans(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	scans := make([]Scan, 0, len(s.scans)+1)
+	if s.running != nil {
+		scans = append(scans, *s.running)
+	}
+	for i := len(s.scans) - 1; i >= 0; i-- {
+		scans = append(scans, *s.scans[i])
+	}
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, map[string][]Scan{"scans": scans})
+}
+
+func (s *Server) handleStartScan(w http.ResponseWriter, r *http.Request) {
+	var scope Scope
+	if r.ContentLength != 0 {
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&scope); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid scan request: %w", err))
+			return
+		}
+	}
+
+	scan, err := s.StartScan(scope)
+	switch {
+	case errors.Is(err, ErrScanInProgress):
+		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "scan": scan})
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/scans/"+scan.ID)
+	writeJSON(w, http.StatusAccepted, scan)
+}
+
+func (s *Server) handleGetScan(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.RLock()
+	var found *Scan
+	if s.running != nil && s.running.ID == id {
+		found = s.running
+	}
+	for _, scan := range s.scans {
+		if scan.ID == id {
+			found = scan
+		}
+	}
+	var scan Scan
+	if found != nil {
+		scan = *found
+	}
+	s.mu.RUnlock()
+
+	if found == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("scan %q not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, scan)
+}
+
+// handlePolicy serves the policy without notification credentials.
+func (s *Server) handlePolicy(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	data, err := yaml.Marshal(s.policy.Redacted())
+	s.mu.RUnlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(data)
+}
+
+func (s *Server) openApprovals(w http.ResponseWriter) (*approval.Store, bool) {
+	if s.opts.ApprovalStore == "" {
+		writeError(w, http.StatusNotFound, errors.New("approvals are not enabled"))
+		return nil, false
+	}
+	// Reopen on every request so decisions made with the approvals command
+	// are visible.
+	store, err := approval.Open(s.opts.ApprovalStore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return store, true
+}
+
+func (s *Server) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	store, ok := s.openApprovals(w)
+	if !ok {
+		return
+	}
+	state := approval.StatePending
+	if r.URL.Query().Has("state") {
+		state = r.URL.Query().Get("state")
+	}
+	items := store.List(state)
+	if items == nil {
+		items = []approval.Item{}
+	}
+	writeJSON(w, http.StatusOK, map[string][]approval.Item{"approvals": items})
+}
+
+// decisionRequest is the body of the approve and reject endpoints.
+type decisionRequest struct {
+	Reviewer string `json:"reviewer"`
+	Comment  string `json:"comment"`
+}
+
+func (s *Server) handleDecide(state string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req decisionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid decision: %w", err))
+			return
+		}
+		if req.Reviewer == "" {
+			writeError(w, http.StatusBadRequest, errors.New("reviewer is required"))
+			return
+		}
+		store, ok := s.openApprovals(w)
+		if !ok {
+			return
+		}
+
+		id := r.PathValue("id")
+		if _, ok := store.Get(id); !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("approval %q not found", id))
+			return
+		}
+		decide := store.Approve
+		if state == approval.StateRejected {
+			decide = store.Reject
+		}
+		if err := decide(id, req.Reviewer, req.Comment); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		item, _ := store.Get(id)
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,432 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/history"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+var (
+	sshRule = &policy.Rule{Name: "open-ssh", Service: "neutron", Resource: "security_group_rule", Action: "log"}
+	vmRule  = &policy.Rule{Name: "shutoff-vm", Service: "nova", Resource: "instance", Action: "log"}
+)
+
+func testPolicy() *policy.Policy {
+	return &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{
+			{Service: "neutron", Rules: []policy.Rule{*sshRule}},
+			{Service: "nova", Rules: []policy.Rule{*vmRule}},
+		},
+	}
+}
+
+// fakeScanner returns the next batch of results for each scan and records
+// the policies it was given. Every scan reports discovered as completed.
+type fakeScanner struct {
+	batches    [][]*audit.Result
+	policies   []*policy.Policy
+	release    chan struct{}
+	discovered []string
+}
+
+func (f *fakeScanner) Scan(p *policy.Policy) (<-chan *audit.Result, error) {
+	if len(f.batches) == 0 {
+		return nil, errors.New("no more batches")
+	}
+	f.policies = append(f.policies, p)
+	batch := f.batches[0]
+	f.batches = f.batches[1:]
+
+	out := make(chan *audit.Result)
+	go func() {
+		defer close(out)
+		if f.release != nil {
+			<-f.release
+		}
+		for _, r := range batch {
+			out <- r
+		}
+	}()
+	return out, nil
+}
+
+func (f *fakeScanner) Discovered() []string {
+	return f.discovered
+}
+
+func result(rule *policy.Rule, id, project, severity string, compliant bool) *audit.Result {
+	return &audit.Result{
+		RuleID:     rule.Name,
+		ResourceID: id,
+		ProjectID:  project,
+		Severity:   severity,
+		Compliant:  compliant,
+		Rule:       rule,
+	}
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+func scanAndWait(t *testing.T, s *Server, h http.Handler, body string) Scan {
+	t.Helper()
+	rec := do(t, h, http.MethodPost, "/v1/scans", body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /v1/scans = %d %s", rec.Code, rec.Body.String())
+	}
+	var scan Scan
+	decode(t, rec, &scan)
+	if rec.Header().Get("Location") != "/v1/scans/"+scan.ID {
+		t.Errorf("Location = %q", rec.Header().Get("Location"))
+	}
+	s.Wait()
+	return scan
+}
+
+func TestServer_FindingsFilters(t *testing.T) {
+	scanner := &fakeScanner{batches: [][]*audit.Result{{
+		result(sshRule, "sgr-1", "p1", "high", false),
+		result(sshRule, "sgr-2", "p2", "high", false),
+		result(sshRule, "sgr-3", "p1", "high", true),
+		result(vmRule, "vm-1", "p1", "low", false),
+	}}}
+	s := NewServer(testPolicy(), scanner, Options{})
+	h := s.Handler()
+	scanAndWait(t, s, h, "")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"sgr-1", "sgr-2", "vm-1"}},
+		{"?include_compliant=true", []string{"sgr-1", "sgr-2", "sgr-3", "vm-1"}},
+		{"?project=p1", []string{"sgr-1", "vm-1"}},
+		{"?severity=HIGH", []string{"sgr-1", "sgr-2"}},
+		{"?rule=shutoff-vm", []string{"vm-1"}},
+		{"?severity=low,high&project=p2", []string{"sgr-2"}},
+		{"?service=neutron&resource_type=security_group_rule&limit=1&offset=1", []string{"sgr-2"}},
+	}
+	for _, tt := range tests {
+		rec := do(t, h, http.MethodGet, "/v1/findings"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d", tt.query, rec.Code)
+		}
+		var resp findingsResponse
+		decode(t, rec, &resp)
+		var got []string
+		for _, f := range resp.Findings {
+			got = append(got, f.ResourceID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GET /v1/findings%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if rec := do(t, h, http.MethodGet, "/v1/findings?limit=-1", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit = %d, want 400", rec.Code)
+	}
+}
+
+func TestServer_ScopedScanReplacesScope(t *testing.T) {
+	scanner := &fakeScanner{batches: [][]*audit.Result{
+		{
+			result(sshRule, "sgr-1", "p1", "high", false),
+			result(vmRule, "vm-1", "p1", "low", false),
+		},
+		{
+			result(sshRule, "sgr-1", "p1", "high", true),
+		},
+	}}
+	s := NewServer(testPolicy(), scanner, Options{})
+	h := s.Handler()
+	scanAndWait(t, s, h, "")
+	scan := scanAndWait(t, s, h, `{"service":"neutron","resource_type":"security_group_rule"}`)
+
+	if got := scanner.policies[1].GetAllRules(); len(got) != 1 || got[0].Name != "open-ssh" {
+		t.Fatalf("scoped scan rules = %+v", got)
+	}
+
+	var resp findingsResponse
+	decode(t, do(t, h, http.MethodGet, "/v1/findings", ""), &resp)
+	if resp.Total != 1 || resp.Findings[0].ResourceID != "vm-1" {
+		t.Fatalf("findings after scoped scan = %+v", resp.Findings)
+	}
+
+	rec := do(t, h, http.MethodGet, "/v1/scans/"+scan.ID, "")
+	var got Scan
+	decode(t, rec, &got)
+	if got.State != ScanCompleted || got.Scanned != 1 || got.Violations != 0 || got.FinishedAt == nil {
+		t.Fatalf("scan = %+v", got)
+	}
+
+	var list struct{ Scans []Scan }
+	decode(t, do(t, h, http.MethodGet, "/v1/scans", ""), &list)
+	if len(list.Scans) != 2 || list.Scans[0].ID != scan.ID {
+		t.Fatalf("scans = %+v", list.Scans)
+	}
+}
+
+func TestServer_History(t *testing.T) {
+	scanner := &fakeScanner{batches: [][]*audit.Result{
+		{result(sshRule, "sgr-1", "p1", "high", false)},
+		{result(sshRule, "sgr-1", "p1", "high", true)},
+	}}
+	s := NewServer(testPolicy(), scanner, Options{})
+	h := s.Handler()
+	first := scanAndWait(t, s, h, "")
+	second := scanAndWait(t, s, h, "")
+
+	rec := do(t, h, http.MethodGet, "/v1/resources/sgr-1/history", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("history = %d", rec.Code)
+	}
+	var resp historyResponse
+	decode(t, rec, &resp)
+	if len(resp.History) != 2 {
+		t.Fatalf("history = %+v", resp.History)
+	}
+	if resp.History[0].RunID != first.ID || resp.History[0].Compliant {
+		t.Errorf("first entry = %+v", resp.History[0])
+	}
+	if resp.History[1].RunID != second.ID || !resp.History[1].Compliant {
+		t.Errorf("second entry = %+v", resp.History[1])
+	}
+
+	if rec := do(t, h, http.MethodGet, "/v1/resources/unknown/history", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown resource = %d, want 404", rec.Code)
+	}
+}
+
+func TestServer_StartScanErrors(t *testing.T) {
+	scanner := &fakeScanner{
+		batches: [][]*audit.Result{{result(vmRule, "vm-1", "p1", "low", false)}},
+		release: make(chan struct{}),
+	}
+	s := NewServer(testPolicy(), scanner, Options{})
+	h := s.Handler()
+
+	if rec := do(t, h, http.MethodPost, "/v1/scans", `{"service":"cinder"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown service = %d, want 400", rec.Code)
+	}
+	if rec := do(t, h, http.MethodPost, "/v1/scans", `{"servce":"nova"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown field = %d, want 400", rec.Code)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/v1/scans", `{"service":"nova"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("first scan = %d", rec.Code)
+	}
+	if rec := do(t, h, http.MethodPost, "/v1/scans", ""); rec.Code != http.StatusConflict {
+		t.Errorf("concurrent scan = %d, want 409", rec.Code)
+	}
+	close(scanner.release)
+	s.Wait()
+
+	if rec := do(t, h, http.MethodGet, "/v1/scans/42", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown scan = %d, want 404", rec.Code)
+	}
+}
+
+func TestServer_PolicyAndOpenAPI(t *testing.T) {
+	s := NewServer(testPolicy(), &fakeScanner{}, Options{})
+	h := s.Handler()
+
+	rec := do(t, h, http.MethodGet, "/v1/policy", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "open-ssh") {
+		t.Fatalf("policy = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(t, h, http.MethodGet, "/openapi.json", "")
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	decode(t, rec, &spec)
+	for _, path := range []string{"/v1/findings", "/v1/resources/{id}/history", "/v1/scans", "/v1/scans/{id}", "/v1/policy", "/v1/approvals"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("OpenAPI description is missing %s", path)
+		}
+	}
+}
+
+func TestServer_PolicyRedactsNotificationCredentials(t *testing.T) {
+	p := testPolicy()
+	p.Notifications = []policy.Notification{{
+		Name:    "slack",
+		Type:    "slack",
+		URL:     "https://hooks.slack.com/services/T000/B000/XXXX",
+		Headers: map[string]string{"Authorization": "Bearer hook-token"},
+		Secret:  "signing-key",
+	}}
+	h := NewServer(p, &fakeScanner{}, Options{}).Handler()
+
+	body := do(t, h, http.MethodGet, "/v1/policy", "").Body.String()
+	for _, secret := range []string{"XXXX", "hook-token", "signing-key"} {
+		if strings.Contains(body, secret) {
+			t.Errorf("policy exposes %q:\n%s", secret, body)
+		}
+	}
+	if !strings.Contains(body, "https://hooks.slack.com/"+policy.RedactedValue) {
+		t.Errorf("policy does not show the redacted URL:\n%s", body)
+	}
+	if p.Notifications[0].Secret != "signing-key" {
+		t.Error("redacting changed the loaded policy")
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	h := NewServer(testPolicy(), &fakeScanner{}, Options{Token: "s3cret"}).Handler()
+
+	request := func(target, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	tests := []struct {
+		target, authorization string
+		want                  int
+	}{
+		{"/v1/findings", "", http.StatusUnauthorized},
+		{"/v1/findings", "Bearer wrong", http.StatusUnauthorized},
+		{"/v1/findings", "Bearer s3cret", http.StatusOK},
+		{"/v1/policy", "s3cret", http.StatusUnauthorized},
+		{"/openapi.json", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := request(tt.target, tt.authorization); got != tt.want {
+			t.Errorf("GET %s with %q = %d, want %d", tt.target, tt.authorization, got, tt.want)
+		}
+	}
+}
+
+func TestServer_Approvals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals.json")
+	store, err := approval.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	item, err := store.Request(approval.Item{RuleID: "r1", Service: "nova", ResourceType: "instance", ResourceID: "vm-1", Action: "delete"})
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	h := NewServer(testPolicy(), &fakeScanner{}, Options{ApprovalStore: path}).Handler()
+
+	var list struct{ Approvals []approval.Item }
+	decode(t, do(t, h, http.MethodGet, "/v1/approvals", ""), &list)
+	if len(list.Approvals) != 1 || list.Approvals[0].ID != item.ID {
+		t.Fatalf("approvals = %+v", list.Approvals)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/v1/approvals/"+item.ID+"/approve", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing reviewer = %d, want 400", rec.Code)
+	}
+	if rec := do(t, h, http.MethodPost, "/v1/approvals/nope/approve", `{"reviewer":"alice"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown approval = %d, want 404", rec.Code)
+	}
+
+	rec := do(t, h, http.MethodPost, "/v1/approvals/"+item.ID+"/approve", `{"reviewer":"alice","comment":"ok"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("approve = %d %s", rec.Code, rec.Body.String())
+	}
+	var decided approval.Item
+	decode(t, rec, &decided)
+	if decided.State != approval.StateApproved || decided.Reviewer != "alice" {
+		t.Fatalf("approved item = %+v", decided)
+	}
+
+	if rec := do(t, h, http.MethodPost, "/v1/approvals/"+item.ID+"/reject", `{"reviewer":"bob"}`); rec.Code != http.StatusConflict {
+		t.Errorf("reject decided item = %d, want 409", rec.Code)
+	}
+
+	reopened, err := approval.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got, _ := reopened.Get(item.ID); got.State != approval.StateApproved {
+		t.Fatalf("persisted state = %s", got.State)
+	}
+
+	disabled := NewServer(testPolicy(), &fakeScanner{}, Options{}).Handler()
+	if rec := do(t, disabled, http.MethodGet, "/v1/approvals", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("approvals without store = %d, want 404", rec.Code)
+	}
+}
+
+func TestServer_PersistentHistory(t *testing.T) {
+	store, err := history.Open(t.TempDir(), history.Options{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	scanner := &fakeScanner{
+		batches: [][]*audit.Result{
+			{result(sshRule, "sgr-1", "p1", "high", false), result(vmRule, "vm-1", "p1", "low", false)},
+			{result(vmRule, "vm-1", "p1", "low", true)},
+		},
+		discovered: []string{"neutron/security_group_rule", "nova/instance"},
+	}
+	s := NewServer(testPolicy(), scanner, Options{History: store})
+	h := s.Handler()
+	first := scanAndWait(t, s, h, "")
+	scanAndWait(t, s, h, `{"service":"nova"}`)
+
+	runs := store.Runs()
+	if len(runs) != 2 || runs[0].ID != first.ID || runs[1].Service != "nova" {
+		t.Fatalf("recorded runs = %+v", runs)
+	}
+
+	var hist historyResponse
+	decode(t, do(t, h, http.MethodGet, "/v1/resources/vm-1/history", ""), &hist)
+	if len(hist.History) != 2 || hist.History[0].RunID != first.ID || !hist.History[1].Compliant {
+		t.Fatalf("history = %+v", hist.History)
+	}
+
+	var incidents struct{ Incidents []history.Incident }
+	decode(t, do(t, h, http.MethodGet, "/v1/incidents", ""), &incidents)
+	if len(incidents.Incidents) != 1 || incidents.Incidents[0].ResourceID != "sgr-1" {
+		t.Fatalf("open incidents = %+v", incidents.Incidents)
+	}
+	decode(t, do(t, h, http.MethodGet, "/v1/incidents?state=resolved&rule=shutoff-vm", ""), &incidents)
+	if len(incidents.Incidents) != 1 || incidents.Incidents[0].ResourceID != "vm-1" {
+		t.Fatalf("resolved incidents = %+v", incidents.Incidents)
+	}
+
+	var mttr struct{ Rules []history.RuleMTTR }
+	decode(t, do(t, h, http.MethodGet, "/v1/mttr", ""), &mttr)
+	if len(mttr.Rules) != 2 || mttr.Rules[1].RuleID != "shutoff-vm" || mttr.Rules[1].Resolved != 1 {
+		t.Fatalf("mttr = %+v", mttr.Rules)
+	}
+
+	memory := NewServer(testPolicy(), &fakeScanner{}, Options{}).Handler()
+	if rec := do(t, memory, http.MethodGet, "/v1/incidents", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("incidents without history = %d, want 404", rec.Code)
+	}
+}
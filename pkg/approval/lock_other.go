@@ -0,0 +1,9 @@
+//go:build !unix
+
+package approval
+
+// lockFile is a no-op where flock is unavailable; the store is then only
+// safe for a single writing process.
+func lockFile(string) (func(), error) {
+	return func() {}, nil
+}
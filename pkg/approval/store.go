@@ -0,0 +1,274 @@
+package approval
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/atomicfile"
+)
+
+// Approval states.
+const (
+	StatePending  = "pending"
+	StateApproved = "approved"
+	StateRejected = "rejected"
+	StateExecuted = "executed"
+)
+
+// Item is a remediation that needs a reviewer's decision before it runs.
+type Item struct {
+	ID           string     `json:"id"`
+	RuleID       string     `json:"rule_id"`
+	Service      string     `json:"service"`
+	ResourceType string     `json:"resource_type"`
+	ResourceID   string     `json:"resource_id"`
+	ResourceName string     `json:"resource_name,omitempty"`
+	ProjectID    string     `json:"project_id,omitempty"`
+	Action       string     `json:"action"`
+	State        string     `json:"state"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	Reviewer     string     `json:"reviewer,omitempty"`
+	Comment      string     `json:"comment,omitempty"`
+	ExecutedAt   *time.Time `json:"executed_at,omitempty"`
+}
+
+// ItemID returns the stable ID of the approval for a rule applied to a
+// resource, so repeated runs find the same item.
+func ItemID(ruleID, service, resourceType, resourceID string) string {
+	sum := sha256.Sum256([]byte(ruleID + "\x00" + service + "\x00" + resourceType + "\x00" + resourceID))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Store is a JSON file of approval items shared by the agent and the
+// approvals command. Changes are made under an exclusive lock on
+// path+".lock" and start from the file's current contents, so concurrent
+// writers do not lose each other's updates. Decisions and executions are
+// written immediately; requests are kept in memory until Flush, so a run
+// writes the file once rather than once per violation. List and Get read the
+// items as of the last change.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	items map[string]*Item
+	// requested holds the requests recorded since the last write.
+	requested map[string]Item
+	now       func() time.Time
+}
+
+// Open loads the store at path. A missing file yields an empty store; the
+// file is created on the first change.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, requested: make(map[string]Item), now: time.Now}
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadLocked replaces the items with the file's contents and applies the
+// requests not written yet. The caller must hold s.mu, and the file lock
+// when the items are about to be changed.
+func (s *Store) loadLocked() error {
+	items := make(map[string]*Item)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading approval store %q: %w", s.path, err)
+	}
+	if len(data) > 0 {
+		var list []*Item
+		if err := json.Unmarshal(data, &list); err != nil {
+			return fmt.Errorf("parsing approval store %q: %w", s.path, err)
+		}
+		for _, item := range list {
+			items[item.ID] = item
+		}
+	}
+	s.items = items
+
+	for id, item := range s.requested {
+		if _, added := s.requestLocked(item); !added {
+			// Requested or decided elsewhere in the meantime.
+			delete(s.requested, id)
+		}
+	}
+	return nil
+}
+
+// update re-reads the store and applies change under the file lock, then
+// writes the result, including the pending requests, unless there is
+// nothing to write.
+func (s *Store) update(change func() (bool, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking approval store %q: %w", s.path, err)
+	}
+	defer unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	changed, err := change()
+	if err != nil || (!changed && len(s.requested) == 0) {
+		return err
+	}
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	clear(s.requested)
+	return nil
+}
+
+// Flush writes the requests recorded since the last write.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	pending := len(s.requested)
+	s.mu.Unlock()
+	if pending == 0 {
+		return nil
+	}
+	return s.update(func() (bool, error) { return false, nil })
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns the items in the given state, or all items when state is
+// empty, oldest request first.
+func (s *Store) List(state string) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Item
+	for _, item := range s.items {
+		if state == "" || item.State == state {
+			out = append(out, *item)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].RequestedAt.Equal(out[j].RequestedAt) {
+			return out[i].RequestedAt.Before(out[j].RequestedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Get returns the item with the given ID.
+func (s *Store) Get(id string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return Item{}, false
+	}
+	return *item, true
+}
+
+// Request returns the existing decision for the item's rule and resource, or
+// records a new pending item. An executed item is replaced by a new request,
+// since the resource has become non-compliant again, and so is an item for
+// a different action: a decision only covers the action it was made for.
+// New requests are written by the next Flush or change.
+func (s *Store) Request(item Item) (Item, error) {
+	item.ID = ItemID(item.RuleID, item.Service, item.ResourceType, item.ResourceID)
+	item.RequestedAt = s.now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, added := s.requestLocked(item)
+	if added {
+		s.requested[item.ID] = item
+	}
+	return item, nil
+}
+
+// requestLocked applies a request to the items and reports whether it added
+// a pending item. The caller must hold s.mu.
+func (s *Store) requestLocked(item Item) (Item, bool) {
+	if existing, ok := s.items[item.ID]; ok && existing.State != StateExecuted && existing.Action == item.Action {
+		return *existing, false
+	}
+
+	item.State = StatePending
+	item.DecidedAt = nil
+	item.Reviewer = ""
+	item.Comment = ""
+	item.ExecutedAt = nil
+	added := item
+	s.items[item.ID] = &added
+	return item, true
+}
+
+// Approve marks a pending item as approved; it runs on the next audit in
+// which the resource is still non-compliant.
+func (s *Store) Approve(id, reviewer, comment string) error {
+	return s.decide(id, StateApproved, reviewer, comment)
+}
+
+// Reject marks a pending item as rejected; the remediation will not run.
+func (s *Store) Reject(id, reviewer, comment string) error {
+	return s.decide(id, StateRejected, reviewer, comment)
+}
+
+func (s *Store) decide(id, state, reviewer, comment string) error {
+	return s.update(func() (bool, error) {
+		item, ok := s.items[id]
+		if !ok {
+			return false, fmt.Errorf("approval %q not found", id)
+		}
+		if item.State != StatePending {
+			return false, fmt.Errorf("approval %q is %s, not pending", id, item.State)
+		}
+
+		now := s.now().UTC()
+		item.State = state
+		item.DecidedAt = &now
+		item.Reviewer = reviewer
+		item.Comment = comment
+		return true, nil
+	})
+}
+
+// MarkExecuted records that an approved remediation ran successfully.
+func (s *Store) MarkExecuted(id string) error {
+	return s.update(func() (bool, error) {
+		item, ok := s.items[id]
+		if !ok {
+			return false, fmt.Errorf("approval %q not found", id)
+		}
+		now := s.now().UTC()
+		item.State = StateExecuted
+		item.ExecutedAt = &now
+		return true, nil
+	})
+}
+
+// saveLocked writes the store atomically. The caller must hold s.mu and the
+// file lock.
+func (s *Store) saveLocked() error {
+	items := make([]*Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	if err := atomicfile.WriteJSON(s.path, items); err != nil {
+		return fmt.Errorf("writing approval store: %w", err)
+	}
+	return nil
+}
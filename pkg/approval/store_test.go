@@ -0,0 +1,231 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "approvals.json"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return s
+}
+
+func testItem() Item {
+	return Item{RuleID: "r1", Service: "nova", ResourceType: "instance", ResourceID: "srv-1", Action: "delete"}
+}
+
+func TestStore_RequestIsIdempotent(t *testing.T) {
+	s := newTestStore(t)
+
+	first, err := s.Request(testItem())
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if first.State != StatePending || first.ID == "" {
+		t.Fatalf("Request() = %+v, want pending item with ID", first)
+	}
+
+	second, err := s.Request(testItem())
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if second.ID != first.ID || len(s.List("")) != 1 {
+		t.Fatalf("expected a single item, got %+v", s.List(""))
+	}
+}
+
+func TestStore_ApprovePersists(t *testing.T) {
+	s := newTestStore(t)
+	item, _ := s.Request(testItem())
+
+	if err := s.Approve(item.ID, "alice", "ok"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if err := s.Reject(item.ID, "bob", ""); err == nil {
+		t.Fatal("Reject() of an approved item expected error")
+	}
+
+	reopened, err := Open(s.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, ok := reopened.Get(item.ID)
+	if !ok || got.State != StateApproved || got.Reviewer != "alice" || got.DecidedAt == nil {
+		t.Fatalf("reopened item = %+v, want approved by alice", got)
+	}
+}
+
+func TestStore_ExecutedItemIsRequestedAgain(t *testing.T) {
+	s := newTestStore(t)
+	item, _ := s.Request(testItem())
+	_ = s.Approve(item.ID, "alice", "")
+	if err := s.MarkExecuted(item.ID); err != nil {
+		t.Fatalf("MarkExecuted() error = %v", err)
+	}
+
+	again, err := s.Request(testItem())
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if again.State != StatePending || again.Reviewer != "" {
+		t.Fatalf("Request() after execution = %+v, want fresh pending item", again)
+	}
+}
+
+func TestStore_ListFiltersByState(t *testing.T) {
+	s := newTestStore(t)
+	a, _ := s.Request(testItem())
+	other := testItem()
+	other.ResourceID = "srv-2"
+	_, _ = s.Request(other)
+	_ = s.Reject(a.ID, "alice", "keep it")
+
+	if got := s.List(StatePending); len(got) != 1 || got[0].ResourceID != "srv-2" {
+		t.Fatalf("List(pending) = %+v, want srv-2 only", got)
+	}
+	if got := s.List(StateRejected); len(got) != 1 || got[0].Comment != "keep it" {
+		t.Fatalf("List(rejected) = %+v", got)
+	}
+}
+
+func TestStore_DecideUnknownID(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Approve("missing", "alice", ""); err == nil {
+		t.Fatal("Approve() of unknown ID expected error")
+	}
+}
+
+func TestStore_TwoStoresShareFile(t *testing.T) {
+	agent := newTestStore(t)
+	cli, err := Open(agent.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	item, _ := agent.Request(testItem())
+	if err := agent.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	other := testItem()
+	other.ResourceID = "srv-2"
+	// cli was opened before the agent's request; its write must keep it.
+	if _, err := cli.Request(other); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if err := cli.Approve(item.ID, "alice", ""); err != nil {
+		t.Fatalf("Approve() through the second store error = %v", err)
+	}
+
+	// The next run opens the store again and sees the decision.
+	next, err := Open(agent.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, err := next.Request(testItem())
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if got.State != StateApproved {
+		t.Errorf("agent sees %s, want the approval made through the other store", got.State)
+	}
+	if items := next.List(""); len(items) != 2 {
+		t.Fatalf("store holds %d items, want 2: %+v", len(items), items)
+	}
+}
+
+func TestStore_RequestsAreWrittenOnFlush(t *testing.T) {
+	s := newTestStore(t)
+	for i := 0; i < 3; i++ {
+		item := testItem()
+		item.ResourceID = fmt.Sprintf("srv-%d", i)
+		if _, err := s.Request(item); err != nil {
+			t.Fatalf("Request() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(s.Path()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("store written before Flush: %v", err)
+	}
+
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	reopened, err := Open(s.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if items := reopened.List(StatePending); len(items) != 3 {
+		t.Fatalf("store holds %d pending items, want 3", len(items))
+	}
+}
+
+func TestStore_ApprovalDoesNotCoverAnotherAction(t *testing.T) {
+	s := newTestStore(t)
+	stop := testItem()
+	stop.Action = "stop"
+	item, _ := s.Request(stop)
+	if err := s.Approve(item.ID, "alice", "stopping is fine"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	// The rule's action is changed to delete, e.g. by an override.
+	got, err := s.Request(testItem())
+	if err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if got.State != StatePending || got.Action != "delete" || got.Reviewer != "" {
+		t.Fatalf("Request(delete) after approving stop = %+v, want a new pending request", got)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	reopened, err := Open(s.Path())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if stored, _ := reopened.Get(item.ID); stored.State != StatePending || stored.Action != "delete" {
+		t.Fatalf("stored item = %+v, want pending delete", stored)
+	}
+}
+
+func TestStore_ConcurrentWritersKeepAllItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approvals.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		s, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			item := testItem()
+			item.ResourceID = fmt.Sprintf("srv-%d", i)
+			if _, err := s.Request(item); err != nil {
+				t.Errorf("Request() error = %v", err)
+			}
+			if err := s.Flush(); err != nil {
+				t.Errorf("Flush() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if items := s.List(""); len(items) != 8 {
+		t.Fatalf("store holds %d items, want 8", len(items))
+	}
+}
@@ -0,0 +1,44 @@
+// Package atomicfile replaces files atomically, so readers of the state files
+// kept by the agent (history, approvals, rollout) never see a partial write.
+package atomicfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Write replaces path with data. The data is written to a temporary file in
+// the same directory, synced, and renamed over path; on error path is left
+// unchanged.
+func Write(path string, data []byte) error {
+	pattern := "." + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "-*"
+	tmp, err := os.CreateTemp(filepath.Dir(path), pattern)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteJSON replaces path with v encoded as indented JSON.
+func WriteJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return Write(path, append(data, '\n'))
+}
@@ -0,0 +1,51 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSON_ReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteJSON(path, map[string]int{"a": 1}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "{\n  \"a\": 1\n}\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want the file only", len(entries))
+	}
+}
+
+func TestWriteJSON_EncodingErrorLeavesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteJSON(path, func() {}); err == nil {
+		t.Fatal("WriteJSON(func) expected error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("file = %q, want it unchanged", data)
+	}
+}
+
+func TestWrite_MissingDirectory(t *testing.T) {
+	if err := Write(filepath.Join(t.TempDir(), "missing", "state.json"), []byte("x")); err == nil {
+		t.Fatal("Write() expected error for a missing directory")
+	}
+}
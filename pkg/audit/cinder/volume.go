@@ -3,21 +3,32 @@ package cinder
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit/common"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
-	// TODO: Import the gophercloud resource type for volume.
-	// Example: "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
 )
 
+// volumeAdapter has no project: the v3 volume type does not carry it, so the
+// orchestrator takes it from discovery.
+type volumeAdapter struct{ v volumes.Volume }
+
+func (a volumeAdapter) GetID() string           { return a.v.ID }
+func (a volumeAdapter) GetName() string         { return a.v.Name }
+func (a volumeAdapter) GetProjectID() string    { return "" }
+func (a volumeAdapter) GetStatus() string       { return a.v.Status }
+func (a volumeAdapter) GetCreatedAt() time.Time { return a.v.CreatedAt }
+func (a volumeAdapter) GetUpdatedAt() time.Time { return a.v.UpdatedAt }
+
 // VolumeAuditor audits cinder/volume resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, encrypted, attached
+// Allowed actions: log, delete, detach
 //
-// TODO: Cast 'resource' to the correct gophercloud type and implement checks.
-// Gophercloud docs: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
-// OpenStack API: https://docs.openstack.org/api-ref/cinder
+// A volume is unused when it has no attachments.
 type VolumeAuditor struct{}
 
 func (a *VolumeAuditor) ResourceType() string {
@@ -25,59 +36,90 @@ func (a *VolumeAuditor) ResourceType() string {
 }
 
 func (a *VolumeAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "encrypted", "attached"}
 }
 
 func (a *VolumeAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
 	_ = ctx
 
-	// TODO: Cast resource to the correct type.
-	// Example: r := resource.(servers.Server)
-	//
-	// Then populate the result:
-	//   result.ResourceID = r.ID
-	//   result.ResourceName = r.Name
-	//   result.ProjectID = r.TenantID
-	//   result.Status = r.Status
-	//   result.UpdatedAt = r.Updated
-	//
-	// Implement checks based on rule.Check fields:
-	//   - Status: compare r.Status with rule.Check.Status
-	//   - AgeGT: compare time.Since(r.Updated) with rule.Check.AgeGT
-	//   - Unused: implement resource-specific unused detection
-	//   - ExemptNames: skip if r.Name matches any exempt pattern
-
-	result := &audit.Result{
-		RuleID:       rule.Name,
-		ResourceID:   "unknown",
-		ResourceName: "unknown",
-		ProjectID:    "",
-		Compliant:    true,
-		Rule:         rule,
-		Status:       "",
+	volume, ok := resource.(volumes.Volume)
+	if !ok {
+		return nil, fmt.Errorf("expected volumes.Volume, got %T", resource)
+	}
+
+	adapter := volumeAdapter{v: volume}
+	result := common.BuildBaseResult(adapter, rule)
+
+	exempt, err := common.RunCommonChecks(adapter, rule, result)
+	if exempt || err != nil {
+		return result, err
+	}
+
+	attached := len(volume.Attachments) > 0
+
+	if rule.Check.Unused {
+		if !attached {
+			result.Compliant = false
+			result.Observation = "volume has no attachments"
+		}
+	}
+
+	if rule.Check.Attached != nil {
+		if attached == *rule.Check.Attached {
+			result.Compliant = false
+			if attached {
+				result.Observation = fmt.Sprintf("volume is attached to %d server(s)", len(volume.Attachments))
+			} else {
+				result.Observation = "volume is not attached"
+			}
+		}
+	}
+
+	if rule.Check.Encrypted != nil {
+		if volume.Encrypted == *rule.Check.Encrypted {
+			result.Compliant = false
+			if volume.Encrypted {
+				result.Observation = "volume is encrypted"
+			} else {
+				result.Observation = "volume is not encrypted"
+			}
+		}
 	}
 
-	_ = resource
 	return result, nil
 }
 
 func (a *VolumeAuditor) Fix(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
 	_ = ctx
-	_ = client
-	_ = resource
-
-	// TODO: Implement remediation actions.
-	// Cast client to *gophercloud.ServiceClient.
-	// Allowed actions: log, delete, tag
-	//
-	// Example for delete:
-	//   c := client.(*gophercloud.ServiceClient)
-	//   r := resource.(servers.Server)
-	//   return servers.Delete(c, r.ID).ExtractErr()
 
+	// Log action doesn't require client or resource validation
+	if rule.Action == "log" {
+		return nil
+	}
+
+	c, ok := client.(*gophercloud.ServiceClient)
+	if !ok {
+		return fmt.Errorf("expected *gophercloud.ServiceClient, got %T", client)
+	}
+
+	volume, ok := resource.(volumes.Volume)
+	if !ok {
+		return fmt.Errorf("expected volumes.Volume, got %T", resource)
+	}
+
+	// detach is carried out by the detach remediator, which needs the
+	// compute client as well.
 	switch rule.Action {
-	case "log":
+
+	case "delete":
+		if len(volume.Attachments) > 0 {
+			return fmt.Errorf("cannot delete volume %s: attached to %d server(s)", volume.ID, len(volume.Attachments))
+		}
+		if err := volumes.Delete(c, volume.ID, volumes.DeleteOpts{}).ExtractErr(); err != nil {
+			return fmt.Errorf("deleting volume %s: %w", volume.ID, err)
+		}
 		return nil
+
 	default:
 		return fmt.Errorf("%s/%s: action %q not implemented", "cinder", "volume", rule.Action)
 	}
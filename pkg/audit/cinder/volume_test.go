@@ -2,9 +2,15 @@ package cinder
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
 )
 
 func TestVolumeAuditor_ResourceType(t *testing.T) {
@@ -15,31 +21,148 @@ func TestVolumeAuditor_ResourceType(t *testing.T) {
 }
 
 func TestVolumeAuditor_Check(t *testing.T) {
-	auditor := &VolumeAuditor{}
-
-	// TODO: Replace with the real gophercloud type once the auditor is implemented.
-	resource := map[string]interface{}{"id": "test-id", "name": "test-resource"}
+	yes, no := true, false
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	attachments := []volumes.Attachment{{ServerID: "srv-1", VolumeID: "vol-1"}}
 
-	rule := &policy.Rule{
-		Name:     "test-rule",
-		Service:  "cinder",
-		Resource: "volume",
-		Check:    policy.CheckConditions{Status: "active"},
-		Action:   "log",
+	tests := []struct {
+		name          string
+		volume        volumes.Volume
+		check         policy.CheckConditions
+		wantCompliant bool
+	}{
+		{
+			name:          "status matches",
+			volume:        volumes.Volume{ID: "vol-1", Status: "error"},
+			check:         policy.CheckConditions{Status: "error"},
+			wantCompliant: false,
+		},
+		{
+			name:          "older than age_gt",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use", CreatedAt: old, UpdatedAt: old},
+			check:         policy.CheckConditions{AgeGT: "30d"},
+			wantCompliant: false,
+		},
+		{
+			name:          "no attachments is unused",
+			volume:        volumes.Volume{ID: "vol-1", Status: "available"},
+			check:         policy.CheckConditions{Unused: true},
+			wantCompliant: false,
+		},
+		{
+			name:          "attached is used",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use", Attachments: attachments},
+			check:         policy.CheckConditions{Unused: true},
+			wantCompliant: true,
+		},
+		{
+			name:          "attached false flags detached volume",
+			volume:        volumes.Volume{ID: "vol-1", Status: "available"},
+			check:         policy.CheckConditions{Attached: &no},
+			wantCompliant: false,
+		},
+		{
+			name:          "attached false passes attached volume",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use", Attachments: attachments},
+			check:         policy.CheckConditions{Attached: &no},
+			wantCompliant: true,
+		},
+		{
+			name:          "encrypted false flags plain volume",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use"},
+			check:         policy.CheckConditions{Encrypted: &no},
+			wantCompliant: false,
+		},
+		{
+			name:          "encrypted false passes encrypted volume",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use", Encrypted: true},
+			check:         policy.CheckConditions{Encrypted: &no},
+			wantCompliant: true,
+		},
+		{
+			name:          "encrypted true flags encrypted volume",
+			volume:        volumes.Volume{ID: "vol-1", Status: "in-use", Encrypted: true},
+			check:         policy.CheckConditions{Encrypted: &yes},
+			wantCompliant: false,
+		},
+		{
+			name:          "exempt by name",
+			volume:        volumes.Volume{ID: "vol-1", Name: "keep-data", Status: "available"},
+			check:         policy.CheckConditions{Unused: true, ExemptNames: []string{"keep-*"}},
+			wantCompliant: true,
+		},
 	}
 
-	result, err := auditor.Check(context.Background(), resource, rule)
-	if err != nil {
-		t.Fatalf("Check() error = %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &policy.Rule{Name: "test-rule", Service: "cinder", Resource: "volume", Check: tt.check, Action: "log"}
+
+			result, err := (&VolumeAuditor{}).Check(context.Background(), tt.volume, rule)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if result.Compliant != tt.wantCompliant {
+				t.Errorf("Compliant = %v, want %v (observation %q)", result.Compliant, tt.wantCompliant, result.Observation)
+			}
+			if result.ResourceID != "vol-1" || result.RuleID != rule.Name {
+				t.Errorf("result = %+v, want resource vol-1", result)
+			}
+		})
 	}
-	if result == nil {
-		t.Fatal("Check() returned nil result")
+}
+
+func TestVolumeAuditor_Check_WrongType(t *testing.T) {
+	rule := &policy.Rule{Name: "test-rule", Action: "log"}
+	if _, err := (&VolumeAuditor{}).Check(context.Background(), map[string]interface{}{"id": "x"}, rule); err == nil {
+		t.Fatal("Check(map) expected error")
 	}
-	if result.RuleID != rule.Name {
-		t.Errorf("Result.RuleID = %q, want %q", result.RuleID, rule.Name)
+}
+
+func newTestVolumeClient(t *testing.T, calls *[]string) *gophercloud.ServiceClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
 	}
 }
 
 func TestVolumeAuditor_Fix(t *testing.T) {
-	t.Skip("Fix() requires a mock gophercloud client")
+	tests := []struct {
+		name      string
+		action    string
+		volume    volumes.Volume
+		wantCalls []string
+		wantErr   bool
+	}{
+		{name: "log", action: "log", volume: volumes.Volume{ID: "vol-1"}},
+		{name: "delete", action: "delete", volume: volumes.Volume{ID: "vol-1", Status: "available"}, wantCalls: []string{"DELETE /volumes/vol-1"}},
+		{
+			name:    "delete attached",
+			action:  "delete",
+			volume:  volumes.Volume{ID: "vol-1", Status: "in-use", Attachments: []volumes.Attachment{{ServerID: "srv-1"}}},
+			wantErr: true,
+		},
+		{name: "unsupported", action: "tag", volume: volumes.Volume{ID: "vol-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			c := newTestVolumeClient(t, &calls)
+			rule := &policy.Rule{Name: "r1", Action: tt.action}
+
+			err := (&VolumeAuditor{}).Fix(context.Background(), c, tt.volume, rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fix(%s) error = %v, wantErr %v", tt.action, err, tt.wantErr)
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
 }
@@ -21,6 +21,13 @@ type ResourceAdapter interface {
 	GetUpdatedAt() time.Time
 }
 
+// TaggedResource is implemented by adapters whose underlying resource
+// carries tags (e.g. Neutron standard-attr resources). RunCommonChecks uses
+// it to honour exempt_tags.
+type TaggedResource interface {
+	GetTags() []string
+}
+
 // BuildBaseResult constructs an audit.Result pre-populated with fields from
 // the adapter and the rule. Auditors should call this first, then layer on
 // service-specific logic.
@@ -57,6 +64,29 @@ func CheckExemptByName(a ResourceAdapter, rule *policy.Rule, result *audit.Resul
 	return false
 }
 
+// CheckExemptByTag returns true (and sets the observation) when the
+// resource carries any tag listed in rule.Check.ExemptTags. Adapters that do
+// not implement TaggedResource are never exempt by tag.
+func CheckExemptByTag(a ResourceAdapter, rule *policy.Rule, result *audit.Result) bool {
+	if len(rule.Check.ExemptTags) == 0 {
+		return false
+	}
+	tagged, ok := a.(TaggedResource)
+	if !ok {
+		return false
+	}
+	for _, tag := range tagged.GetTags() {
+		for _, exempt := range rule.Check.ExemptTags {
+			if tag == exempt {
+				result.Compliant = true
+				result.Observation = fmt.Sprintf("exempt by tag %q", tag)
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // CheckStatus marks the result non-compliant when the resource status
 // matches the value declared in the policy rule.
 func CheckStatus(a ResourceAdapter, rule *policy.Rule, result *audit.Result) {
@@ -99,7 +129,7 @@ func CheckAgeGT(a ResourceAdapter, rule *policy.Rule, result *audit.Result) erro
 }
 
 // RunCommonChecks executes the universal check sequence that applies to
-// every resource type: exempt_names -> status -> age_gt.
+// every resource type: exempt_names -> exempt_tags -> status -> age_gt.
 //
 // It returns true if the resource is exempt (and therefore the auditor
 // should short-circuit). The caller is responsible for unused and any
@@ -108,6 +138,9 @@ func RunCommonChecks(a ResourceAdapter, rule *policy.Rule, result *audit.Result)
 	if CheckExemptByName(a, rule, result) {
 		return true, nil
 	}
+	if CheckExemptByTag(a, rule, result) {
+		return true, nil
+	}
 
 	CheckStatus(a, rule, result)
 
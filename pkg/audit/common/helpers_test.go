@@ -223,6 +223,45 @@ func TestRunCommonChecks_ExemptShortCircuits(t *testing.T) {
 	}
 }
 
+type fakeTaggedResource struct {
+	fakeResource
+	tags []string
+}
+
+func (f fakeTaggedResource) GetTags() []string { return f.tags }
+
+func TestRunCommonChecks_ExemptByTag(t *testing.T) {
+	r := fakeTaggedResource{fakeResource: fakeResource{name: "net", status: "DOWN"}, tags: []string{"ospa-flagged"}}
+	rule := &policy.Rule{
+		Check: policy.CheckConditions{
+			Status:     "DOWN",
+			ExemptTags: []string{"ospa-flagged"},
+		},
+	}
+	result := &audit.Result{Compliant: true}
+
+	exempt, err := RunCommonChecks(r, rule, result)
+	if err != nil {
+		t.Fatalf("RunCommonChecks() error = %v", err)
+	}
+	if !exempt {
+		t.Error("expected exempt=true for tagged resource")
+	}
+	if !result.Compliant {
+		t.Error("expected compliant for exempt resource")
+	}
+}
+
+func TestCheckExemptByTag_UntaggedAdapter(t *testing.T) {
+	r := fakeResource{name: "net"}
+	rule := &policy.Rule{Check: policy.CheckConditions{ExemptTags: []string{"ospa-flagged"}}}
+	result := &audit.Result{Compliant: true}
+
+	if CheckExemptByTag(r, rule, result) {
+		t.Error("expected exempt=false for adapter without tags")
+	}
+}
+
 func TestRunCommonChecks_StatusAndAge(t *testing.T) {
 	old := time.Now().Add(-72 * time.Hour)
 	r := fakeResource{name: "prod", status: "SHUTOFF", updatedAt: old}
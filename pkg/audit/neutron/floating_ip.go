@@ -20,11 +20,12 @@ func (a floatingIpAdapter) GetProjectID() string    { return a.f.TenantID }
 func (a floatingIpAdapter) GetStatus() string       { return a.f.Status }
 func (a floatingIpAdapter) GetCreatedAt() time.Time { return a.f.CreatedAt }
 func (a floatingIpAdapter) GetUpdatedAt() time.Time { return a.f.UpdatedAt }
+func (a floatingIpAdapter) GetTags() []string       { return a.f.Tags }
 
 // FloatingIpAuditor audits neutron/floating_ip resources.
 //
-// Allowed checks: status, age_gt, unused, unassociated, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, unassociated, exempt_names, exempt_tags
+// Allowed actions: log, delete, tag, untag, disassociate
 //
 // FloatingIP has no Name field; exempt_names matches against Description.
 // Both unused and unassociated flag floating IPs with PortID == ""
@@ -36,7 +37,7 @@ func (a *FloatingIpAuditor) ResourceType() string {
 }
 
 func (a *FloatingIpAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "unassociated", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "unassociated", "exempt_names", "exempt_tags"}
 }
 
 func (a *FloatingIpAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -96,8 +97,18 @@ func (a *FloatingIpAuditor) Fix(ctx context.Context, client interface{}, resourc
 		}
 		return nil
 
-	case "tag":
-		return fmt.Errorf("neutron/floating_ip: tag action not yet implemented")
+	case "tag", "untag":
+		return applyTagAction(c, "floating_ip", fip.ID, rule)
+
+	case "disassociate":
+		if fip.PortID == "" {
+			return nil
+		}
+		portID := ""
+		if _, err := floatingips.Update(c, fip.ID, floatingips.UpdateOpts{PortID: &portID}).Extract(); err != nil {
+			return fmt.Errorf("disassociating floating IP %s from port %s: %w", fip.ID, fip.PortID, err)
+		}
+		return nil
 
 	default:
 		return fmt.Errorf("neutron/floating_ip: action %q not implemented", rule.Action)
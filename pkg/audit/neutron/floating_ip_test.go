@@ -2,6 +2,7 @@ package neutron
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -252,3 +253,40 @@ func TestFloatingIpAuditor_Fix_UnsupportedAction(t *testing.T) {
 		t.Error("Fix(reboot) expected error for unsupported action")
 	}
 }
+
+func TestFloatingIpAuditor_Fix_Disassociate(t *testing.T) {
+	var calls []string
+	var body string
+	c := newTestUpdateClient(t, `{"floatingip":{"id":"fip-123","port_id":null}}`, &calls, &body)
+
+	auditor := &FloatingIpAuditor{}
+	fip := floatingips.FloatingIP{ID: "fip-123", PortID: "port-1"}
+	rule := &policy.Rule{Name: "r1", Action: "disassociate"}
+
+	if err := auditor.Fix(context.Background(), c, fip, rule); err != nil {
+		t.Fatalf("Fix(disassociate) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "PUT /floatingips/fip-123" {
+		t.Fatalf("calls = %v, want [PUT /floatingips/fip-123]", calls)
+	}
+	if !strings.Contains(body, `"port_id":null`) {
+		t.Errorf("request body = %s, want port_id null", body)
+	}
+}
+
+func TestFloatingIpAuditor_Fix_DisassociateUnassociated(t *testing.T) {
+	var calls []string
+	var body string
+	c := newTestUpdateClient(t, `{}`, &calls, &body)
+
+	auditor := &FloatingIpAuditor{}
+	fip := floatingips.FloatingIP{ID: "fip-123"}
+	rule := &policy.Rule{Name: "r1", Action: "disassociate"}
+
+	if err := auditor.Fix(context.Background(), c, fip, rule); err != nil {
+		t.Fatalf("Fix(disassociate) error = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls, got %v", calls)
+	}
+}
@@ -22,11 +22,12 @@ func (a networkAdapter) GetProjectID() string    { return a.n.TenantID }
 func (a networkAdapter) GetStatus() string       { return a.n.Status }
 func (a networkAdapter) GetCreatedAt() time.Time { return a.n.CreatedAt }
 func (a networkAdapter) GetUpdatedAt() time.Time { return a.n.UpdatedAt }
+func (a networkAdapter) GetTags() []string       { return a.n.Tags }
 
 // NetworkAuditor audits neutron/network resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names, shared_network
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, exempt_tags, shared_network
+// Allowed actions: log, delete, tag, untag, disable
 type NetworkAuditor struct{}
 
 func (a *NetworkAuditor) ResourceType() string {
@@ -34,7 +35,7 @@ func (a *NetworkAuditor) ResourceType() string {
 }
 
 func (a *NetworkAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}
 }
 
 func (a *NetworkAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -103,10 +104,18 @@ func (a *NetworkAuditor) Fix(ctx context.Context, client interface{}, resource i
 		}
 		return nil
 
-	case "tag":
-		// Neutron networks support tags via the tags extension
-		// For now, return not implemented - would need tags extension
-		return fmt.Errorf("neutron/network: tag action not yet implemented")
+	case "tag", "untag":
+		return applyTagAction(c, "network", network.ID, rule)
+
+	case "disable":
+		if !network.AdminStateUp {
+			return nil
+		}
+		adminStateUp := false
+		if _, err := networks.Update(c, network.ID, networks.UpdateOpts{AdminStateUp: &adminStateUp}).Extract(); err != nil {
+			return fmt.Errorf("disabling network %s: %w", network.ID, err)
+		}
+		return nil
 
 	default:
 		return fmt.Errorf("neutron/network: action %q not implemented", rule.Action)
@@ -2,6 +2,7 @@ package neutron
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -200,3 +201,40 @@ func TestNetworkAuditor_Fix_Delete_RequiresClient(t *testing.T) {
 		t.Error("Fix(delete) expected error without client")
 	}
 }
+
+func TestNetworkAuditor_Fix_Disable(t *testing.T) {
+	var calls []string
+	var body string
+	c := newTestUpdateClient(t, `{"network":{"id":"net-1","admin_state_up":false}}`, &calls, &body)
+
+	auditor := &NetworkAuditor{}
+	network := networks.Network{ID: "net-1", AdminStateUp: true}
+	rule := &policy.Rule{Name: "r1", Action: "disable"}
+
+	if err := auditor.Fix(context.Background(), c, network, rule); err != nil {
+		t.Fatalf("Fix(disable) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "PUT /networks/net-1" {
+		t.Fatalf("calls = %v, want [PUT /networks/net-1]", calls)
+	}
+	if !strings.Contains(body, `"admin_state_up":false`) {
+		t.Errorf("request body = %s, want admin_state_up false", body)
+	}
+}
+
+func TestNetworkAuditor_Fix_DisableAlreadyDown(t *testing.T) {
+	var calls []string
+	var body string
+	c := newTestUpdateClient(t, `{}`, &calls, &body)
+
+	auditor := &NetworkAuditor{}
+	network := networks.Network{ID: "net-1", AdminStateUp: false}
+	rule := &policy.Rule{Name: "r1", Action: "disable"}
+
+	if err := auditor.Fix(context.Background(), c, network, rule); err != nil {
+		t.Fatalf("Fix(disable) error = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls for a disabled network, got %v", calls)
+	}
+}
@@ -20,11 +20,12 @@ func (a portAdapter) GetProjectID() string    { return a.p.TenantID }
 func (a portAdapter) GetStatus() string       { return a.p.Status }
 func (a portAdapter) GetCreatedAt() time.Time { return a.p.CreatedAt }
 func (a portAdapter) GetUpdatedAt() time.Time { return a.p.UpdatedAt }
+func (a portAdapter) GetTags() []string       { return a.p.Tags }
 
 // PortAuditor audits neutron/port resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names, no_security_group
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, exempt_tags, no_security_group
+// Allowed actions: log, delete, tag, untag, disable
 //
 // The unused check flags ports not attached to any device (DeviceID is empty).
 // The no_security_group check flags ports with no security groups attached.
@@ -35,7 +36,7 @@ func (a *PortAuditor) ResourceType() string {
 }
 
 func (a *PortAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names", "no_security_group"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags", "no_security_group"}
 }
 
 func (a *PortAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -95,8 +96,18 @@ func (a *PortAuditor) Fix(ctx context.Context, client interface{}, resource inte
 		}
 		return nil
 
-	case "tag":
-		return fmt.Errorf("neutron/port: tag action not yet implemented")
+	case "tag", "untag":
+		return applyTagAction(c, "port", port.ID, rule)
+
+	case "disable":
+		if !port.AdminStateUp {
+			return nil
+		}
+		adminStateUp := false
+		if _, err := ports.Update(c, port.ID, ports.UpdateOpts{AdminStateUp: &adminStateUp}).Extract(); err != nil {
+			return fmt.Errorf("disabling port %s: %w", port.ID, err)
+		}
+		return nil
 
 	default:
 		return fmt.Errorf("neutron/port: action %q not implemented", rule.Action)
@@ -216,10 +216,10 @@ func TestPortAuditor_Fix_Delete_RequiresClient(t *testing.T) {
 func TestPortAuditor_Fix_UnsupportedAction(t *testing.T) {
 	a := &PortAuditor{}
 	port := ports.Port{ID: "p1"}
-	rule := &policy.Rule{Name: "r1", Action: "tag"}
+	rule := &policy.Rule{Name: "r1", Action: "reboot"}
 
 	err := a.Fix(context.Background(), nil, port, rule)
 	if err == nil {
-		t.Error("expected error for tag action (not implemented)")
+		t.Error("expected error for unsupported action")
 	}
 }
@@ -21,11 +21,12 @@ func (a routerAdapter) GetProjectID() string    { return a.r.TenantID }
 func (a routerAdapter) GetStatus() string       { return a.r.Status }
 func (a routerAdapter) GetCreatedAt() time.Time { return time.Time{} }
 func (a routerAdapter) GetUpdatedAt() time.Time { return time.Time{} }
+func (a routerAdapter) GetTags() []string       { return a.r.Tags }
 
 // RouterAuditor audits neutron/router resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, exempt_tags
+// Allowed actions: log, delete, tag, untag, disable
 //
 // Note: routers.Router in gophercloud v1.14.1 has no timestamp fields.
 // The age_gt check is accepted for policy consistency but is a no-op.
@@ -38,7 +39,7 @@ func (a *RouterAuditor) ResourceType() string {
 }
 
 func (a *RouterAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}
 }
 
 func (a *RouterAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -104,8 +105,18 @@ func (a *RouterAuditor) Fix(ctx context.Context, client interface{}, resource in
 		}
 		return nil
 
-	case "tag":
-		return fmt.Errorf("neutron/router: tag action not yet implemented")
+	case "tag", "untag":
+		return applyTagAction(c, "router", router.ID, rule)
+
+	case "disable":
+		if !router.AdminStateUp {
+			return nil
+		}
+		adminStateUp := false
+		if _, err := routers.Update(c, router.ID, routers.UpdateOpts{AdminStateUp: &adminStateUp}).Extract(); err != nil {
+			return fmt.Errorf("disabling router %s: %w", router.ID, err)
+		}
+		return nil
 
 	default:
 		return fmt.Errorf("neutron/router: action %q not implemented", rule.Action)
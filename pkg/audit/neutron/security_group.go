@@ -9,24 +9,24 @@ import (
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit/common"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
 	"github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 )
 
 type secGroupAdapter struct{ sg groups.SecGroup }
 
-func (a secGroupAdapter) GetID() string        { return a.sg.ID }
-func (a secGroupAdapter) GetName() string      { return a.sg.Name }
-func (a secGroupAdapter) GetProjectID() string { return a.sg.TenantID }
-func (a secGroupAdapter) GetStatus() string    { return "ACTIVE" }
+func (a secGroupAdapter) GetID() string           { return a.sg.ID }
+func (a secGroupAdapter) GetName() string         { return a.sg.Name }
+func (a secGroupAdapter) GetProjectID() string    { return a.sg.TenantID }
+func (a secGroupAdapter) GetStatus() string       { return "ACTIVE" }
 func (a secGroupAdapter) GetCreatedAt() time.Time { return a.sg.CreatedAt }
 func (a secGroupAdapter) GetUpdatedAt() time.Time { return a.sg.UpdatedAt }
+func (a secGroupAdapter) GetTags() []string       { return a.sg.Tags }
 
 // SecurityGroupAuditor audits neutron/security_group resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, exempt_tags
+// Allowed actions: log, delete, tag, untag
 type SecurityGroupAuditor struct{}
 
 func (a *SecurityGroupAuditor) ResourceType() string {
@@ -34,7 +34,7 @@ func (a *SecurityGroupAuditor) ResourceType() string {
 }
 
 func (a *SecurityGroupAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}
 }
 
 func (a *SecurityGroupAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -105,23 +105,8 @@ func (a *SecurityGroupAuditor) Fix(ctx context.Context, client interface{}, reso
 		}
 		return nil
 
-	case "tag":
-		// Neutron security groups support tags via the standard-attr-tag extension
-		tagName := rule.TagName
-		if tagName == "" {
-			tagName = rule.ActionTagName
-		}
-		if tagName == "" {
-			return fmt.Errorf("neutron/security_group: tag action requires tag_name")
-		}
-
-		// Add the tag to the security group
-		// The resource type for security groups in the tagging API is "security-groups"
-		err := attributestags.Add(c, "security-groups", sg.ID, tagName).ExtractErr()
-		if err != nil {
-			return fmt.Errorf("tagging security group %s with %q: %w", sg.ID, tagName, err)
-		}
-		return nil
+	case "tag", "untag":
+		return applyTagAction(c, "security_group", sg.ID, rule)
 
 	default:
 		return fmt.Errorf("neutron/security_group: action %q not implemented", rule.Action)
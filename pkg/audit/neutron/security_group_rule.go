@@ -3,6 +3,7 @@ package neutron
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
@@ -13,7 +14,12 @@ import (
 // SecurityGroupRuleAuditor audits neutron/security_group_rule resources.
 //
 // Allowed checks: direction, ethertype, protocol, port, remote_ip_prefix, exempt_names
-// Allowed actions: log, delete
+// Allowed actions: log, delete, restrict
+//
+// The restrict action replaces the matched rule with copies scoped to the
+// CIDR allowlist from rule.Restrict. New rules are created before the
+// original is deleted; if any creation or the deletion fails, the rules
+// created so far are rolled back and the original is left in place.
 type SecurityGroupRuleAuditor struct{}
 
 func (a *SecurityGroupRuleAuditor) ResourceType() string {
@@ -138,11 +144,78 @@ func (a *SecurityGroupRuleAuditor) Fix(ctx context.Context, client interface{},
 		}
 		return nil
 
+	case "restrict":
+		return restrictRule(c, sgRule, rule.Restrict.CIDRsFor(sgRule.TenantID))
+
 	default:
 		return fmt.Errorf("neutron/security_group_rule: action %q not implemented", rule.Action)
 	}
 }
 
+// restrictRule replaces sgRule with one rule per allowed CIDR of the same
+// address family, keeping direction, protocol and port range.
+func restrictRule(c *gophercloud.ServiceClient, sgRule rules.SecGroupRule, allowed []string) error {
+	var cidrs []string
+	for _, cidr := range allowed {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("neutron/security_group_rule: invalid CIDR %q: %w", cidr, err)
+		}
+		if cidr == sgRule.RemoteIPPrefix {
+			// The rule is already scoped to an allowed CIDR.
+			return nil
+		}
+		isV4 := ip.To4() != nil
+		if (sgRule.EtherType == string(rules.EtherType4)) == isV4 {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	if len(cidrs) == 0 {
+		return fmt.Errorf("neutron/security_group_rule: no %s CIDRs in restrict allowlist for rule %s; refusing to remove access", sgRule.EtherType, sgRule.ID)
+	}
+
+	var created []string
+	rollback := func() {
+		for _, id := range created {
+			_ = rules.Delete(c, id).ExtractErr()
+		}
+	}
+
+	for _, cidr := range cidrs {
+		opts := rules.CreateOpts{
+			Direction:      rules.RuleDirection(sgRule.Direction),
+			Description:    fmt.Sprintf("OSPA restricted from %s (rule %s)", sgRule.RemoteIPPrefix, sgRule.ID),
+			EtherType:      rules.RuleEtherType(sgRule.EtherType),
+			SecGroupID:     sgRule.SecGroupID,
+			PortRangeMin:   sgRule.PortRangeMin,
+			PortRangeMax:   sgRule.PortRangeMax,
+			Protocol:       rules.RuleProtocol(sgRule.Protocol),
+			RemoteIPPrefix: cidr,
+		}
+		newRule, err := rules.Create(c, opts).Extract()
+		if err != nil {
+			// An identical rule already exists; the allowlist entry is covered.
+			if _, conflict := err.(gophercloud.ErrDefault409); conflict {
+				continue
+			}
+			rollback()
+			return fmt.Errorf("creating restricted rule for %s from %s: %w", sgRule.ID, cidr, err)
+		}
+		created = append(created, newRule.ID)
+	}
+
+	if err := rules.Delete(c, sgRule.ID).ExtractErr(); err != nil {
+		// The original is already gone; the narrowed rules replace it.
+		if _, notFound := err.(gophercloud.ErrDefault404); notFound {
+			return nil
+		}
+		// Keep the group as it was rather than leave both versions open.
+		rollback()
+		return fmt.Errorf("deleting security group rule %s after restricting: %w", sgRule.ID, err)
+	}
+	return nil
+}
+
 // buildRuleName creates a descriptive name for a security group rule
 func buildRuleName(r rules.SecGroupRule) string {
 	proto := r.Protocol
@@ -2,9 +2,15 @@ package neutron
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
 )
 
@@ -185,8 +191,175 @@ func TestSecurityGroupRuleAuditor_Check_PartialMatch(t *testing.T) {
 	}
 }
 
-func TestSecurityGroupRuleAuditor_Fix(t *testing.T) {
-	t.Skip("Fix() requires a mock gophercloud client")
+// fakeRuleAPI serves the security-group-rules endpoints used by restrict.
+// Creating a rule for failCIDR and deleting failDelete return a 500.
+type fakeRuleAPI struct {
+	calls      []string
+	failCIDR   string
+	failDelete string
+	nextID     int
+}
+
+func (f *fakeRuleAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Rule rules.CreateOpts `json:"security_group_rule"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.calls = append(f.calls, "POST "+body.Rule.RemoteIPPrefix)
+		if body.Rule.RemoteIPPrefix == f.failCIDR {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.nextID++
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"security_group_rule":{"id":"new-%d"}}`, f.nextID)
+	case http.MethodDelete:
+		id := strings.TrimPrefix(r.URL.Path, "/security-group-rules/")
+		f.calls = append(f.calls, "DELETE "+id)
+		if id == f.failDelete {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFakeRuleClient(t *testing.T, api *fakeRuleAPI) *gophercloud.ServiceClient {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
+	}
+}
+
+func sshOpenToWorld() rules.SecGroupRule {
+	return rules.SecGroupRule{
+		ID:             "old",
+		Direction:      "ingress",
+		EtherType:      "IPv4",
+		SecGroupID:     "sg-1",
+		PortRangeMin:   22,
+		PortRangeMax:   22,
+		Protocol:       "tcp",
+		RemoteIPPrefix: "0.0.0.0/0",
+		TenantID:       "proj-1",
+	}
+}
+
+func TestSecurityGroupRuleAuditor_Fix_Restrict(t *testing.T) {
+	api := &fakeRuleAPI{}
+	c := newFakeRuleClient(t, api)
+
+	rule := &policy.Rule{
+		Name:   "restrict-ssh",
+		Action: "restrict",
+		Restrict: &policy.RestrictOptions{
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.0.0/16", "fd00::/8"},
+		},
+	}
+
+	if err := (&SecurityGroupRuleAuditor{}).Fix(context.Background(), c, sshOpenToWorld(), rule); err != nil {
+		t.Fatalf("Fix(restrict) error = %v", err)
+	}
+
+	want := "POST 10.0.0.0/8,POST 192.168.0.0/16,DELETE old"
+	if got := strings.Join(api.calls, ","); got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestSecurityGroupRuleAuditor_Fix_RestrictProjectOverride(t *testing.T) {
+	api := &fakeRuleAPI{}
+	c := newFakeRuleClient(t, api)
+
+	rule := &policy.Rule{
+		Name:   "restrict-ssh",
+		Action: "restrict",
+		Restrict: &policy.RestrictOptions{
+			AllowedCIDRs: []string{"10.0.0.0/8"},
+			ProjectCIDRs: map[string][]string{"proj-1": {"172.16.5.0/24"}},
+		},
+	}
+
+	if err := (&SecurityGroupRuleAuditor{}).Fix(context.Background(), c, sshOpenToWorld(), rule); err != nil {
+		t.Fatalf("Fix(restrict) error = %v", err)
+	}
+
+	want := "POST 172.16.5.0/24,DELETE old"
+	if got := strings.Join(api.calls, ","); got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestSecurityGroupRuleAuditor_Fix_RestrictRollsBackOnFailure(t *testing.T) {
+	api := &fakeRuleAPI{failCIDR: "192.168.0.0/16"}
+	c := newFakeRuleClient(t, api)
+
+	rule := &policy.Rule{
+		Name:   "restrict-ssh",
+		Action: "restrict",
+		Restrict: &policy.RestrictOptions{
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+	}
+
+	if err := (&SecurityGroupRuleAuditor{}).Fix(context.Background(), c, sshOpenToWorld(), rule); err == nil {
+		t.Fatal("Fix(restrict) expected error when rule creation fails")
+	}
+
+	// The created rule is removed again and the original is never deleted.
+	want := "POST 10.0.0.0/8,POST 192.168.0.0/16,DELETE new-1"
+	if got := strings.Join(api.calls, ","); got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestSecurityGroupRuleAuditor_Fix_RestrictRollsBackWhenDeleteFails(t *testing.T) {
+	api := &fakeRuleAPI{failDelete: "old"}
+	c := newFakeRuleClient(t, api)
+
+	rule := &policy.Rule{
+		Name:   "restrict-ssh",
+		Action: "restrict",
+		Restrict: &policy.RestrictOptions{
+			AllowedCIDRs: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+	}
+
+	if err := (&SecurityGroupRuleAuditor{}).Fix(context.Background(), c, sshOpenToWorld(), rule); err == nil {
+		t.Fatal("Fix(restrict) expected error when the original rule cannot be deleted")
+	}
+
+	// The narrowed rules are removed so only the original remains.
+	want := "POST 10.0.0.0/8,POST 192.168.0.0/16,DELETE old,DELETE new-1,DELETE new-2"
+	if got := strings.Join(api.calls, ","); got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestSecurityGroupRuleAuditor_Fix_RestrictNoMatchingFamily(t *testing.T) {
+	api := &fakeRuleAPI{}
+	c := newFakeRuleClient(t, api)
+
+	rule := &policy.Rule{
+		Name:     "restrict-ssh",
+		Action:   "restrict",
+		Restrict: &policy.RestrictOptions{AllowedCIDRs: []string{"fd00::/8"}},
+	}
+
+	if err := (&SecurityGroupRuleAuditor{}).Fix(context.Background(), c, sshOpenToWorld(), rule); err == nil {
+		t.Fatal("Fix(restrict) expected error when no CIDR matches the rule's ethertype")
+	}
+	if len(api.calls) != 0 {
+		t.Fatalf("expected no API calls, got %v", api.calls)
+	}
 }
 
 func TestBuildRuleName(t *testing.T) {
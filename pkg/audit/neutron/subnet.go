@@ -21,11 +21,12 @@ func (a subnetAdapter) GetProjectID() string    { return a.s.TenantID }
 func (a subnetAdapter) GetStatus() string       { return "" }
 func (a subnetAdapter) GetCreatedAt() time.Time { return time.Time{} }
 func (a subnetAdapter) GetUpdatedAt() time.Time { return time.Time{} }
+func (a subnetAdapter) GetTags() []string       { return a.s.Tags }
 
 // SubnetAuditor audits neutron/subnet resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, exempt_tags
+// Allowed actions: log, delete, tag, untag
 //
 // Note: subnets in Neutron have no Status or timestamp fields. The status
 // and age_gt checks are accepted for policy consistency but are no-ops.
@@ -38,7 +39,7 @@ func (a *SubnetAuditor) ResourceType() string {
 }
 
 func (a *SubnetAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}
 }
 
 func (a *SubnetAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
@@ -108,8 +109,8 @@ func (a *SubnetAuditor) Fix(ctx context.Context, client interface{}, resource in
 		}
 		return nil
 
-	case "tag":
-		return fmt.Errorf("neutron/subnet: tag action not yet implemented")
+	case "tag", "untag":
+		return applyTagAction(c, "subnet", subnet.ID, rule)
 
 	default:
 		return fmt.Errorf("neutron/subnet: action %q not implemented", rule.Action)
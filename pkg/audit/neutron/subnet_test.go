@@ -182,10 +182,10 @@ func TestSubnetAuditor_Fix_UnsupportedAction(t *testing.T) {
 	auditor := &SubnetAuditor{}
 
 	subnet := subnets.Subnet{ID: "sub-123"}
-	rule := &policy.Rule{Action: "tag"}
+	rule := &policy.Rule{Action: "reboot"}
 
 	err := auditor.Fix(context.Background(), nil, subnet, rule)
 	if err == nil {
-		t.Error("Fix(tag) expected error for unimplemented action")
+		t.Error("Fix(reboot) expected error for unsupported action")
 	}
 }
@@ -0,0 +1,57 @@
+package neutron
+
+import (
+	"fmt"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+)
+
+// Collection names used by the Neutron tagging API (standard-attr-tag
+// extension) for each resource type OSPA audits. Security group rules are
+// not taggable: the extension does not cover them.
+var tagCollections = map[string]string{
+	"network":        "networks",
+	"subnet":         "subnets",
+	"port":           "ports",
+	"router":         "routers",
+	"floating_ip":    "floatingips",
+	"security_group": "security-groups",
+}
+
+// applyTagAction handles the "tag" and "untag" actions for any Neutron
+// standard-attr resource. Untagging a resource that does not carry the tag
+// is treated as success so repeated runs stay idempotent.
+func applyTagAction(c *gophercloud.ServiceClient, resourceType, resourceID string, rule *policy.Rule) error {
+	collection, ok := tagCollections[resourceType]
+	if !ok {
+		return fmt.Errorf("neutron/%s: tagging not supported", resourceType)
+	}
+
+	tagName := rule.EffectiveTagName()
+	if tagName == "" {
+		return fmt.Errorf("neutron/%s: %s action requires tag_name", resourceType, rule.Action)
+	}
+
+	switch rule.Action {
+	case "tag":
+		if err := attributestags.Add(c, collection, resourceID, tagName).ExtractErr(); err != nil {
+			return fmt.Errorf("tagging %s %s with %q: %w", resourceType, resourceID, tagName, err)
+		}
+		return nil
+
+	case "untag":
+		err := attributestags.Delete(c, collection, resourceID, tagName).ExtractErr()
+		if err != nil {
+			if _, notFound := err.(gophercloud.ErrDefault404); notFound {
+				return nil
+			}
+			return fmt.Errorf("removing tag %q from %s %s: %w", tagName, resourceType, resourceID, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("neutron/%s: action %q is not a tag action", resourceType, rule.Action)
+	}
+}
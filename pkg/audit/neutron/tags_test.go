@@ -0,0 +1,158 @@
+package neutron
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+)
+
+// newTestNetworkClient returns a service client pointed at an httptest
+// server that records the method and path of each request.
+func newTestNetworkClient(t *testing.T, status int, calls *[]string) *gophercloud.ServiceClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
+	}
+}
+
+// newTestUpdateClient returns a service client pointed at an httptest server
+// that answers every request with 200 and respBody, recording "METHOD path"
+// in calls and the last request body in reqBody.
+func newTestUpdateClient(t *testing.T, respBody string, calls *[]string, reqBody *string) *gophercloud.ServiceClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		*reqBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, respBody)
+	}))
+	t.Cleanup(srv.Close)
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
+	}
+}
+
+func TestApplyTagAction_Tag(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusCreated, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "tag", TagName: "ospa-flagged"}
+	if err := applyTagAction(c, "network", "net-1", rule); err != nil {
+		t.Fatalf("applyTagAction(tag) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "PUT /networks/net-1/tags/ospa-flagged" {
+		t.Fatalf("calls = %v, want [PUT /networks/net-1/tags/ospa-flagged]", calls)
+	}
+}
+
+func TestApplyTagAction_ActionTagNameAlias(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusCreated, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "tag", ActionTagName: "review"}
+	if err := applyTagAction(c, "floating_ip", "fip-1", rule); err != nil {
+		t.Fatalf("applyTagAction(tag) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "PUT /floatingips/fip-1/tags/review" {
+		t.Fatalf("calls = %v, want [PUT /floatingips/fip-1/tags/review]", calls)
+	}
+}
+
+func TestApplyTagAction_Untag(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusNoContent, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "untag", TagName: "ospa-flagged"}
+	if err := applyTagAction(c, "security_group", "sg-1", rule); err != nil {
+		t.Fatalf("applyTagAction(untag) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "DELETE /security-groups/sg-1/tags/ospa-flagged" {
+		t.Fatalf("calls = %v, want [DELETE /security-groups/sg-1/tags/ospa-flagged]", calls)
+	}
+}
+
+func TestApplyTagAction_UntagMissingTagIsNoop(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusNotFound, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "untag", TagName: "ospa-flagged"}
+	if err := applyTagAction(c, "port", "port-1", rule); err != nil {
+		t.Fatalf("applyTagAction(untag) on missing tag error = %v, want nil", err)
+	}
+}
+
+func TestApplyTagAction_RequiresTagName(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusCreated, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "tag"}
+	if err := applyTagAction(c, "router", "router-1", rule); err == nil {
+		t.Fatal("applyTagAction(tag) without tag_name expected error")
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls, got %v", calls)
+	}
+}
+
+func TestApplyTagAction_SecurityGroupRuleNotTaggable(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusCreated, &calls)
+
+	rule := &policy.Rule{Name: "r1", Action: "tag", TagName: "ospa-flagged"}
+	if err := applyTagAction(c, "security_group_rule", "sgr-1", rule); err == nil {
+		t.Fatal("applyTagAction(security_group_rule) expected error")
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls, got %v", calls)
+	}
+}
+
+func TestNetworkAuditor_Fix_Tag(t *testing.T) {
+	var calls []string
+	c := newTestNetworkClient(t, http.StatusCreated, &calls)
+
+	auditor := &NetworkAuditor{}
+	network := networks.Network{ID: "net-1"}
+	rule := &policy.Rule{Name: "r1", Action: "tag", TagName: "ospa-flagged"}
+
+	if err := auditor.Fix(context.Background(), c, network, rule); err != nil {
+		t.Fatalf("Fix(tag) error = %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected one API call, got %v", calls)
+	}
+}
+
+func TestNetworkAuditor_Check_ExemptTags(t *testing.T) {
+	auditor := &NetworkAuditor{}
+	network := networks.Network{ID: "net-1", Name: "n", Status: "DOWN", Tags: []string{"ospa-flagged"}}
+	rule := &policy.Rule{
+		Name:     "r1",
+		Resource: "network",
+		Check:    policy.CheckConditions{Status: "DOWN", ExemptTags: []string{"ospa-flagged"}},
+		Action:   "tag",
+		TagName:  "ospa-flagged",
+	}
+
+	result, err := auditor.Check(context.Background(), network, rule)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !result.Compliant {
+		t.Error("expected already-tagged network to be exempt")
+	}
+}
@@ -3,21 +3,40 @@ package nova
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit/common"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
-	// TODO: Import the gophercloud resource type for instance.
-	// Example: "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/shelveunshelve"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/startstop"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 )
 
+type instanceAdapter struct{ s servers.Server }
+
+func (a instanceAdapter) GetID() string           { return a.s.ID }
+func (a instanceAdapter) GetName() string         { return a.s.Name }
+func (a instanceAdapter) GetProjectID() string    { return a.s.TenantID }
+func (a instanceAdapter) GetStatus() string       { return a.s.Status }
+func (a instanceAdapter) GetCreatedAt() time.Time { return a.s.Created }
+func (a instanceAdapter) GetUpdatedAt() time.Time { return a.s.Updated }
+
+// idleStatuses are the server states in which an instance does no work.
+var idleStatuses = map[string]bool{
+	"SHUTOFF":           true,
+	"SUSPENDED":         true,
+	"SHELVED":           true,
+	"SHELVED_OFFLOADED": true,
+}
+
 // InstanceAuditor audits nova/instance resources.
 //
-// Allowed checks: status, age_gt, unused, exempt_names
-// Allowed actions: log, delete, tag
+// Allowed checks: status, age_gt, unused, exempt_names, no_keypair
+// Allowed actions: log, delete, stop, shelve
 //
-// TODO: Cast 'resource' to the correct gophercloud type and implement checks.
-// Gophercloud docs: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
-// OpenStack API: https://docs.openstack.org/api-ref/nova
+// An instance is unused when it is shut off, suspended or shelved.
 type InstanceAuditor struct{}
 
 func (a *InstanceAuditor) ResourceType() string {
@@ -25,59 +44,86 @@ func (a *InstanceAuditor) ResourceType() string {
 }
 
 func (a *InstanceAuditor) ImplementedChecks() []string {
-	return []string{"status", "age_gt", "unused", "exempt_names"}
+	return []string{"status", "age_gt", "unused", "exempt_names", "no_keypair"}
 }
 
 func (a *InstanceAuditor) Check(ctx context.Context, resource interface{}, rule *policy.Rule) (*audit.Result, error) {
 	_ = ctx
 
-	// TODO: Cast resource to the correct type.
-	// Example: r := resource.(servers.Server)
-	//
-	// Then populate the result:
-	//   result.ResourceID = r.ID
-	//   result.ResourceName = r.Name
-	//   result.ProjectID = r.TenantID
-	//   result.Status = r.Status
-	//   result.UpdatedAt = r.Updated
-	//
-	// Implement checks based on rule.Check fields:
-	//   - Status: compare r.Status with rule.Check.Status
-	//   - AgeGT: compare time.Since(r.Updated) with rule.Check.AgeGT
-	//   - Unused: implement resource-specific unused detection
-	//   - ExemptNames: skip if r.Name matches any exempt pattern
-
-	result := &audit.Result{
-		RuleID:       rule.Name,
-		ResourceID:   "unknown",
-		ResourceName: "unknown",
-		ProjectID:    "",
-		Compliant:    true,
-		Rule:         rule,
-		Status:       "",
+	server, ok := resource.(servers.Server)
+	if !ok {
+		return nil, fmt.Errorf("expected servers.Server, got %T", resource)
+	}
+
+	adapter := instanceAdapter{s: server}
+	result := common.BuildBaseResult(adapter, rule)
+
+	exempt, err := common.RunCommonChecks(adapter, rule, result)
+	if exempt || err != nil {
+		return result, err
+	}
+
+	if rule.Check.Unused {
+		if idleStatuses[server.Status] {
+			result.Compliant = false
+			result.Observation = fmt.Sprintf("instance is %s", server.Status)
+		}
+	}
+
+	if rule.Check.NoKeypair {
+		if server.KeyName == "" {
+			result.Compliant = false
+			result.Observation = "instance has no SSH keypair"
+		}
 	}
 
-	_ = resource
 	return result, nil
 }
 
 func (a *InstanceAuditor) Fix(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
 	_ = ctx
-	_ = client
-	_ = resource
-
-	// TODO: Implement remediation actions.
-	// Cast client to *gophercloud.ServiceClient.
-	// Allowed actions: log, delete, tag
-	//
-	// Example for delete:
-	//   c := client.(*gophercloud.ServiceClient)
-	//   r := resource.(servers.Server)
-	//   return servers.Delete(c, r.ID).ExtractErr()
+
+	// Log action doesn't require client or resource validation
+	if rule.Action == "log" {
+		return nil
+	}
+
+	c, ok := client.(*gophercloud.ServiceClient)
+	if !ok {
+		return fmt.Errorf("expected *gophercloud.ServiceClient, got %T", client)
+	}
+
+	server, ok := resource.(servers.Server)
+	if !ok {
+		return fmt.Errorf("expected servers.Server, got %T", resource)
+	}
 
 	switch rule.Action {
-	case "log":
+
+	case "delete":
+		if err := servers.Delete(c, server.ID).ExtractErr(); err != nil {
+			return fmt.Errorf("deleting server %s: %w", server.ID, err)
+		}
+		return nil
+
+	case "stop":
+		if server.Status == "SHUTOFF" {
+			return nil
+		}
+		if err := startstop.Stop(c, server.ID).ExtractErr(); err != nil {
+			return fmt.Errorf("stopping server %s: %w", server.ID, err)
+		}
 		return nil
+
+	case "shelve":
+		if server.Status == "SHELVED" || server.Status == "SHELVED_OFFLOADED" {
+			return nil
+		}
+		if err := shelveunshelve.Shelve(c, server.ID).ExtractErr(); err != nil {
+			return fmt.Errorf("shelving server %s: %w", server.ID, err)
+		}
+		return nil
+
 	default:
 		return fmt.Errorf("%s/%s: action %q not implemented", "nova", "instance", rule.Action)
 	}
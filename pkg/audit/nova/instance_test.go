@@ -2,9 +2,16 @@ package nova
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 )
 
 func TestInstanceAuditor_ResourceType(t *testing.T) {
@@ -15,31 +22,164 @@ func TestInstanceAuditor_ResourceType(t *testing.T) {
 }
 
 func TestInstanceAuditor_Check(t *testing.T) {
-	auditor := &InstanceAuditor{}
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	tests := []struct {
+		name          string
+		server        servers.Server
+		check         policy.CheckConditions
+		wantCompliant bool
+	}{
+		{
+			name:          "status matches",
+			server:        servers.Server{ID: "srv-1", Status: "ERROR"},
+			check:         policy.CheckConditions{Status: "ERROR"},
+			wantCompliant: false,
+		},
+		{
+			name:          "status differs",
+			server:        servers.Server{ID: "srv-1", Status: "ACTIVE"},
+			check:         policy.CheckConditions{Status: "ERROR"},
+			wantCompliant: true,
+		},
+		{
+			name:          "older than age_gt",
+			server:        servers.Server{ID: "srv-1", Status: "ACTIVE", Created: old, Updated: old},
+			check:         policy.CheckConditions{AgeGT: "30d"},
+			wantCompliant: false,
+		},
+		{
+			name:          "shut off is unused",
+			server:        servers.Server{ID: "srv-1", Status: "SHUTOFF"},
+			check:         policy.CheckConditions{Unused: true},
+			wantCompliant: false,
+		},
+		{
+			name:          "active is used",
+			server:        servers.Server{ID: "srv-1", Status: "ACTIVE"},
+			check:         policy.CheckConditions{Unused: true},
+			wantCompliant: true,
+		},
+		{
+			name:          "no keypair",
+			server:        servers.Server{ID: "srv-1", Status: "ACTIVE"},
+			check:         policy.CheckConditions{NoKeypair: true},
+			wantCompliant: false,
+		},
+		{
+			name:          "has keypair",
+			server:        servers.Server{ID: "srv-1", Status: "ACTIVE", KeyName: "ops"},
+			check:         policy.CheckConditions{NoKeypair: true},
+			wantCompliant: true,
+		},
+		{
+			name:          "exempt by name",
+			server:        servers.Server{ID: "srv-1", Name: "keep-me", Status: "SHUTOFF"},
+			check:         policy.CheckConditions{Unused: true, ExemptNames: []string{"keep-*"}},
+			wantCompliant: true,
+		},
+	}
 
-	// TODO: Replace with the real gophercloud type once the auditor is implemented.
-	resource := map[string]interface{}{"id": "test-id", "name": "test-resource"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.TenantID = "proj-1"
+			rule := &policy.Rule{Name: "test-rule", Service: "nova", Resource: "instance", Check: tt.check, Action: "log"}
 
-	rule := &policy.Rule{
-		Name:     "test-rule",
-		Service:  "nova",
-		Resource: "instance",
-		Check:    policy.CheckConditions{Status: "active"},
-		Action:   "log",
+			result, err := (&InstanceAuditor{}).Check(context.Background(), tt.server, rule)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if result.Compliant != tt.wantCompliant {
+				t.Errorf("Compliant = %v, want %v (observation %q)", result.Compliant, tt.wantCompliant, result.Observation)
+			}
+			if result.ResourceID != "srv-1" || result.ProjectID != "proj-1" || result.RuleID != rule.Name {
+				t.Errorf("result = %+v, want resource srv-1 in proj-1", result)
+			}
+		})
 	}
+}
 
-	result, err := auditor.Check(context.Background(), resource, rule)
-	if err != nil {
-		t.Fatalf("Check() error = %v", err)
+func TestInstanceAuditor_Check_WrongType(t *testing.T) {
+	rule := &policy.Rule{Name: "test-rule", Action: "log"}
+	if _, err := (&InstanceAuditor{}).Check(context.Background(), map[string]interface{}{"id": "x"}, rule); err == nil {
+		t.Fatal("Check(map) expected error")
 	}
-	if result == nil {
-		t.Fatal("Check() returned nil result")
-	}
-	if result.RuleID != rule.Name {
-		t.Errorf("Result.RuleID = %q, want %q", result.RuleID, rule.Name)
+}
+
+func newTestComputeClient(t *testing.T, calls *[]string, bodies *[]string) *gophercloud.ServiceClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		*bodies = append(*bodies, string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{},
+		Endpoint:       srv.URL + "/",
 	}
 }
 
 func TestInstanceAuditor_Fix(t *testing.T) {
-	t.Skip("Fix() requires a mock gophercloud client")
+	tests := []struct {
+		name      string
+		action    string
+		status    string
+		wantBody  string
+		wantCalls int
+	}{
+		{name: "stop", action: "stop", status: "ACTIVE", wantBody: `"os-stop"`, wantCalls: 1},
+		{name: "stop already shutoff", action: "stop", status: "SHUTOFF"},
+		{name: "shelve", action: "shelve", status: "SHUTOFF", wantBody: `"shelve"`, wantCalls: 1},
+		{name: "shelve already offloaded", action: "shelve", status: "SHELVED_OFFLOADED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls, bodies []string
+			c := newTestComputeClient(t, &calls, &bodies)
+
+			server := servers.Server{ID: "srv-1", Status: tt.status}
+			rule := &policy.Rule{Name: "r1", Action: tt.action}
+
+			if err := (&InstanceAuditor{}).Fix(context.Background(), c, server, rule); err != nil {
+				t.Fatalf("Fix(%s) error = %v", tt.action, err)
+			}
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("calls = %v, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantCalls == 0 {
+				return
+			}
+			if calls[0] != "POST /servers/srv-1/action" {
+				t.Errorf("call = %q, want POST /servers/srv-1/action", calls[0])
+			}
+			if !strings.Contains(bodies[0], tt.wantBody) {
+				t.Errorf("body = %s, want %s", bodies[0], tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestInstanceAuditor_Fix_Delete(t *testing.T) {
+	var calls, bodies []string
+	c := newTestComputeClient(t, &calls, &bodies)
+
+	rule := &policy.Rule{Name: "r1", Action: "delete"}
+	if err := (&InstanceAuditor{}).Fix(context.Background(), c, servers.Server{ID: "srv-1", Status: "SHUTOFF"}, rule); err != nil {
+		t.Fatalf("Fix(delete) error = %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "DELETE /servers/srv-1" {
+		t.Errorf("calls = %v, want DELETE /servers/srv-1", calls)
+	}
+}
+
+func TestInstanceAuditor_Fix_UnsupportedAction(t *testing.T) {
+	var calls, bodies []string
+	c := newTestComputeClient(t, &calls, &bodies)
+
+	rule := &policy.Rule{Name: "r1", Action: "reboot"}
+	if err := (&InstanceAuditor{}).Fix(context.Background(), c, servers.Server{ID: "srv-1"}, rule); err == nil {
+		t.Fatal("Fix(reboot) expected error")
+	}
 }
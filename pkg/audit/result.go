@@ -31,9 +31,22 @@ type Result struct {
 	RemediationSkipped    bool
 	RemediationSkipReason string
 
+	// RemediationDetails records what a remediation changed (e.g. the
+	// security groups a port had before quarantine), keyed by a short name.
+	RemediationDetails map[string]string
+
 	// Additional metadata
 	UpdatedAt time.Time
 	Status    string
+
+	// Cloud and Region locate the resource; they are part of its
+	// fingerprint (see report.Fingerprint).
+	Cloud  string
+	Region string
+
+	// BaselineStatus is "new" or "existing" when the run is compared with a
+	// baseline, and "resolved" for baseline findings that no longer occur.
+	BaselineStatus string
 }
 
 // ErrorKind captures the source of an error to help reporting and metrics.
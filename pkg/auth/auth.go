@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/utils/openstack/clientconfig"
@@ -31,9 +32,23 @@ func NewSession(cloudName string) (*Session, error) {
 	return &Session{
 		Provider:  provider,
 		CloudName: cloudName,
+		Region:    regionName(opts),
 	}, nil
 }
 
+// regionName returns the region configured for the cloud, from OS_REGION_NAME
+// or clouds.yaml. It is empty when no region is configured.
+func regionName(opts *clientconfig.ClientOpts) string {
+	if region := os.Getenv("OS_REGION_NAME"); region != "" {
+		return region
+	}
+	cloud, err := clientconfig.GetCloudFromYAML(opts)
+	if err != nil || cloud == nil {
+		return ""
+	}
+	return cloud.RegionName
+}
+
 // GetComputeClient returns a client for Nova (Compute)
 func (s *Session) GetComputeClient() (*gophercloud.ServiceClient, error) {
 	// clientconfig handles finding the right endpoint (public/internal) and region automatically
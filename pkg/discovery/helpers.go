@@ -69,13 +69,22 @@ func DiscoverPaged(
 		})
 
 		if err != nil && err != context.Canceled {
-			slog.Error("discovery error", "service", serviceName, "resource", resourceType, "error", err)
+			Fail(ctx, jobChan, serviceName, resourceType, err)
 		}
 	}()
 
 	return jobChan, nil
 }
 
+// Fail sends a job reporting that the discovery of serviceName/resourceType
+// failed with err. It gives up if ctx is done first.
+func Fail(ctx context.Context, jobChan chan<- Job, serviceName, resourceType string, err error) {
+	select {
+	case <-ctx.Done():
+	case jobChan <- Job{Service: serviceName, ResourceType: resourceType, Err: err}:
+	}
+}
+
 // SimpleJobCreator creates a helper function for simple job creation where
 // the resource ID and project ID can be extracted using simple functions
 func SimpleJobCreator(
@@ -8,7 +8,9 @@ import (
 
 // Discoverer discovers resources of a specific type
 type Discoverer interface {
-	// Discover discovers resources and sends them to the returned channel
+	// Discover discovers resources and sends them to the returned channel.
+	// A failure after the channel was returned is sent as a Job with Err
+	// set; see Fail.
 	Discover(ctx context.Context, client *gophercloud.ServiceClient, allTenants bool) (<-chan Job, error)
 
 	// ResourceType returns the resource type this discoverer handles
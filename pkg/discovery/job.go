@@ -7,4 +7,8 @@ type Job struct {
 	Resource     interface{} // Service-specific resource struct
 	Service      string
 	ProjectID    string
+
+	// Err is set on a job that carries no resource but reports that the
+	// discovery failed, so the resources sent before it are incomplete.
+	Err error
 }
@@ -5,22 +5,10 @@ import (
 
 	discovery "github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
 	"github.com/gophercloud/gophercloud"
-	// TODO: Import the correct gophercloud package for cinder.
-	// Example for Nova: "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
-	// See: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
 )
 
 // CinderVolumeDiscoverer discovers cinder/volume resources.
-//
-// TODO: Implement Discover() using gophercloud to list volume resources.
-// Gophercloud docs: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
-// OpenStack API: https://docs.openstack.org/api-ref/cinder
-//
-// Discovery hints from registry:
-//
-//	pagination: false
-//	all_tenants: false
-//	regions: false
 type CinderVolumeDiscoverer struct{}
 
 func (d *CinderVolumeDiscoverer) ResourceType() string {
@@ -33,20 +21,46 @@ func (d *CinderVolumeDiscoverer) Discover(ctx context.Context, client *gopherclo
 	go func() {
 		defer close(ch)
 
-		// TODO: List volume resources using gophercloud and send jobs.
-		// Example pattern:
-		//   pages, err := <resource>.List(client, <opts>).AllPages()
-		//   resources, err := <resource>.ExtractResources(pages)
-		//   for _, r := range resources {
-		//       select {
-		//       case <-ctx.Done():
-		//           return
-		//       case ch <- discovery.Job{Service: "cinder", ResourceType: "volume", ResourceID: r.ID, ProjectID: r.TenantID, Resource: r}:
-		//       }
-		//   }
-		_ = ctx
-		_ = client
-		_ = allTenants
+		opts := volumes.ListOpts{AllTenants: allTenants}
+		pages, err := volumes.List(client, opts).AllPages()
+		if err != nil {
+			discovery.Fail(ctx, ch, "cinder", "volume", err)
+			return
+		}
+
+		volumeList, err := volumes.ExtractVolumes(pages)
+		if err != nil {
+			discovery.Fail(ctx, ch, "cinder", "volume", err)
+			return
+		}
+
+		// volumes.Volume has no project; read it from the tenant attribute.
+		var owners []struct {
+			ID       string `json:"id"`
+			TenantID string `json:"os-vol-tenant-attr:tenant_id"`
+		}
+		if err := volumes.ExtractVolumesInto(pages, &owners); err != nil {
+			discovery.Fail(ctx, ch, "cinder", "volume", err)
+			return
+		}
+		projects := make(map[string]string, len(owners))
+		for _, owner := range owners {
+			projects[owner.ID] = owner.TenantID
+		}
+
+		for _, volume := range volumeList {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- discovery.Job{
+				Service:      "cinder",
+				ResourceType: "volume",
+				ResourceID:   volume.ID,
+				ProjectID:    projects[volume.ID],
+				Resource:     volume,
+			}:
+			}
+		}
 	}()
 
 	return ch, nil
@@ -78,6 +92,10 @@ func (d *CinderSnapshotDiscoverer) Discover(ctx context.Context, client *gopherc
 		// TODO: List snapshot resources using gophercloud and send jobs.
 		// Example pattern:
 		//   pages, err := <resource>.List(client, <opts>).AllPages()
+		//   if err != nil {
+		//       discovery.Fail(ctx, ch, "cinder", "snapshot", err)
+		//       return
+		//   }
 		//   resources, err := <resource>.ExtractResources(pages)
 		//   for _, r := range resources {
 		//       select {
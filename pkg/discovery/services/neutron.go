@@ -30,11 +30,13 @@ func (d *NeutronNetworkDiscoverer) Discover(ctx context.Context, client *gopherc
 		opts := networks.ListOpts{}
 		pages, err := networks.List(client, opts).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "network", err)
 			return
 		}
 
 		networkList, err := networks.ExtractNetworks(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "network", err)
 			return
 		}
 
@@ -82,11 +84,13 @@ func (d *NeutronSecurityGroupDiscoverer) Discover(ctx context.Context, client *g
 		opts := groups.ListOpts{}
 		pages, err := groups.List(client, opts).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "security_group", err)
 			return
 		}
 
 		sgList, err := groups.ExtractGroups(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "security_group", err)
 			return
 		}
 
@@ -135,11 +139,13 @@ func (d *NeutronSecurityGroupRuleDiscoverer) Discover(ctx context.Context, clien
 		opts := rules.ListOpts{}
 		pages, err := rules.List(client, opts).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "security_group_rule", err)
 			return
 		}
 
 		ruleList, err := rules.ExtractRules(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "security_group_rule", err)
 			return
 		}
 
@@ -177,11 +183,13 @@ func (d *NeutronFloatingIpDiscoverer) Discover(ctx context.Context, client *goph
 
 		pages, err := floatingips.List(client, floatingips.ListOpts{}).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "floating_ip", err)
 			return
 		}
 
 		fipList, err := floatingips.ExtractFloatingIPs(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "floating_ip", err)
 			return
 		}
 
@@ -220,11 +228,13 @@ func (d *NeutronSubnetDiscoverer) Discover(ctx context.Context, client *gophercl
 		opts := subnets.ListOpts{}
 		pages, err := subnets.List(client, opts).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "subnet", err)
 			return
 		}
 
 		subnetList, err := subnets.ExtractSubnets(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "subnet", err)
 			return
 		}
 
@@ -262,11 +272,13 @@ func (d *NeutronRouterDiscoverer) Discover(ctx context.Context, client *gophercl
 
 		pages, err := routers.List(client, routers.ListOpts{}).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "router", err)
 			return
 		}
 
 		routerList, err := routers.ExtractRouters(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "router", err)
 			return
 		}
 
@@ -304,11 +316,13 @@ func (d *NeutronPortDiscoverer) Discover(ctx context.Context, client *gopherclou
 
 		pages, err := ports.List(client, ports.ListOpts{}).AllPages()
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "port", err)
 			return
 		}
 
 		portList, err := ports.ExtractPorts(pages)
 		if err != nil {
+			discovery.Fail(ctx, ch, "neutron", "port", err)
 			return
 		}
 
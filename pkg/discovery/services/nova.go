@@ -5,22 +5,10 @@ import (
 
 	discovery "github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
 	"github.com/gophercloud/gophercloud"
-	// TODO: Import the correct gophercloud package for nova.
-	// Example for Nova: "github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
-	// See: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 )
 
 // NovaInstanceDiscoverer discovers nova/instance resources.
-//
-// TODO: Implement Discover() using gophercloud to list instance resources.
-// Gophercloud docs: https://pkg.go.dev/github.com/gophercloud/gophercloud/openstack
-// OpenStack API: https://docs.openstack.org/api-ref/nova
-//
-// Discovery hints from registry:
-//
-//	pagination: false
-//	all_tenants: false
-//	regions: false
 type NovaInstanceDiscoverer struct{}
 
 func (d *NovaInstanceDiscoverer) ResourceType() string {
@@ -33,20 +21,32 @@ func (d *NovaInstanceDiscoverer) Discover(ctx context.Context, client *gopherclo
 	go func() {
 		defer close(ch)
 
-		// TODO: List instance resources using gophercloud and send jobs.
-		// Example pattern:
-		//   pages, err := <resource>.List(client, <opts>).AllPages()
-		//   resources, err := <resource>.ExtractResources(pages)
-		//   for _, r := range resources {
-		//       select {
-		//       case <-ctx.Done():
-		//           return
-		//       case ch <- discovery.Job{Service: "nova", ResourceType: "instance", ResourceID: r.ID, ProjectID: r.TenantID, Resource: r}:
-		//       }
-		//   }
-		_ = ctx
-		_ = client
-		_ = allTenants
+		opts := servers.ListOpts{AllTenants: allTenants}
+		pages, err := servers.List(client, opts).AllPages()
+		if err != nil {
+			discovery.Fail(ctx, ch, "nova", "instance", err)
+			return
+		}
+
+		serverList, err := servers.ExtractServers(pages)
+		if err != nil {
+			discovery.Fail(ctx, ch, "nova", "instance", err)
+			return
+		}
+
+		for _, server := range serverList {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- discovery.Job{
+				Service:      "nova",
+				ResourceType: "instance",
+				ResourceID:   server.ID,
+				ProjectID:    server.TenantID,
+				Resource:     server,
+			}:
+			}
+		}
 	}()
 
 	return ch, nil
@@ -78,6 +78,10 @@ func (d *NovaKeypairDiscoverer) Discover(ctx context.Context, client *gopherclou
 		// TODO: List keypair resources using gophercloud and send jobs.
 		// Example pattern:
 		//   pages, err := <resource>.List(client, <opts>).AllPages()
+		//   if err != nil {
+		//       discovery.Fail(ctx, ch, "nova", "keypair", err)
+		//       return
+		//   }
 		//   resources, err := <resource>.ExtractResources(pages)
 		//   for _, r := range resources {
 		//       select {
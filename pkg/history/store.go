@@ -0,0 +1,520 @@
+// Package history keeps a persistent record of scan runs, their per-resource
+// results and the violation incidents derived from them, so that the agent
+// can answer how long a resource has been non-compliant and how quickly each
+// rule's violations get fixed.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/atomicfile"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+)
+
+const (
+	runsFile      = "runs.json"
+	incidentsFile = "incidents.json"
+	resultsDir    = "results"
+)
+
+// Run is one recorded scan.
+type Run struct {
+	ID         string    `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	// Service and ResourceType are set when the scan was limited to them.
+	Service      string `json:"service,omitempty"`
+	ResourceType string `json:"resource_type,omitempty"`
+
+	// Rules are the rules the scan evaluated. A violation of one of these
+	// rules that the scan no longer reports is resolved. Empty means all.
+	Rules []string `json:"rules,omitempty"`
+
+	// Discovered lists the service/resource types, as
+	// "service/resource_type", whose discovery completed. Only violations
+	// of these types can be resolved: a resource missing from a failed or
+	// cancelled discovery was not fixed, just not seen.
+	Discovered []string `json:"discovered,omitempty"`
+
+	Scanned    int `json:"scanned"`
+	Violations int `json:"violations"`
+	Errors     int `json:"errors"`
+}
+
+// covers reports whether the run completely evaluated the rule and
+// resource type of incident.
+func (r Run) covers(inc *Incident) bool {
+	if r.Service != "" && r.Service != inc.Service {
+		return false
+	}
+	if r.ResourceType != "" && r.ResourceType != inc.ResourceType {
+		return false
+	}
+	discovered := false
+	for _, t := range r.Discovered {
+		if t == inc.Service+"/"+inc.ResourceType {
+			discovered = true
+			break
+		}
+	}
+	if !discovered {
+		return false
+	}
+	if len(r.Rules) == 0 {
+		return true
+	}
+	for _, rule := range r.Rules {
+		if rule == inc.RuleID {
+			return true
+		}
+	}
+	return false
+}
+
+// Incident is a period during which a rule was violated by a resource. It
+// opens at the first run reporting the violation and resolves at the first
+// later run that evaluates the rule without reporting it, because the
+// resource was fixed or removed. Times are therefore accurate to the scan
+// interval.
+type Incident struct {
+	Fingerprint  string     `json:"fingerprint"`
+	RuleID       string     `json:"rule_id"`
+	Service      string     `json:"service,omitempty"`
+	ResourceType string     `json:"resource_type,omitempty"`
+	ResourceID   string     `json:"resource_id"`
+	ResourceName string     `json:"resource_name,omitempty"`
+	ProjectID    string     `json:"project_id,omitempty"`
+	Severity     string     `json:"severity,omitempty"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Open reports whether the violation is still present.
+func (i Incident) Open() bool {
+	return i.ResolvedAt == nil
+}
+
+// Entry is one recorded result for a resource.
+type Entry struct {
+	RunID     string    `json:"run_id"`
+	ScannedAt time.Time `json:"scanned_at"`
+	report.Finding
+}
+
+// RuleMTTR is the mean time to remediate the violations of a rule.
+type RuleMTTR struct {
+	RuleID   string        `json:"rule_id"`
+	Resolved int           `json:"resolved"`
+	Open     int           `json:"open"`
+	Mean     time.Duration `json:"-"`
+
+	// MeanSeconds is Mean in seconds, for JSON consumers.
+	MeanSeconds float64 `json:"mean_seconds"`
+}
+
+// Options configures retention. Zero values keep everything.
+type Options struct {
+	// MaxAge drops runs that started longer ago, and incidents resolved
+	// before the oldest remaining run.
+	MaxAge time.Duration
+
+	// MaxRuns keeps at most this many runs.
+	MaxRuns int
+}
+
+// Store is a directory holding runs.json, incidents.json and one JSON Lines
+// results file per run. Only one process should record runs at a time;
+// readers such as the history command may open it concurrently.
+type Store struct {
+	dir  string
+	opts Options
+	now  func() time.Time
+
+	mu        sync.Mutex
+	runs      []Run
+	incidents []*Incident
+	open      map[string]*Incident
+}
+
+// Open loads the store in dir, creating the directory if needed.
+func Open(dir string, opts Options) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, resultsDir), 0o755); err != nil {
+		return nil, fmt.Errorf("creating history store %q: %w", dir, err)
+	}
+	s := &Store{dir: dir, opts: opts, now: time.Now, open: make(map[string]*Incident)}
+	if err := readJSON(filepath.Join(dir, runsFile), &s.runs); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(dir, incidentsFile), &s.incidents); err != nil {
+		return nil, err
+	}
+	for _, inc := range s.incidents {
+		if inc.Open() {
+			s.open[inc.Fingerprint] = inc
+		}
+	}
+	return s, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading history %q: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing history %q: %w", path, err)
+	}
+	return nil
+}
+
+// Dir returns the directory backing the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Runs returns the recorded runs, oldest first.
+func (s *Store) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Run(nil), s.runs...)
+}
+
+// Results returns every result recorded by a run.
+func (s *Store) Results(runID string) ([]report.Finding, error) {
+	s.mu.Lock()
+	found := false
+	for _, r := range s.runs {
+		if r.ID == runID {
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		return nil, fmt.Errorf("run %q not found", runID)
+	}
+	return report.LoadFindings(s.resultsPath(runID))
+}
+
+// ResourceHistory returns every retained result for a resource, oldest
+// first. There is no per-resource index: every retained run's results file
+// is read, so the cost grows with the total history kept. Bound it with
+// Options.MaxRuns or MaxAge.
+func (s *Store) ResourceHistory(resourceID string) ([]Entry, error) {
+	var entries []Entry
+	for _, run := range s.Runs() {
+		findings, err := report.LoadFindings(s.resultsPath(run.ID))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range findings {
+			if f.ResourceID == resourceID {
+				entries = append(entries, Entry{RunID: run.ID, ScannedAt: run.StartedAt, Finding: f})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// Incidents returns the retained incidents, oldest first. With openOnly
+// set, resolved incidents are skipped.
+func (s *Store) Incidents(openOnly bool) []Incident {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Incident
+	for _, inc := range s.incidents {
+		if !openOnly || inc.Open() {
+			out = append(out, *inc)
+		}
+	}
+	return out
+}
+
+// NonCompliantSince returns when the current violation of rule by resource
+// was first seen, or false if the resource currently complies.
+func (s *Store) NonCompliantSince(ruleID, resourceID string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, inc := range s.open {
+		if inc.RuleID == ruleID && inc.ResourceID == resourceID {
+			return inc.OpenedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// MTTR returns the mean time to remediate of each rule over the retained
+// resolved incidents, sorted by rule.
+func (s *Store) MTTR() []RuleMTTR {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byRule := make(map[string]*RuleMTTR)
+	total := make(map[string]time.Duration)
+	for _, inc := range s.incidents {
+		m := byRule[inc.RuleID]
+		if m == nil {
+			m = &RuleMTTR{RuleID: inc.RuleID}
+			byRule[inc.RuleID] = m
+		}
+		if inc.Open() {
+			m.Open++
+			continue
+		}
+		m.Resolved++
+		total[inc.RuleID] += inc.ResolvedAt.Sub(inc.OpenedAt)
+	}
+
+	out := make([]RuleMTTR, 0, len(byRule))
+	for rule, m := range byRule {
+		if m.Resolved > 0 {
+			m.Mean = total[rule] / time.Duration(m.Resolved)
+			m.MeanSeconds = m.Mean.Seconds()
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RuleID < out[j].RuleID })
+	return out
+}
+
+func (s *Store) resultsPath(runID string) string {
+	return filepath.Join(s.dir, resultsDir, runID+".jsonl")
+}
+
+// Begin starts recording a run. The caller sets StartedAt (default now)
+// and the scope fields; the counts are filled in from the results. Close
+// the returned recorder to commit the run.
+func (s *Store) Begin(run Run) (*Recorder, error) {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = s.now()
+	}
+	run.StartedAt = run.StartedAt.UTC()
+	run.ID = s.newRunID(run.StartedAt)
+
+	file, err := os.CreateTemp(filepath.Join(s.dir, resultsDir), ".run-*")
+	if err != nil {
+		return nil, fmt.Errorf("recording run: %w", err)
+	}
+	return &Recorder{
+		store:      s,
+		run:        run,
+		file:       file,
+		buf:        bufio.NewWriter(file),
+		violations: make(map[string]report.Finding),
+		errored:    make(map[string]bool),
+	}, nil
+}
+
+func (s *Store) newRunID(start time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := start.Format("20060102T150405Z")
+	id := base
+	for n := 2; s.hasRunLocked(id); n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id
+}
+
+func (s *Store) hasRunLocked(id string) bool {
+	for _, r := range s.runs {
+		if r.ID == id {
+			return true
+		}
+	}
+	_, err := os.Stat(s.resultsPath(id))
+	return err == nil
+}
+
+// Recorder is a report.ResultWriter that records one run in the store.
+type Recorder struct {
+	store *Store
+	run   Run
+	file  *os.File
+	buf   *bufio.Writer
+	enc   *json.Encoder
+
+	violations map[string]report.Finding
+	errored    map[string]bool
+}
+
+// SetDiscovered records the service/resource types whose discovery
+// completed, see Run.Discovered. Call it before Close; a run that never
+// does, e.g. because it was cancelled, resolves no incidents.
+func (r *Recorder) SetDiscovered(types []string) {
+	r.run.Discovered = append([]string(nil), types...)
+}
+
+// IncludesCompliant is true: compliant results make up the history too.
+func (r *Recorder) IncludesCompliant() bool {
+	return true
+}
+
+// RunID returns the ID of the run being recorded.
+func (r *Recorder) RunID() string {
+	return r.run.ID
+}
+
+// WriteResult records a result. Resolved baseline records are skipped since
+// they do not come from this run.
+func (r *Recorder) WriteResult(res *audit.Result) error {
+	if res.BaselineStatus == report.BaselineResolved {
+		return nil
+	}
+	f := report.NewFinding(res)
+	f.BaselineStatus = ""
+
+	if r.enc == nil {
+		r.enc = json.NewEncoder(r.buf)
+		r.enc.SetEscapeHTML(false)
+	}
+	if err := r.enc.Encode(f); err != nil {
+		return fmt.Errorf("recording result: %w", err)
+	}
+
+	r.run.Scanned++
+	switch {
+	case f.Error != "":
+		r.run.Errors++
+		r.errored[f.Fingerprint] = true
+		if !f.Compliant {
+			r.run.Violations++
+		}
+	case !f.Compliant:
+		r.run.Violations++
+		r.violations[f.Fingerprint] = f
+	}
+	if f.RemediationError != "" {
+		r.run.Errors++
+	}
+	return nil
+}
+
+// Close commits the run: it stores the results, updates the incidents and
+// applies retention.
+func (r *Recorder) Close() error {
+	err := r.buf.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(r.file.Name(), r.store.resultsPath(r.run.ID))
+	}
+	if err != nil {
+		_ = os.Remove(r.file.Name())
+		return fmt.Errorf("recording run %s: %w", r.run.ID, err)
+	}
+	return r.store.commit(r)
+}
+
+func (s *Store) commit(r *Recorder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := r.run
+	run.FinishedAt = s.now().UTC()
+
+	for fp, f := range r.violations {
+		if inc, ok := s.open[fp]; ok {
+			inc.LastSeenAt = run.StartedAt
+			inc.Severity = f.Severity
+			continue
+		}
+		inc := &Incident{
+			Fingerprint:  fp,
+			RuleID:       f.RuleID,
+			Service:      f.Service,
+			ResourceType: f.ResourceType,
+			ResourceID:   f.ResourceID,
+			ResourceName: f.ResourceName,
+			ProjectID:    f.ProjectID,
+			Severity:     f.Severity,
+			OpenedAt:     run.StartedAt,
+			LastSeenAt:   run.StartedAt,
+		}
+		s.incidents = append(s.incidents, inc)
+		s.open[fp] = inc
+	}
+	for fp, inc := range s.open {
+		if _, still := r.violations[fp]; still || r.errored[fp] || !run.covers(inc) {
+			continue
+		}
+		resolved := run.StartedAt
+		inc.ResolvedAt = &resolved
+		delete(s.open, fp)
+	}
+
+	s.runs = append(s.runs, run)
+	sort.SliceStable(s.runs, func(i, j int) bool { return s.runs[i].StartedAt.Before(s.runs[j].StartedAt) })
+	s.applyRetentionLocked()
+
+	return s.saveLocked()
+}
+
+// applyRetentionLocked drops runs outside the retention limits, their
+// results, and incidents resolved before the oldest remaining run.
+func (s *Store) applyRetentionLocked() {
+	keepFrom := 0
+	if s.opts.MaxAge > 0 {
+		cutoff := s.now().Add(-s.opts.MaxAge)
+		for keepFrom < len(s.runs) && s.runs[keepFrom].StartedAt.Before(cutoff) {
+			keepFrom++
+		}
+	}
+	if s.opts.MaxRuns > 0 && len(s.runs)-keepFrom > s.opts.MaxRuns {
+		keepFrom = len(s.runs) - s.opts.MaxRuns
+	}
+	if keepFrom == 0 {
+		return
+	}
+	for _, run := range s.runs[:keepFrom] {
+		_ = os.Remove(s.resultsPath(run.ID))
+	}
+	s.runs = append([]Run(nil), s.runs[keepFrom:]...)
+
+	if len(s.runs) == 0 {
+		return
+	}
+	oldest := s.runs[0].StartedAt
+	kept := s.incidents[:0]
+	for _, inc := range s.incidents {
+		if inc.Open() || !inc.ResolvedAt.Before(oldest) {
+			kept = append(kept, inc)
+		}
+	}
+	s.incidents = kept
+}
+
+func (s *Store) saveLocked() error {
+	if err := writeJSON(filepath.Join(s.dir, runsFile), s.runs); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(s.dir, incidentsFile), s.incidents)
+}
+
+func writeJSON(path string, v any) error {
+	if err := atomicfile.WriteJSON(path, v); err != nil {
+		return fmt.Errorf("writing history %q: %w", path, err)
+	}
+	return nil
+}
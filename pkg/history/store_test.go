@@ -0,0 +1,211 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+var (
+	sgRule  = &policy.Rule{Name: "open-ssh", Service: "neutron", Resource: "security_group"}
+	vmRule  = &policy.Rule{Name: "shutoff", Service: "nova", Resource: "instance"}
+	dayZero = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// full is a run whose discovery of every test resource type completed.
+	full = Run{Discovered: []string{"neutron/security_group", "nova/instance"}}
+)
+
+func newTestStore(t *testing.T, dir string, opts Options) *Store {
+	t.Helper()
+	s, err := Open(dir, opts)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return s
+}
+
+// record stores a run started on the given day with the given results.
+func record(t *testing.T, s *Store, day int, run Run, results ...*audit.Result) string {
+	t.Helper()
+	start := dayZero.Add(time.Duration(day) * 24 * time.Hour)
+	s.now = func() time.Time { return start.Add(time.Minute) }
+	run.StartedAt = start
+	rec, err := s.Begin(run)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, r := range results {
+		if err := rec.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult() error = %v", err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return rec.RunID()
+}
+
+func res(rule *policy.Rule, id string, compliant bool) *audit.Result {
+	return &audit.Result{RuleID: rule.Name, ResourceID: id, Compliant: compliant, Rule: rule}
+}
+
+func TestStore_IncidentLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, dir, Options{})
+
+	record(t, s, 0, full, res(sgRule, "sg-1", false), res(vmRule, "vm-1", false))
+	record(t, s, 1, full, res(sgRule, "sg-1", false), res(vmRule, "vm-1", true))
+
+	since, ok := s.NonCompliantSince("open-ssh", "sg-1")
+	if !ok || !since.Equal(dayZero) {
+		t.Fatalf("NonCompliantSince(sg-1) = %s, %v; want %s", since, ok, dayZero)
+	}
+	if _, ok := s.NonCompliantSince("shutoff", "vm-1"); ok {
+		t.Fatal("vm-1 was fixed on day 1")
+	}
+
+	// An audit error neither opens nor resolves an incident.
+	errored := res(sgRule, "sg-1", false)
+	errored.Error = errors.New("timeout")
+	record(t, s, 2, full, errored)
+	if _, ok := s.NonCompliantSince("open-ssh", "sg-1"); !ok {
+		t.Fatal("an errored audit must not resolve the incident")
+	}
+
+	// A scan of another service does not resolve it either.
+	record(t, s, 3, Run{Service: "nova", Rules: []string{"shutoff"}, Discovered: full.Discovered})
+	if _, ok := s.NonCompliantSince("open-ssh", "sg-1"); !ok {
+		t.Fatal("a scan not covering the rule must not resolve the incident")
+	}
+
+	// The resource disappears from a full scan: resolved after 4 days.
+	record(t, s, 4, Run{Rules: []string{"open-ssh", "shutoff"}, Discovered: full.Discovered})
+	if _, ok := s.NonCompliantSince("open-ssh", "sg-1"); ok {
+		t.Fatal("incident should be resolved")
+	}
+
+	reopened := newTestStore(t, dir, Options{})
+	mttr := reopened.MTTR()
+	if len(mttr) != 2 {
+		t.Fatalf("MTTR() = %+v", mttr)
+	}
+	if mttr[0].RuleID != "open-ssh" || mttr[0].Resolved != 1 || mttr[0].Mean != 4*24*time.Hour {
+		t.Errorf("open-ssh MTTR = %+v", mttr[0])
+	}
+	if mttr[1].RuleID != "shutoff" || mttr[1].Mean != 24*time.Hour || mttr[1].MeanSeconds != 86400 {
+		t.Errorf("shutoff MTTR = %+v", mttr[1])
+	}
+	if got := len(reopened.Incidents(true)); got != 0 {
+		t.Errorf("open incidents = %d, want 0", got)
+	}
+
+	// The violation comes back: a new incident opens.
+	record(t, reopened, 5, full, res(sgRule, "sg-1", false))
+	if since, _ := reopened.NonCompliantSince("open-ssh", "sg-1"); !since.Equal(dayZero.Add(5 * 24 * time.Hour)) {
+		t.Errorf("reopened since = %s", since)
+	}
+	if got := len(reopened.Incidents(false)); got != 3 {
+		t.Errorf("incidents = %d, want 3", got)
+	}
+}
+
+func TestStore_IncompleteDiscoveryResolvesNothing(t *testing.T) {
+	s := newTestStore(t, t.TempDir(), Options{})
+	record(t, s, 0, full, res(sgRule, "sg-1", false), res(vmRule, "vm-1", false))
+
+	// Listing security groups failed: only the nova incident is resolved.
+	record(t, s, 1, Run{Discovered: []string{"nova/instance"}})
+	if _, ok := s.NonCompliantSince("open-ssh", "sg-1"); !ok {
+		t.Fatal("a failed discovery must not resolve the incident")
+	}
+	if _, ok := s.NonCompliantSince("shutoff", "vm-1"); ok {
+		t.Fatal("vm-1 is gone from a completed discovery")
+	}
+
+	// A cancelled run records no completed discovery and resolves nothing.
+	record(t, s, 2, Run{})
+	if since, ok := s.NonCompliantSince("open-ssh", "sg-1"); !ok || !since.Equal(dayZero) {
+		t.Fatalf("NonCompliantSince(sg-1) = %s, %v; want %s", since, ok, dayZero)
+	}
+
+	s.now = func() time.Time { return dayZero.Add(3 * 24 * time.Hour) }
+	rec, err := s.Begin(Run{StartedAt: dayZero.Add(3 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	rec.SetDiscovered(full.Discovered)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := s.NonCompliantSince("open-ssh", "sg-1"); ok {
+		t.Fatal("sg-1 is gone from a completed discovery")
+	}
+	if mttr := s.MTTR(); mttr[0].Mean != 3*24*time.Hour {
+		t.Errorf("open-ssh MTTR = %+v", mttr[0])
+	}
+}
+
+func TestStore_RunsAndHistory(t *testing.T) {
+	s := newTestStore(t, t.TempDir(), Options{})
+	first := record(t, s, 0, full, res(sgRule, "sg-1", false), res(vmRule, "vm-1", true))
+	record(t, s, 1, full, res(sgRule, "sg-1", true))
+
+	runs := s.Runs()
+	if len(runs) != 2 || runs[0].ID != first {
+		t.Fatalf("Runs() = %+v", runs)
+	}
+	if runs[0].Scanned != 2 || runs[0].Violations != 1 || runs[1].Violations != 0 {
+		t.Errorf("run counts = %+v", runs)
+	}
+
+	results, err := s.Results(first)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Results() = %+v, %v", results, err)
+	}
+	if _, err := s.Results("missing"); err == nil {
+		t.Error("expected error for unknown run")
+	}
+
+	history, err := s.ResourceHistory("sg-1")
+	if err != nil {
+		t.Fatalf("ResourceHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Compliant || !history[1].Compliant || history[0].RunID != first {
+		t.Fatalf("ResourceHistory() = %+v", history)
+	}
+}
+
+func TestStore_Retention(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestStore(t, dir, Options{MaxRuns: 2})
+
+	first := record(t, s, 0, full, res(vmRule, "vm-1", false))
+	record(t, s, 1, full, res(vmRule, "vm-1", true))
+	record(t, s, 2, full, res(sgRule, "sg-1", false))
+	record(t, s, 3, full, res(sgRule, "sg-1", false))
+
+	runs := s.Runs()
+	if len(runs) != 2 || runs[0].StartedAt.Day() != 3 {
+		t.Fatalf("Runs() = %+v", runs)
+	}
+	if _, err := os.Stat(s.resultsPath(first)); !os.IsNotExist(err) {
+		t.Errorf("results of pruned run still exist: %v", err)
+	}
+	incidents := s.Incidents(false)
+	if len(incidents) != 1 || incidents[0].RuleID != "open-ssh" {
+		t.Fatalf("incidents after retention = %+v", incidents)
+	}
+
+	aged := newTestStore(t, dir, Options{MaxAge: 36 * time.Hour})
+	record(t, aged, 4, full, res(sgRule, "sg-1", false))
+	if got := len(aged.Runs()); got != 2 {
+		t.Fatalf("runs within 36h = %d, want 2", got)
+	}
+	if since, ok := aged.NonCompliantSince("open-ssh", "sg-1"); !ok || since.Day() != 3 {
+		t.Errorf("open incidents survive retention: %s, %v", since, ok)
+	}
+}
@@ -3,9 +3,9 @@ package metrics
 import (
 	"net/http"
 	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var enabled atomic.Bool
@@ -55,6 +55,64 @@ var (
 		Name: "ospa_auditor_not_found_total",
 		Help: "Total number of auditor lookup failures.",
 	})
+
+	runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ospa_runs_total",
+		Help: "Total number of completed scan runs by outcome.",
+	}, []string{"status"})
+	lastRunTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_timestamp_seconds",
+		Help: "Unix time at which the last scan run started.",
+	})
+	lastRunDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_duration_seconds",
+		Help: "Duration of the last scan run.",
+	})
+	lastRunSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_success",
+		Help: "Whether the last scan run completed without errors (1) or not (0).",
+	})
+	lastRunScanned = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_scanned",
+		Help: "Number of resources scanned by the last scan run.",
+	})
+	lastRunViolations = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_violations",
+		Help: "Number of violations found by the last scan run.",
+	})
+	lastRunErrors = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_last_run_errors",
+		Help: "Number of errors in the last scan run.",
+	})
+	nextRunTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ospa_next_run_timestamp_seconds",
+		Help: "Unix time at which the next scheduled scan run starts.",
+	})
+
+	ruleViolationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ospa_rule_violations_total",
+		Help: "Total number of violations by rule and project.",
+	}, []string{"rule", "service", "resource_type", "severity", "category", "project"})
+	ruleViolations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ospa_rule_violations",
+		Help: "Number of violations of each rule found by the last scan run.",
+	}, []string{"rule", "service", "resource_type", "severity", "category"})
+	remediations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ospa_remediations_total",
+		Help: "Total number of remediation outcomes by action (remediated, failed, skipped).",
+	}, []string{"action", "outcome"})
+	discovered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ospa_discovered_resources_total",
+		Help: "Total number of discovered resources by service and resource type.",
+	}, []string{"service", "resource_type"})
+	lastDiscovered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ospa_last_run_discovered_resources",
+		Help: "Number of resources discovered by the last scan of each service and resource type.",
+	}, []string{"service", "resource_type"})
+	scanDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ospa_last_run_scan_duration_seconds",
+		Help: "Time the last scan of each service and resource type took to discover and queue its resources.",
+	}, []string{"service", "resource_type"})
 )
 
 func init() {
@@ -70,6 +128,20 @@ func init() {
 		serviceNotFound,
 		discovererNotFound,
 		auditorNotFound,
+		runs,
+		lastRunTimestamp,
+		lastRunDuration,
+		lastRunSuccess,
+		lastRunScanned,
+		lastRunViolations,
+		lastRunErrors,
+		nextRunTimestamp,
+		ruleViolationsTotal,
+		ruleViolations,
+		remediations,
+		discovered,
+		lastDiscovered,
+		scanDuration,
 	)
 }
 
@@ -143,10 +215,121 @@ func IncAuditorNotFound() {
 	}
 }
 
-// StartServer starts the Prometheus metrics endpoint.
+// RuleLabels identify the rule and resource of a violation.
+type RuleLabels struct {
+	Rule         string
+	Service      string
+	ResourceType string
+	Severity     string
+	Category     string
+	Project      string
+}
+
+// IncRuleViolation counts a violation of a rule in a project.
+func IncRuleViolation(l RuleLabels) {
+	if enabled.Load() {
+		ruleViolationsTotal.WithLabelValues(l.Rule, l.Service, l.ResourceType, l.Severity, l.Category, l.Project).Inc()
+	}
+}
+
+// SetRuleViolations replaces the per-rule violation gauges with the counts
+// of the last run. Project is ignored. Rules that were evaluated without
+// violations should be passed with a zero count; rules missing from counts
+// disappear from the gauge.
+func SetRuleViolations(counts map[RuleLabels]int) {
+	if !enabled.Load() {
+		return
+	}
+	ruleViolations.Reset()
+	for l, n := range counts {
+		ruleViolations.WithLabelValues(l.Rule, l.Service, l.ResourceType, l.Severity, l.Category).Add(float64(n))
+	}
+}
+
+// Remediation outcomes.
+const (
+	OutcomeRemediated = "remediated"
+	OutcomeFailed     = "failed"
+	OutcomeSkipped    = "skipped"
+)
+
+// IncRemediation counts a remediation outcome for an action.
+func IncRemediation(action, outcome string) {
+	if enabled.Load() {
+		remediations.WithLabelValues(action, outcome).Inc()
+	}
+}
+
+// IncDiscovered counts a discovered resource.
+func IncDiscovered(service, resourceType string) {
+	if enabled.Load() {
+		discovered.WithLabelValues(service, resourceType).Inc()
+	}
+}
+
+// ResetDiscovery clears the per-resource-type gauges at the start of a run,
+// so resource types the run no longer scans do not keep reporting the
+// values of an earlier run.
+func ResetDiscovery() {
+	if !enabled.Load() {
+		return
+	}
+	lastDiscovered.Reset()
+	scanDuration.Reset()
+}
+
+// RecordDiscovery sets the per-resource-type gauges once discovery of a
+// service and resource type has finished.
+func RecordDiscovery(service, resourceType string, resources int, duration time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	lastDiscovered.WithLabelValues(service, resourceType).Set(float64(resources))
+	scanDuration.WithLabelValues(service, resourceType).Set(duration.Seconds())
+}
+
+// RunStats summarizes one completed scan run.
+type RunStats struct {
+	Start      time.Time
+	Duration   time.Duration
+	Scanned    int
+	Violations int
+	Errors     int
+}
+
+// RecordRun updates the per-run gauges with the outcome of a scan run.
+// A run with errors counts as failed.
+func RecordRun(stats RunStats) {
+	recordRunStatus(stats)
+	if !enabled.Load() {
+		return
+	}
+	status := "success"
+	success := 1.0
+	if stats.Errors > 0 {
+		status = "failure"
+		success = 0
+	}
+	runs.WithLabelValues(status).Inc()
+	lastRunTimestamp.Set(float64(stats.Start.Unix()))
+	lastRunDuration.Set(stats.Duration.Seconds())
+	lastRunSuccess.Set(success)
+	lastRunScanned.Set(float64(stats.Scanned))
+	lastRunViolations.Set(float64(stats.Violations))
+	lastRunErrors.Set(float64(stats.Errors))
+}
+
+// SetNextRun records when the next scheduled scan run starts.
+func SetNextRun(t time.Time) {
+	setNextRunStatus(t)
+	if enabled.Load() {
+		nextRunTimestamp.Set(float64(t.Unix()))
+	}
+}
+
+// StartServer starts the Prometheus metrics endpoint together with the
+// health, readiness and status endpoints.
 func StartServer(addr string) error {
 	Enable()
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
-	return http.ListenAndServe(addr, mux)
+	return http.ListenAndServe(addr, Handler())
 }
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSetRuleViolations_ResetsEachRun(t *testing.T) {
+	Enable()
+	ssh := RuleLabels{Rule: "open-ssh", Service: "neutron", ResourceType: "security_group_rule", Severity: "high", Category: "security"}
+	vm := RuleLabels{Rule: "shutoff", Service: "nova", ResourceType: "instance", Severity: "low", Category: "cost"}
+
+	SetRuleViolations(map[RuleLabels]int{ssh: 3, vm: 1})
+	if got := testutil.ToFloat64(ruleViolations.WithLabelValues("open-ssh", "neutron", "security_group_rule", "high", "security")); got != 3 {
+		t.Fatalf("open-ssh = %v, want 3", got)
+	}
+
+	// The next run fixed everything and no longer evaluates shutoff.
+	SetRuleViolations(map[RuleLabels]int{ssh: 0})
+	if got := testutil.CollectAndCount(ruleViolations); got != 1 {
+		t.Fatalf("series after reset = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(ruleViolations.WithLabelValues("open-ssh", "neutron", "security_group_rule", "high", "security")); got != 0 {
+		t.Fatalf("open-ssh = %v, want 0", got)
+	}
+}
+
+func TestLabelledCounters(t *testing.T) {
+	Enable()
+	l := RuleLabels{Rule: "r1", Service: "nova", ResourceType: "instance", Severity: "high", Category: "security", Project: "p1"}
+	IncRuleViolation(l)
+	IncRuleViolation(l)
+	if got := testutil.ToFloat64(ruleViolationsTotal.WithLabelValues("r1", "nova", "instance", "high", "security", "p1")); got != 2 {
+		t.Errorf("ospa_rule_violations_total = %v, want 2", got)
+	}
+
+	IncRemediation("delete", OutcomeFailed)
+	if got := testutil.ToFloat64(remediations.WithLabelValues("delete", OutcomeFailed)); got != 1 {
+		t.Errorf("ospa_remediations_total = %v, want 1", got)
+	}
+
+	IncDiscovered("nova", "instance")
+	RecordDiscovery("nova", "instance", 7, 1500*time.Millisecond)
+	if got := testutil.ToFloat64(lastDiscovered.WithLabelValues("nova", "instance")); got != 7 {
+		t.Errorf("ospa_last_run_discovered_resources = %v, want 7", got)
+	}
+	if got := testutil.ToFloat64(scanDuration.WithLabelValues("nova", "instance")); got != 1.5 {
+		t.Errorf("ospa_last_run_scan_duration_seconds = %v, want 1.5", got)
+	}
+}
+
+func TestResetDiscovery(t *testing.T) {
+	Enable()
+	RecordDiscovery("nova", "instance", 7, time.Second)
+	RecordDiscovery("cinder", "volume", 2, time.Second)
+
+	// The next run only scans nova.
+	ResetDiscovery()
+	RecordDiscovery("nova", "instance", 5, time.Second)
+	if got := testutil.CollectAndCount(lastDiscovered); got != 1 {
+		t.Errorf("ospa_last_run_discovered_resources series = %d, want 1", got)
+	}
+	if got := testutil.CollectAndCount(scanDuration); got != 1 {
+		t.Errorf("ospa_last_run_scan_duration_seconds series = %d, want 1", got)
+	}
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Status is the document served under /status.
+type Status struct {
+	// Phase is the phase of the current run, or "starting" until the agent
+	// is ready.
+	Phase         progress.Phase     `json:"phase"`
+	Ready         bool               `json:"ready"`
+	Progress      *progress.Snapshot `json:"progress,omitempty"`
+	LastRunAt     *time.Time         `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time         `json:"last_success_at,omitempty"`
+	LastError     string             `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time         `json:"last_error_at,omitempty"`
+	NextRunAt     *time.Time         `json:"next_run_at,omitempty"`
+}
+
+// PhaseStarting is reported until SetReady(true) is called.
+const PhaseStarting progress.Phase = "starting"
+
+// The status is kept even when metrics are disabled, so it is complete once
+// the server starts.
+var status struct {
+	sync.Mutex
+	ready         bool
+	tracker       *progress.Tracker
+	lastRunAt     time.Time
+	lastSuccessAt time.Time
+	lastError     string
+	lastErrorAt   time.Time
+	nextRunAt     time.Time
+}
+
+// SetReady marks the agent ready, i.e. authenticated with a loaded policy.
+func SetReady(ready bool) {
+	status.Lock()
+	defer status.Unlock()
+	status.ready = ready
+}
+
+// SetProgressTracker sets the tracker whose progress is reported in /status.
+func SetProgressTracker(t *progress.Tracker) {
+	status.Lock()
+	defer status.Unlock()
+	status.tracker = t
+}
+
+// RecordError records the most recent error, e.g. a failed run or a resource
+// that could not be audited.
+func RecordError(err error) {
+	if err == nil {
+		return
+	}
+	status.Lock()
+	defer status.Unlock()
+	status.lastError = err.Error()
+	status.lastErrorAt = time.Now()
+}
+
+// recordRunStatus updates the status with a completed run.
+func recordRunStatus(stats RunStats) {
+	status.Lock()
+	defer status.Unlock()
+	status.lastRunAt = stats.Start
+	if stats.Errors == 0 {
+		status.lastSuccessAt = stats.Start.Add(stats.Duration)
+	}
+}
+
+func setNextRunStatus(t time.Time) {
+	status.Lock()
+	defer status.Unlock()
+	status.nextRunAt = t
+}
+
+// CurrentStatus returns a snapshot of the agent status.
+func CurrentStatus() Status {
+	status.Lock()
+	defer status.Unlock()
+
+	s := Status{
+		Phase:         PhaseStarting,
+		Ready:         status.ready,
+		LastRunAt:     timePtr(status.lastRunAt),
+		LastSuccessAt: timePtr(status.lastSuccessAt),
+		LastError:     status.lastError,
+		LastErrorAt:   timePtr(status.lastErrorAt),
+		NextRunAt:     timePtr(status.nextRunAt),
+	}
+	if status.ready {
+		s.Phase = progress.PhaseIdle
+	}
+	if status.tracker != nil {
+		snapshot := status.tracker.Snapshot()
+		s.Progress = &snapshot
+		if status.ready {
+			s.Phase = snapshot.Phase
+		}
+	}
+	return s
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Handler returns the handler of the metrics server: /metrics, /healthz,
+// /readyz and /status.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status.Lock()
+		ready := status.ready
+		status.Unlock()
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(CurrentStatus())
+	})
+	return mux
+}
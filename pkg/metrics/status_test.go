@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
+)
+
+func TestHandler_HealthReadinessStatus(t *testing.T) {
+	SetReady(false)
+	SetProgressTracker(nil)
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	if resp := get("/healthz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("/healthz = %d, want 200", resp.StatusCode)
+	}
+	if resp := get("/readyz"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/readyz before ready = %d, want 503", resp.StatusCode)
+	}
+
+	tracker := progress.NewTracker()
+	tracker.Start(time.Now())
+	tracker.AddDiscovered("nova", "instance")
+	tracker.AddDiscovered("nova", "instance")
+	tracker.AddProcessed("nova", "instance")
+	SetProgressTracker(tracker)
+	SetReady(true)
+	t.Cleanup(func() { SetReady(false); SetProgressTracker(nil) })
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	RecordRun(RunStats{Start: start, Duration: time.Minute})
+	RecordRun(RunStats{Start: start.Add(time.Hour), Duration: time.Minute, Errors: 1})
+	RecordError(fmt.Errorf("open-ssh on sg-1: timeout"))
+
+	if resp := get("/readyz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("/readyz when ready = %d, want 200", resp.StatusCode)
+	}
+
+	var status Status
+	if err := json.NewDecoder(get("/status").Body).Decode(&status); err != nil {
+		t.Fatalf("decoding /status: %v", err)
+	}
+	if status.Phase != progress.PhaseDiscovering || !status.Ready {
+		t.Errorf("phase = %q, ready = %v", status.Phase, status.Ready)
+	}
+	if status.Progress == nil || len(status.Progress.Counts) != 1 || status.Progress.Counts[0].Processed != 1 {
+		t.Fatalf("progress = %+v", status.Progress)
+	}
+	if status.LastSuccessAt == nil || !status.LastSuccessAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("last_success_at = %v, want end of the first run", status.LastSuccessAt)
+	}
+	if status.LastRunAt == nil || !status.LastRunAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("last_run_at = %v", status.LastRunAt)
+	}
+	if status.LastError != "open-ssh on sg-1: timeout" || status.LastErrorAt == nil {
+		t.Errorf("last_error = %q at %v", status.LastError, status.LastErrorAt)
+	}
+}
@@ -0,0 +1,409 @@
+// Package notify pushes findings to webhooks and chat channels configured in
+// the policy's notifications section.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/report"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, prefixed
+// with "sha256=", when a destination has a secret.
+const SignatureHeader = "X-OSPA-Signature"
+
+const (
+	defaultRetries = 3
+	defaultTimeout = 10 * time.Second
+	queueSize      = 16
+)
+
+// RetryBackoff is the delay before the first retry; it doubles per attempt.
+var RetryBackoff = time.Second
+
+// Data is the template data for a message.
+type Data struct {
+	// Cloud is the audited cloud's name.
+	Cloud string
+	// Destination is the notification name.
+	Destination string
+	// Findings are the findings in this message.
+	Findings []report.Finding
+	// Count is len(Findings); Violations and Errors break it down.
+	Count      int
+	Violations int
+	Errors     int
+}
+
+// defaultText is the message text for chat destinations.
+const defaultText = `OSPA{{ if .Cloud }} ({{ .Cloud }}){{ end }}: {{ .Count }} finding{{ if ne .Count 1 }}s{{ end }}
+{{- range .Findings }}
+- [{{ or .Severity "n/a" }}] {{ .RuleID }}: {{ .Service }}/{{ .ResourceType }} {{ or .ResourceName .ResourceID }}
+{{- if .ProjectID }} (project {{ .ProjectID }}){{ end }}
+{{- if .Error }} error: {{ .Error }}{{ else if .Observation }} {{ .Observation }}{{ end }}
+{{- if .GuideRef }} [{{ .GuideRef }}]{{ end }}
+{{- end }}`
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+}
+
+// Notifier delivers matching findings to one destination. It implements
+// report.ResultWriter so it can be fed from the results channel alongside
+// other writers. Deliveries run on a background goroutine so retries do not
+// hold up result processing: batches that do not fit in the delivery queue
+// wait in a backlog instead of blocking WriteResult.
+type Notifier struct {
+	name      string
+	kind      string
+	url       string
+	headers   map[string]string
+	secret    []byte
+	tmpl      *template.Template
+	filter    policy.NotificationFilter
+	batchSize int
+	interval  time.Duration
+	digest    bool
+	retries   int
+	client    *http.Client
+	cloud     string
+
+	mu      sync.Mutex
+	pending []report.Finding
+	backlog [][]report.Finding
+
+	queue   chan []report.Finding
+	stop    chan struct{}
+	flushWG sync.WaitGroup // flushLoop, which must stop before queue is closed
+	wg      sync.WaitGroup // deliverLoop
+	failed  int
+}
+
+// New builds a notifier from cfg and starts its delivery goroutine.
+func New(cfg policy.Notification) (*Notifier, error) {
+	n := &Notifier{
+		name:      cfg.Name,
+		kind:      strings.ToLower(cfg.Type),
+		url:       os.ExpandEnv(cfg.URL),
+		headers:   make(map[string]string, len(cfg.Headers)),
+		filter:    cfg.Filter,
+		batchSize: cfg.BatchSize,
+		digest:    cfg.Digest,
+		retries:   defaultRetries,
+		queue:     make(chan []report.Finding, queueSize),
+		stop:      make(chan struct{}),
+	}
+	if n.url == "" {
+		return nil, fmt.Errorf("notification %q: url is empty", cfg.Name)
+	}
+	for k, v := range cfg.Headers {
+		n.headers[k] = os.ExpandEnv(v)
+	}
+	if cfg.Secret != "" {
+		n.secret = []byte(os.ExpandEnv(cfg.Secret))
+	}
+	if cfg.Retries != nil {
+		n.retries = *cfg.Retries
+	}
+	if n.batchSize <= 0 {
+		n.batchSize = 1
+	}
+
+	var err error
+	if n.interval, err = cfg.BatchIntervalDuration(); err != nil {
+		return nil, fmt.Errorf("notification %q: batch_interval: %w", cfg.Name, err)
+	}
+	timeout, err := cfg.TimeoutDuration()
+	if err != nil {
+		return nil, fmt.Errorf("notification %q: timeout: %w", cfg.Name, err)
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	n.client = &http.Client{Timeout: timeout}
+
+	text := cfg.Template
+	if text == "" && n.kind != policy.NotificationWebhook {
+		text = defaultText
+	}
+	if text != "" {
+		if n.tmpl, err = template.New(cfg.Name).Funcs(templateFuncs).Parse(text); err != nil {
+			return nil, fmt.Errorf("notification %q: invalid template: %w", cfg.Name, err)
+		}
+	}
+
+	n.wg.Add(1)
+	go n.deliverLoop()
+	if n.interval > 0 && !n.digest {
+		n.flushWG.Add(1)
+		go n.flushLoop()
+	}
+	return n, nil
+}
+
+// FromPolicy builds a notifier for every destination in p.
+func FromPolicy(p *policy.Policy) ([]*Notifier, error) {
+	var notifiers []*Notifier
+	for _, cfg := range p.Notifications {
+		n, err := New(cfg)
+		if err != nil {
+			for _, started := range notifiers {
+				_ = started.Close()
+			}
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// SetCloud sets the cloud name passed to templates.
+func (n *Notifier) SetCloud(name string) {
+	n.cloud = name
+}
+
+// Matches reports whether r passes the destination's filter.
+func (n *Notifier) Matches(r *audit.Result) bool {
+	if r.Error != nil {
+		if !n.filter.IncludeErrors {
+			return false
+		}
+	} else if r.Compliant && r.RemediationError == nil {
+		return false
+	}
+
+	var service string
+	if r.Rule != nil {
+		service = r.Rule.Service
+	}
+	return n.filter.Match(r.Severity, r.Category, service)
+}
+
+// WriteResult queues r for delivery if it matches the filter.
+func (n *Notifier) WriteResult(r *audit.Result) error {
+	if !n.Matches(r) {
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending = append(n.pending, report.NewFinding(r))
+	if !n.digest && len(n.pending) >= n.batchSize {
+		n.backlog = append(n.backlog, n.takeLocked())
+	}
+	n.sendBacklogLocked()
+	return nil
+}
+
+// Close sends any pending findings, waits for deliveries to finish and
+// reports how many failed.
+func (n *Notifier) Close() error {
+	close(n.stop)
+	n.flushWG.Wait()
+
+	n.mu.Lock()
+	if len(n.pending) > 0 {
+		n.backlog = append(n.backlog, n.takeLocked())
+	}
+	backlog := n.backlog
+	n.backlog = nil
+	n.mu.Unlock()
+
+	// Nothing else sends now: flushLoop has returned and deliverLoop only
+	// sends from the backlog, which is empty.
+	for _, batch := range backlog {
+		n.queue <- batch
+	}
+	close(n.queue)
+	n.wg.Wait()
+
+	if n.failed > 0 {
+		return fmt.Errorf("notification %q: %d deliveries failed", n.name, n.failed)
+	}
+	return nil
+}
+
+func (n *Notifier) takeLocked() []report.Finding {
+	batch := n.pending
+	n.pending = nil
+	return batch
+}
+
+// sendBacklogLocked hands backlogged batches to deliverLoop, in order, until
+// the queue is full. The caller must hold n.mu.
+func (n *Notifier) sendBacklogLocked() {
+	for len(n.backlog) > 0 {
+		select {
+		case n.queue <- n.backlog[0]:
+			n.backlog = n.backlog[1:]
+		default:
+			return
+		}
+	}
+}
+
+func (n *Notifier) flushLoop() {
+	defer n.flushWG.Done()
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			n.mu.Lock()
+			if len(n.pending) > 0 {
+				n.backlog = append(n.backlog, n.takeLocked())
+			}
+			n.sendBacklogLocked()
+			n.mu.Unlock()
+		}
+	}
+}
+
+func (n *Notifier) deliverLoop() {
+	defer n.wg.Done()
+	for batch := range n.queue {
+		if err := n.deliver(batch); err != nil {
+			n.failed++
+			slog.Error("notification delivery failed", "notification", n.name, "findings", len(batch), "error", err)
+		}
+		// The queue has room again; refill it from the backlog.
+		n.mu.Lock()
+		n.sendBacklogLocked()
+		n.mu.Unlock()
+	}
+}
+
+// deliver renders and posts one message, retrying network errors, 429s and
+// 5xx responses with exponential backoff.
+func (n *Notifier) deliver(findings []report.Finding) error {
+	body, err := n.render(findings)
+	if err != nil {
+		return err
+	}
+
+	backoff := RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := n.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= n.retries {
+			return err
+		}
+		slog.Warn("notification delivery retry", "notification", n.name, "attempt", attempt+1, "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OSPA")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+	if n.secret != nil {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// render builds the request body for findings.
+func (n *Notifier) render(findings []report.Finding) ([]byte, error) {
+	data := Data{Cloud: n.cloud, Destination: n.name, Findings: findings, Count: len(findings)}
+	for _, f := range findings {
+		if f.Error != "" {
+			data.Errors++
+		} else {
+			data.Violations++
+		}
+	}
+
+	if n.kind == policy.NotificationWebhook && n.tmpl == nil {
+		return json.Marshal(webhookPayload{
+			Cloud:       data.Cloud,
+			Destination: data.Destination,
+			Count:       data.Count,
+			Violations:  data.Violations,
+			Errors:      data.Errors,
+			Findings:    findings,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := n.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+	text := buf.String()
+
+	switch n.kind {
+	case policy.NotificationWebhook:
+		return buf.Bytes(), nil
+	case policy.NotificationTeams:
+		return json.Marshal(map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "OSPA findings",
+			"text":     text,
+		})
+	default: // slack, mattermost
+		return json.Marshal(map[string]string{"text": text})
+	}
+}
+
+// webhookPayload is the default body for generic webhooks.
+type webhookPayload struct {
+	Cloud       string           `json:"cloud,omitempty"`
+	Destination string           `json:"destination"`
+	Count       int              `json:"count"`
+	Violations  int              `json:"violations"`
+	Errors      int              `json:"errors"`
+	Findings    []report.Finding `json:"findings"`
+}
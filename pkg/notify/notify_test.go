@@ -0,0 +1,333 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+// recorder is a test endpoint that records request bodies and headers.
+type recorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int // status per request; 200 once exhausted
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.bodies = append(rec.bodies, string(body))
+	rec.headers = append(rec.headers, r.Header.Clone())
+	status := http.StatusOK
+	if len(rec.statuses) > 0 {
+		status, rec.statuses = rec.statuses[0], rec.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rec *recorder) requests() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]string(nil), rec.bodies...)
+}
+
+func newServer(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func violation(id, severity, service string) *audit.Result {
+	return &audit.Result{
+		RuleID:      "rule-" + service,
+		ResourceID:  id,
+		ProjectID:   "proj-1",
+		Severity:    severity,
+		Category:    "security",
+		Observation: "bad " + id,
+		Rule:        &policy.Rule{Name: "rule-" + service, Service: service, Resource: "thing"},
+	}
+}
+
+func TestNotifier_SlackFiltersAndUsesDefaultText(t *testing.T) {
+	rec := &recorder{}
+	srv := newServer(t, rec)
+
+	n, err := New(policy.Notification{
+		Name:   "slack",
+		Type:   "slack",
+		URL:    srv.URL,
+		Filter: policy.NotificationFilter{FindingFilter: policy.FindingFilter{Severities: []string{"critical", "high"}, Services: []string{"neutron"}}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	n.SetCloud("prod")
+
+	for _, r := range []*audit.Result{
+		violation("a", "critical", "neutron"),
+		violation("b", "low", "neutron"),                // severity filtered
+		violation("c", "high", "nova"),                  // service filtered
+		{RuleID: "r", ResourceID: "d", Compliant: true}, // compliant
+		{RuleID: "r", ResourceID: "e", Error: errors.New("boom"), Rule: &policy.Rule{Service: "neutron"}}, // errors excluded
+	} {
+		if err := n.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult() error = %v", err)
+		}
+	}
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := rec.requests()
+	if len(got) != 1 {
+		t.Fatalf("requests = %q, want 1", got)
+	}
+	var msg struct{ Text string }
+	if err := json.Unmarshal([]byte(got[0]), &msg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := "OSPA (prod): 1 finding\n- [critical] rule-neutron: neutron/thing a (project proj-1) bad a"
+	if msg.Text != want {
+		t.Fatalf("text = %q\nwant %q", msg.Text, want)
+	}
+}
+
+func TestNotifier_BatchesAndDigests(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       policy.Notification
+		wantSizes []int
+	}{
+		{"per finding", policy.Notification{}, []int{1, 1, 1}},
+		{"batch of two", policy.Notification{BatchSize: 2}, []int{2, 1}},
+		{"digest", policy.Notification{Digest: true}, []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			srv := newServer(t, rec)
+			cfg := tt.cfg
+			cfg.Name, cfg.Type, cfg.URL = "hook", "webhook", srv.URL
+
+			n, err := New(cfg)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			for _, id := range []string{"a", "b", "c"} {
+				_ = n.WriteResult(violation(id, "high", "nova"))
+			}
+			if err := n.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := rec.requests()
+			if len(got) != len(tt.wantSizes) {
+				t.Fatalf("requests = %d, want %d", len(got), len(tt.wantSizes))
+			}
+			for i, body := range got {
+				var payload webhookPayload
+				if err := json.Unmarshal([]byte(body), &payload); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+				if payload.Count != tt.wantSizes[i] || len(payload.Findings) != tt.wantSizes[i] || payload.Destination != "hook" {
+					t.Errorf("request %d = %+v, want %d findings", i, payload, tt.wantSizes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNotifier_BatchIntervalFlushesPartialBatch(t *testing.T) {
+	rec := &recorder{}
+	srv := newServer(t, rec)
+	n, err := New(policy.Notification{Name: "hook", Type: "webhook", URL: srv.URL, BatchSize: 10, BatchInterval: "10ms"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer n.Close()
+
+	_ = n.WriteResult(violation("a", "high", "nova"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.requests()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNotifier_WriteResultDoesNotBlockOnSlowDestination(t *testing.T) {
+	release := make(chan struct{})
+	rec := &recorder{}
+	srv := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		rec.ServeHTTP(w, r)
+	}))
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	t.Cleanup(unblock) // runs before the server is closed
+	n, err := New(policy.Notification{Name: "hook", Type: "webhook", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	const total = queueSize + 10
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < total; i++ {
+			_ = n.WriteResult(violation(fmt.Sprintf("r%d", i), "high", "nova"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WriteResult blocked while deliveries were stalled")
+	}
+
+	unblock()
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	got := rec.requests()
+	if len(got) != total {
+		t.Fatalf("requests = %d, want %d", len(got), total)
+	}
+	for i, body := range got {
+		if want := fmt.Sprintf(`"resource_id":"r%d"`, i); !strings.Contains(body, want) {
+			t.Fatalf("request %d = %s, want %s in order", i, body, want)
+		}
+	}
+}
+
+func TestNotifier_CloseWhileFlushing(t *testing.T) {
+	rec := &recorder{}
+	srv := newServer(t, rec)
+	for i := 0; i < 50; i++ {
+		n, err := New(policy.Notification{Name: "hook", Type: "webhook", URL: srv.URL, BatchSize: 100, BatchInterval: "1ms"})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		for j := 0; j < queueSize*2; j++ {
+			_ = n.WriteResult(violation("a", "high", "nova"))
+			time.Sleep(10 * time.Microsecond)
+		}
+		if err := n.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+}
+
+func TestNotifier_SignsAndRetries(t *testing.T) {
+	RetryBackoff = time.Millisecond
+	defer func() { RetryBackoff = time.Second }()
+
+	rec := &recorder{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	srv := newServer(t, rec)
+	t.Setenv("OSPA_TEST_SECRET", "s3cret")
+	t.Setenv("OSPA_TEST_URL", srv.URL)
+
+	n, err := New(policy.Notification{
+		Name:    "hook",
+		Type:    "webhook",
+		URL:     "${OSPA_TEST_URL}",
+		Secret:  "${OSPA_TEST_SECRET}",
+		Headers: map[string]string{"X-Team": "sec"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = n.WriteResult(violation("a", "high", "nova"))
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := rec.requests()
+	if len(got) != 3 {
+		t.Fatalf("requests = %d, want 2 failures and 1 success", len(got))
+	}
+	h := rec.headers[2]
+	if h.Get(SignatureHeader) != Sign([]byte("s3cret"), []byte(got[2])) {
+		t.Errorf("signature = %q, want HMAC of body", h.Get(SignatureHeader))
+	}
+	if h.Get("X-Team") != "sec" || h.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", h)
+	}
+}
+
+func TestNotifier_GivesUpOnClientErrors(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusBadRequest}}
+	srv := newServer(t, rec)
+	n, err := New(policy.Notification{Name: "hook", Type: "webhook", URL: srv.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = n.WriteResult(violation("a", "high", "nova"))
+	if err := n.Close(); err == nil || !strings.Contains(err.Error(), "1 deliveries failed") {
+		t.Fatalf("Close() error = %v, want delivery failure", err)
+	}
+	if len(rec.requests()) != 1 {
+		t.Fatalf("requests = %d, want no retry on 400", len(rec.requests()))
+	}
+}
+
+func TestNotifier_CustomTemplates(t *testing.T) {
+	rec := &recorder{}
+	srv := newServer(t, rec)
+
+	hook, err := New(policy.Notification{
+		Name:     "hook",
+		Type:     "webhook",
+		URL:      srv.URL,
+		Template: `{"summary": {{ json (printf "%d violations" .Violations) }}, "ids": [{{ range $i, $f := .Findings }}{{ if $i }},{{ end }}{{ json $f.ResourceID }}{{ end }}]}`,
+		Digest:   true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = hook.WriteResult(violation("a", "high", "nova"))
+	_ = hook.WriteResult(violation("b", "high", "nova"))
+	_ = hook.Close()
+
+	teams, err := New(policy.Notification{Name: "teams", Type: "teams", URL: srv.URL, Template: "{{ upper .Destination }}: {{ .Count }}"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = teams.WriteResult(violation("c", "high", "nova"))
+	_ = teams.Close()
+
+	got := rec.requests()
+	if len(got) != 2 {
+		t.Fatalf("requests = %q", got)
+	}
+	if got[0] != `{"summary": "2 violations", "ids": ["a","b"]}` {
+		t.Errorf("webhook body = %s", got[0])
+	}
+	var card map[string]string
+	if err := json.Unmarshal([]byte(got[1]), &card); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if card["@type"] != "MessageCard" || card["text"] != "TEAMS: 1" {
+		t.Errorf("teams body = %v", card)
+	}
+}
+
+func TestNew_InvalidTemplate(t *testing.T) {
+	if _, err := New(policy.Notification{Name: "x", Type: "slack", URL: "http://example.invalid", Template: "{{ .Count"}); err == nil {
+		t.Fatal("New() expected template error")
+	}
+}
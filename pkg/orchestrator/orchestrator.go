@@ -6,16 +6,25 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/auth"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/metrics"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/remediate"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/rollout"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/services"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/tracing"
 	"github.com/gophercloud/gophercloud"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Orchestrator coordinates policy execution
@@ -37,10 +46,23 @@ type Orchestrator struct {
 	clientCacheLock sync.Mutex
 
 	remediationAllowlist map[string]bool
+	approvals            *approval.Store
+	rollouts             *rollout.State
+	now                  func() time.Time
 
 	compositeRules     map[string][]*policy.CompositeRule
 	compositeResources map[string]map[string][]discovery.Job
 	compositeLock      sync.Mutex
+
+	// runCtx carries the span of the active run.
+	runCtx context.Context
+
+	// discovered maps the "service/resource_type" of each discovery of the
+	// active run to whether it completed.
+	discovered     map[string]bool
+	discoveredLock sync.Mutex
+
+	progress *progress.Tracker
 }
 
 // NewOrchestrator creates a new orchestrator
@@ -61,6 +83,9 @@ func NewOrchestrator(p *policy.Policy, session *auth.Session, workers int, apply
 		clientCache:        make(map[string]*gophercloud.ServiceClient),
 		compositeRules:     make(map[string][]*policy.CompositeRule),
 		compositeResources: make(map[string]map[string][]discovery.Job),
+		now:                time.Now,
+		runCtx:             ctx,
+		progress:           progress.NewTracker(),
 	}
 }
 
@@ -91,10 +116,103 @@ func (o *Orchestrator) SetRemediationAllowlist(actions []string) {
 	o.remediationAllowlist = allow
 }
 
-// Run executes the policy audit
+// SetApprovalStore sets the store used for rules with approval: required.
+// Without a store such rules are never remediated.
+func (o *Orchestrator) SetApprovalStore(store *approval.Store) {
+	o.approvals = store
+}
+
+// SetRolloutState sets the state that tracks the active stage of rules with
+// a rollout block. Without it every rollout stays in its first stage.
+func (o *Orchestrator) SetRolloutState(state *rollout.State) {
+	o.rollouts = state
+}
+
+// SetClock overrides the clock used to evaluate maintenance windows.
+func (o *Orchestrator) SetClock(now func() time.Time) {
+	if now != nil {
+		o.now = now
+	}
+}
+
+// SetPolicy replaces the policy used by subsequent runs, e.g. after the
+// policy file was reloaded. It must not be called while a run is active.
+func (o *Orchestrator) SetPolicy(p *policy.Policy) {
+	o.policy = p
+}
+
+// Progress returns the tracker updated by every run.
+func (o *Orchestrator) Progress() *progress.Tracker {
+	return o.progress
+}
+
+// Done is closed when the orchestrator is stopped or receives SIGINT or
+// SIGTERM.
+func (o *Orchestrator) Done() <-chan struct{} {
+	return o.ctx.Done()
+}
+
+// Discovered returns the service/resource types, as "service/resource_type",
+// whose discovery completed in the last run, sorted. A type whose discovery
+// failed is missing, and a cancelled run returns none. Call it after the
+// results channel returned by Run was closed.
+func (o *Orchestrator) Discovered() []string {
+	o.discoveredLock.Lock()
+	defer o.discoveredLock.Unlock()
+
+	if o.ctx.Err() != nil {
+		return nil
+	}
+	var types []string
+	for key, completed := range o.discovered {
+		if completed {
+			types = append(types, key)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// setDiscovered records whether the discovery of service/resourceType
+// completed. A failure is never overwritten.
+func (o *Orchestrator) setDiscovered(service, resourceType string, completed bool) {
+	o.discoveredLock.Lock()
+	defer o.discoveredLock.Unlock()
+
+	key := service + "/" + resourceType
+	if done, seen := o.discovered[key]; seen && !done {
+		return
+	}
+	o.discovered[key] = completed
+}
+
+// setCompositesDiscovered records the composite rules of a service as
+// discovered when every discovery of that service completed.
+func (o *Orchestrator) setCompositesDiscovered() {
+	for service := range o.compositeRules {
+		completed := true
+		o.discoveredLock.Lock()
+		for key, done := range o.discovered {
+			if !done && strings.HasPrefix(key, service+"/") {
+				completed = false
+			}
+		}
+		o.discoveredLock.Unlock()
+		o.setDiscovered(service, "composite", completed)
+	}
+}
+
+// Run executes the policy audit. The run is traced as one span that ends
+// when the results channel is closed.
 func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
+	runCtx, runSpan := tracing.Start(o.ctx, "ospa.run")
+	o.runCtx = runCtx
+	o.progress.Start(time.Now())
+	metrics.ResetDiscovery()
+
 	// Get all rules from policy
 	rules := o.policy.GetAllRules()
+	runSpan.SetAttributes(attribute.Int("ospa.rules", len(rules)))
 
 	// Group rules by service and resource type for efficient discovery
 	ruleGroups := make(map[string]map[string][]*policy.Rule)
@@ -111,9 +229,31 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 	o.ruleIndex = ruleGroups
 
 	o.compositeRules = o.buildCompositeRules()
+	o.compositeLock.Lock()
+	o.compositeResources = make(map[string]map[string][]discovery.Job)
+	o.compositeLock.Unlock()
 
 	o.validateCheckCoverage(ruleGroups)
 
+	o.discoveredLock.Lock()
+	o.discovered = make(map[string]bool)
+	o.discoveredLock.Unlock()
+
+	// Rollouts only progress while remediation is enabled.
+	if o.apply && o.rollouts != nil {
+		if err := o.rollouts.Advance(rules, o.now()); err != nil {
+			err = fmt.Errorf("advancing rollouts: %w", err)
+			tracing.End(runSpan, err)
+			o.progress.SetPhase(progress.PhaseIdle, time.Now())
+			return nil, err
+		}
+		for _, rule := range rules {
+			if rule.Rollout != nil {
+				slog.Info("rollout stage", "rule", rule.Name, "stage", o.rollouts.ActiveStage(rule.Name)+1, "stages", len(rule.Rollout.Stages))
+			}
+		}
+	}
+
 	// Start worker pool
 	var wg sync.WaitGroup
 	jobsChan := make(chan discovery.Job, o.jobsBuffer)
@@ -132,6 +272,9 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 		if err != nil {
 			slog.Warn("service not found", "service", serviceName, "error", err)
 			metrics.IncServiceNotFound()
+			for resourceType := range resourceRules {
+				o.setDiscovered(serviceName, resourceType, false)
+			}
 			continue
 		}
 
@@ -139,6 +282,9 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 		if err != nil {
 			slog.Warn("failed to get client", "service", serviceName, "error", err)
 			metrics.IncClientErrors()
+			for resourceType := range resourceRules {
+				o.setDiscovered(serviceName, resourceType, false)
+			}
 			continue
 		}
 
@@ -147,6 +293,7 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 			if err != nil {
 				slog.Warn("discoverer not found", "service", serviceName, "resource", resourceType, "error", err)
 				metrics.IncDiscovererNotFound()
+				o.setDiscovered(serviceName, resourceType, false)
 				continue
 			}
 
@@ -154,20 +301,42 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 			go func(svc string, resType string, disc discovery.Discoverer, cli *gophercloud.ServiceClient, rls []*policy.Rule) {
 				defer discoveryWg.Done()
 
-				jobChan, err := disc.Discover(o.ctx, cli, o.allTenants)
+				ctx, span := tracing.Start(runCtx, "ospa.discover",
+					tracing.AttrService.String(svc), tracing.AttrResourceType.String(resType))
+				start := time.Now()
+				jobChan, err := disc.Discover(ctx, cli, o.allTenants)
 				if err != nil {
 					slog.Error("discovery error", "service", svc, "resource", resType, "error", err)
 					metrics.IncDiscoveryErrors()
+					o.setDiscovered(svc, resType, false)
+					tracing.End(span, err)
 					return
 				}
 
+				count := 0
+				var failed error
 				for job := range jobChan {
+					if job.Err != nil {
+						slog.Error("discovery error", "service", svc, "resource", resType, "error", job.Err)
+						metrics.IncDiscoveryErrors()
+						failed = job.Err
+						continue
+					}
+					o.progress.AddDiscovered(svc, resType)
 					select {
 					case <-o.ctx.Done():
+						tracing.End(span, o.ctx.Err())
 						return
 					case jobsChan <- job:
+						count++
+						metrics.IncDiscovered(svc, resType)
+						o.progress.AddQueued(svc, resType)
 					}
 				}
+				metrics.RecordDiscovery(svc, resType, count, time.Since(start))
+				span.SetAttributes(attribute.Int("ospa.discovered", count))
+				o.setDiscovered(svc, resType, failed == nil)
+				tracing.End(span, failed)
 			}(serviceName, resourceType, discoverer, client, rules)
 		}
 	}
@@ -175,6 +344,7 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 	// Close jobs channel when all discovery is done
 	go func() {
 		discoveryWg.Wait()
+		o.progress.SetPhase(progress.PhaseAuditing, time.Now())
 		close(jobsChan)
 	}()
 
@@ -182,7 +352,15 @@ func (o *Orchestrator) Run() (<-chan *audit.Result, error) {
 	go func() {
 		wg.Wait()
 		o.runCompositeAudits()
+		o.setCompositesDiscovered()
+		if o.approvals != nil {
+			if err := o.approvals.Flush(); err != nil {
+				slog.Warn("failed to record approval requests", "error", err)
+			}
+		}
+		o.progress.SetPhase(progress.PhaseIdle, time.Now())
 		close(o.resultsChan)
+		runSpan.End()
 	}()
 
 	return o.resultsChan, nil
@@ -199,81 +377,162 @@ func (o *Orchestrator) worker(id int, jobsChan <-chan discovery.Job, wg *sync.Wa
 		default:
 		}
 
-		// Get service and auditor
-		service, err := services.Get(job.Service)
-		if err != nil {
-			slog.Warn("service not found", "worker", id, "service", job.Service, "error", err)
-			metrics.IncServiceNotFound()
-			continue
+		if !o.processJob(id, job) {
+			return
 		}
+		o.progress.AddProcessed(job.Service, job.ResourceType)
+	}
+}
+
+// processJob evaluates every rule of the job's resource type against it. It
+// returns false when the run was cancelled.
+func (o *Orchestrator) processJob(id int, job discovery.Job) bool {
+	// Get service and auditor
+	service, err := services.Get(job.Service)
+	if err != nil {
+		slog.Warn("service not found", "worker", id, "service", job.Service, "error", err)
+		metrics.IncServiceNotFound()
+		return true
+	}
+
+	o.recordCompositeResource(job)
 
-		o.recordCompositeResource(job)
+	client, err := o.getClient(job.Service, service)
+	if err != nil {
+		slog.Warn("failed to get client", "worker", id, "service", job.Service, "error", err)
+		metrics.IncClientErrors()
+		return true
+	}
+
+	// Get rules for this service/resource type from the policy
+	relevantRules := o.ruleIndex[job.Service][job.ResourceType]
+	if len(relevantRules) == 0 {
+		return true
+	}
 
-		client, err := o.getClient(job.Service, service)
+	// Process each relevant rule
+	for _, rule := range relevantRules {
+		// Get auditor
+		auditor, err := service.GetResourceAuditor(job.ResourceType)
 		if err != nil {
-			slog.Warn("failed to get client", "worker", id, "service", job.Service, "error", err)
-			metrics.IncClientErrors()
+			slog.Warn("auditor not found", "worker", id, "service", job.Service, "resource", job.ResourceType, "error", err)
+			metrics.IncAuditorNotFound()
 			continue
 		}
 
-		// Get rules for this service/resource type from the policy
-		relevantRules := o.ruleIndex[job.Service][job.ResourceType]
-		if len(relevantRules) == 0 {
-			continue
+		// Check resource
+		ctx, span := tracing.Start(o.runCtx, "ospa.check", jobAttributes(job, rule)...)
+		result, err := auditor.Check(ctx, job.Resource, rule)
+		if err == nil {
+			span.SetAttributes(attribute.Bool("ospa.compliant", result.Compliant))
 		}
-
-		// Process each relevant rule
-		for _, rule := range relevantRules {
-			// Get auditor
-			auditor, err := service.GetResourceAuditor(job.ResourceType)
-			if err != nil {
-				slog.Warn("auditor not found", "worker", id, "service", job.Service, "resource", job.ResourceType, "error", err)
-				metrics.IncAuditorNotFound()
-				continue
+		tracing.End(span, err)
+		o.progress.AddAudited(job.Service, job.ResourceType)
+		if err != nil {
+			result = &audit.Result{
+				RuleID:     rule.Name,
+				ResourceID: job.ResourceID,
+				Compliant:  false,
+				Error:      err,
+				ErrorKind:  audit.ErrorKindAudit,
+				Rule:       rule,
 			}
+		}
+		// Some API types carry no project, e.g. cinder volumes; discovery does.
+		if result.ProjectID == "" {
+			result.ProjectID = job.ProjectID
+		}
 
-			// Check resource
-			result, err := auditor.Check(o.ctx, job.Resource, rule)
-			if err != nil {
-				result = &audit.Result{
-					RuleID:     rule.Name,
-					ResourceID: job.ResourceID,
-					Compliant:  false,
-					Error:      err,
-					ErrorKind:  audit.ErrorKindAudit,
-					Rule:       rule,
+		populateClassification(result, rule)
+
+		// Apply remediation if needed
+		if !result.Compliant && result.Error == nil && rule.Action != "log" {
+			if !o.apply {
+				result.RemediationSkipped = true
+				result.RemediationSkipReason = "dry-run"
+			} else if !o.isActionAllowed(rule.Action) {
+				result.RemediationSkipped = true
+				result.RemediationSkipReason = "action_not_allowed"
+			} else if !o.inRolloutStage(job, rule, result) {
+				result.RemediationSkipped = true
+				result.RemediationSkipReason = "outside_rollout_stage"
+			} else if approvalID, reason := o.checkApproval(job, rule, result); reason != "" {
+				result.RemediationSkipped = true
+				result.RemediationSkipReason = reason
+			} else if !o.inMaintenanceWindow(o.policy.EffectiveMaintenanceWindows(rule)) {
+				result.RemediationSkipped = true
+				result.RemediationSkipReason = "outside_maintenance_window"
+			} else {
+				result.RemediationAttempted = true
+				if err := o.remediate(auditor, client, job, rule, result); err != nil {
+					result.RemediationError = err
+					result.RemediationErrorKind = audit.ErrorKindRemediation
+				} else {
+					result.Remediated = true
+					o.progress.AddRemediated(job.Service, job.ResourceType)
+					o.markApprovalExecuted(approvalID)
 				}
 			}
+		}
 
-			populateClassification(result, rule)
+		if !o.send(result) {
+			return false
+		}
+	}
+	return true
+}
 
-			// Apply remediation if needed
-			if !result.Compliant && result.Error == nil && rule.Action != "log" {
-				if !o.apply {
-					result.RemediationSkipped = true
-					result.RemediationSkipReason = "dry-run"
-				} else if !o.isActionAllowed(rule.Action) {
-					result.RemediationSkipped = true
-					result.RemediationSkipReason = "action_not_allowed"
-				} else {
-					result.RemediationAttempted = true
-					if err := auditor.Fix(o.ctx, client, job.Resource, rule); err != nil {
-						result.RemediationError = err
-						result.RemediationErrorKind = audit.ErrorKindRemediation
-					} else {
-						result.Remediated = true
-					}
-				}
-			}
+// remediate applies the rule's action to the job's resource. Actions backed by
+// a ResourceRemediator (e.g. quarantine) run independently of the auditor;
+// all others are delegated to the auditor's Fix method. A rule's pre_action
+// runs first; if it fails the action is not applied. All of it is traced as
+// one ospa.fix span of the run.
+func (o *Orchestrator) remediate(auditor audit.Auditor, client *gophercloud.ServiceClient, job discovery.Job, rule *policy.Rule, result *audit.Result) (err error) {
+	ctx, span := tracing.Start(o.runCtx, "ospa.fix", append(jobAttributes(job, rule), tracing.AttrAction.String(rule.Action))...)
+	defer func() { tracing.End(span, err) }()
 
-			// Send result
-			select {
-			case <-o.ctx.Done():
-				return
-			case o.resultsChan <- result:
-			}
+	clients := remediate.ClientProviderFunc(o.clientFor)
+
+	if rule.PreAction != "" {
+		details, err := remediate.RunPreAction(ctx, clients, job, rule)
+		result.RemediationDetails = mergeDetails(result.RemediationDetails, details)
+		if err != nil {
+			return fmt.Errorf("pre_action %s failed, %s aborted: %w", rule.PreAction, rule.Action, err)
+		}
+	}
+
+	if r, err := remediate.Get(rule.Action); err == nil {
+		if rr, ok := r.(remediate.ResourceRemediator); ok {
+			details, err := rr.Remediate(ctx, clients, job, rule)
+			result.RemediationDetails = mergeDetails(result.RemediationDetails, details)
+			return err
 		}
 	}
+	return auditor.Fix(ctx, client, job.Resource, rule)
+}
+
+// jobAttributes returns the span attributes identifying a rule evaluation.
+func jobAttributes(job discovery.Job, rule *policy.Rule) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		tracing.AttrService.String(job.Service),
+		tracing.AttrResourceType.String(job.ResourceType),
+		tracing.AttrResourceID.String(job.ResourceID),
+		tracing.AttrRule.String(rule.Name),
+	}
+}
+
+// mergeDetails copies src into dst, allocating dst if needed.
+func mergeDetails(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
 }
 
 // Stop stops the orchestrator
@@ -293,10 +552,93 @@ func (o *Orchestrator) getClient(serviceName string, service services.Service) (
 	if err != nil {
 		return nil, err
 	}
+	tracing.InstrumentClient(client)
 	o.clientCache[serviceName] = client
 	return client, nil
 }
 
+// clientFor returns a cached client for the named service.
+func (o *Orchestrator) clientFor(serviceName string) (*gophercloud.ServiceClient, error) {
+	service, err := services.Get(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return o.getClient(serviceName, service)
+}
+
+// checkApproval gates rules that require approval. It returns the approval
+// item ID when the remediation was approved, or a skip reason when it must
+// wait ("awaiting_approval") or was rejected ("approval_rejected").
+func (o *Orchestrator) checkApproval(job discovery.Job, rule *policy.Rule, result *audit.Result) (string, string) {
+	if rule.Approval != policy.ApprovalRequired {
+		return "", ""
+	}
+	if o.approvals == nil {
+		return "", "awaiting_approval"
+	}
+
+	item, err := o.approvals.Request(approval.Item{
+		RuleID:       rule.Name,
+		Service:      job.Service,
+		ResourceType: job.ResourceType,
+		ResourceID:   job.ResourceID,
+		ResourceName: result.ResourceName,
+		ProjectID:    result.ProjectID,
+		Action:       rule.Action,
+	})
+	if err != nil {
+		slog.Warn("failed to record approval request", "rule", rule.Name, "resource", job.ResourceID, "error", err)
+		return "", "awaiting_approval"
+	}
+
+	switch item.State {
+	case approval.StateApproved:
+		return item.ID, ""
+	case approval.StateRejected:
+		return "", "approval_rejected"
+	default:
+		return "", "awaiting_approval"
+	}
+}
+
+// markApprovalExecuted records that an approved remediation ran.
+func (o *Orchestrator) markApprovalExecuted(id string) {
+	if id == "" || o.approvals == nil {
+		return
+	}
+	if err := o.approvals.MarkExecuted(id); err != nil {
+		slog.Warn("failed to mark approval executed", "approval", id, "error", err)
+	}
+}
+
+// inRolloutStage reports whether the resource's project is covered by the
+// rule's active rollout stage. Rules without a rollout cover every project.
+func (o *Orchestrator) inRolloutStage(job discovery.Job, rule *policy.Rule, result *audit.Result) bool {
+	if rule.Rollout == nil {
+		return true
+	}
+	stage := 0
+	if o.rollouts != nil {
+		stage = o.rollouts.ActiveStage(rule.Name)
+	}
+	projectID := result.ProjectID
+	if projectID == "" {
+		projectID = job.ProjectID
+	}
+	return rule.Rollout.Includes(stage, projectID)
+}
+
+// inMaintenanceWindow reports whether remediation may run now. Windows that
+// cannot be evaluated block remediation.
+func (o *Orchestrator) inMaintenanceWindow(windows []policy.MaintenanceWindow) bool {
+	ok, err := policy.InMaintenanceWindow(windows, o.now())
+	if err != nil {
+		slog.Warn("invalid maintenance window", "error", err)
+		return false
+	}
+	return ok
+}
+
 func (o *Orchestrator) isActionAllowed(action string) bool {
 	if o.remediationAllowlist == nil {
 		return true
@@ -371,6 +713,7 @@ func (o *Orchestrator) runCompositeAudits() {
 			}
 
 			o.normalizeCompositeResult(rule, result)
+			o.progress.AddAudited(service, "composite")
 
 			if !result.Compliant && result.Error == nil && rule.Action != "log" {
 				if !o.apply {
@@ -379,6 +722,9 @@ func (o *Orchestrator) runCompositeAudits() {
 				} else if !o.isActionAllowed(rule.Action) {
 					result.RemediationSkipped = true
 					result.RemediationSkipReason = "action_not_allowed"
+				} else if !o.inMaintenanceWindow(o.policy.Defaults.MaintenanceWindows) {
+					result.RemediationSkipped = true
+					result.RemediationSkipReason = "outside_maintenance_window"
 				} else {
 					result.RemediationAttempted = true
 					if err := auditor.Fix(serviceResources, rule); err != nil {
@@ -386,14 +732,13 @@ func (o *Orchestrator) runCompositeAudits() {
 						result.RemediationErrorKind = audit.ErrorKindRemediation
 					} else {
 						result.Remediated = true
+						o.progress.AddRemediated(service, "composite")
 					}
 				}
 			}
 
-			select {
-			case <-o.ctx.Done():
+			if !o.send(result) {
 				return
-			case o.resultsChan <- result:
 			}
 		}
 	}
@@ -454,10 +799,21 @@ func (o *Orchestrator) emitCompositeError(rule *policy.CompositeRule, err error)
 			ActionTagName: rule.ActionTagName,
 		},
 	}
+	o.send(result)
+}
+
+// send stamps result with the session's cloud and region and delivers it.
+// It returns false when the run was cancelled.
+func (o *Orchestrator) send(result *audit.Result) bool {
+	if o.session != nil {
+		result.Cloud = o.session.CloudName
+		result.Region = o.session.Region
+	}
 	select {
 	case <-o.ctx.Done():
-		return
+		return false
 	case o.resultsChan <- result:
+		return true
 	}
 }
 
@@ -2,14 +2,21 @@ package orchestrator_test
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/approval"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/auth"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/orchestrator"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/progress"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/remediate"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/rollout"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/services"
 	"github.com/gophercloud/gophercloud"
 )
@@ -17,11 +24,12 @@ import (
 type fakeDiscoverer struct {
 	service string
 	resType string
+	err     error // reported after the resource
 }
 
 func (d *fakeDiscoverer) ResourceType() string { return d.resType }
 func (d *fakeDiscoverer) Discover(ctx context.Context, _ *gophercloud.ServiceClient, _ bool) (<-chan discovery.Job, error) {
-	ch := make(chan discovery.Job, 1)
+	ch := make(chan discovery.Job, 2)
 	ch <- discovery.Job{
 		Service:      d.service,
 		ResourceType: d.resType,
@@ -29,6 +37,9 @@ func (d *fakeDiscoverer) Discover(ctx context.Context, _ *gophercloud.ServiceCli
 		Resource:     map[string]any{"id": "id-1"},
 		ProjectID:    "proj-1",
 	}
+	if d.err != nil {
+		discovery.Fail(ctx, ch, d.service, d.resType, d.err)
+	}
 	close(ch)
 	return ch, nil
 }
@@ -131,4 +142,416 @@ func TestOrchestrator_Run_ProducesResultAndCanRemediate(t *testing.T) {
 	if !aud.fixed {
 		t.Fatalf("expected auditor.Fix to be called in apply mode")
 	}
+
+	for range results {
+	}
+	snapshot := o.Progress().Snapshot()
+	if snapshot.Phase != progress.PhaseIdle || snapshot.FinishedAt == nil {
+		t.Fatalf("progress phase = %q, finished %v; want idle", snapshot.Phase, snapshot.FinishedAt)
+	}
+	want := progress.Counts{Discovered: 1, Queued: 1, Processed: 1, Audited: 1, Remediated: 1}
+	if total := snapshot.Total(); total != want {
+		t.Fatalf("progress = %+v, want %+v", total, want)
+	}
+}
+
+type fakeResourceRemediator struct {
+	action string
+	jobs   []discovery.Job
+}
+
+func (r *fakeResourceRemediator) Action() string { return r.action }
+func (r *fakeResourceRemediator) Execute(context.Context, interface{}, interface{}, *policy.Rule) error {
+	return nil
+}
+func (r *fakeResourceRemediator) Remediate(_ context.Context, _ remediate.ClientProvider, job discovery.Job, _ *policy.Rule) (map[string]string, error) {
+	r.jobs = append(r.jobs, job)
+	return map[string]string{"handled_by": "remediator"}, nil
+}
+
+func TestOrchestrator_Run_UsesResourceRemediator(t *testing.T) {
+	const (
+		svc = "orchestrator-test-remediator-svc"
+		res = "thing"
+	)
+
+	services.RegisterResource(svc, res)
+
+	aud := &fakeAuditor{resType: res}
+	disc := &fakeDiscoverer{service: svc, resType: res}
+	if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: aud}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+
+	// The validator only accepts known actions, so the fake takes the place
+	// of the real quarantine remediator for the duration of the test.
+	if prev, err := remediate.Get("quarantine"); err == nil {
+		t.Cleanup(func() { remediate.Register(prev) })
+	}
+	rem := &fakeResourceRemediator{action: "quarantine"}
+	remediate.Register(rem)
+
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:     "quarantine-things",
+				Service:  svc,
+				Resource: res,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "quarantine",
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, true, false)
+	results, err := o.Run()
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	var got []*audit.Result
+	for r := range results {
+		got = append(got, r)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	if aud.fixed {
+		t.Fatalf("auditor.Fix called for an action handled by a ResourceRemediator")
+	}
+	if len(rem.jobs) != 1 || rem.jobs[0].ResourceID != "id-1" {
+		t.Fatalf("remediator jobs = %+v, want one job for id-1", rem.jobs)
+	}
+	if !got[0].Remediated || got[0].RemediationDetails["handled_by"] != "remediator" {
+		t.Fatalf("result = %+v, want remediated with details", got[0])
+	}
+}
+
+func TestOrchestrator_Run_FailedPreActionAbortsDelete(t *testing.T) {
+	const (
+		svc = "orchestrator-test-preaction-svc"
+		res = "thing"
+	)
+
+	services.RegisterResource(svc, res)
+
+	aud := &fakeAuditor{resType: res}
+	disc := &fakeDiscoverer{service: svc, resType: res}
+	if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: aud}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+
+	// Validation would reject pre_action on this resource; the orchestrator
+	// must still refuse to delete when the snapshot cannot be taken.
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:      "safe-delete-things",
+				Service:   svc,
+				Resource:  res,
+				Check:     policy.CheckConditions{Status: "active"},
+				Action:    "delete",
+				PreAction: policy.PreActionSnapshot,
+			}},
+		}},
+	}
+
+	o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, true, false)
+	results, err := o.Run()
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	var got []*audit.Result
+	for r := range results {
+		got = append(got, r)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d results, want 1", len(got))
+	}
+	if aud.fixed {
+		t.Fatalf("auditor.Fix called although the pre_action failed")
+	}
+	if got[0].Remediated || got[0].RemediationError == nil || !strings.Contains(got[0].RemediationError.Error(), "delete aborted") {
+		t.Fatalf("result = %+v, want remediation error with aborted delete", got[0])
+	}
+}
+
+func TestOrchestrator_Run_ApprovalRequired(t *testing.T) {
+	const (
+		svc = "orchestrator-test-approval-svc"
+		res = "thing"
+	)
+
+	services.RegisterResource(svc, res)
+
+	aud := &fakeAuditor{resType: res}
+	disc := &fakeDiscoverer{service: svc, resType: res}
+	if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: aud}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:     "delete-things",
+				Service:  svc,
+				Resource: res,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "delete",
+				Approval: policy.ApprovalRequired,
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	store, err := approval.Open(filepath.Join(t.TempDir(), "approvals.json"))
+	if err != nil {
+		t.Fatalf("approval.Open() = %v", err)
+	}
+
+	run := func() *audit.Result {
+		t.Helper()
+		o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, true, false)
+		o.SetApprovalStore(store)
+		results, err := o.Run()
+		if err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+		var got []*audit.Result
+		for r := range results {
+			got = append(got, r)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d results, want 1", len(got))
+		}
+		return got[0]
+	}
+
+	first := run()
+	if aud.fixed || first.RemediationSkipReason != "awaiting_approval" {
+		t.Fatalf("first run: fixed=%v result=%+v, want skipped awaiting_approval", aud.fixed, first)
+	}
+	pending := store.List(approval.StatePending)
+	if len(pending) != 1 || pending[0].ResourceID != "id-1" || pending[0].Action != "delete" {
+		t.Fatalf("pending approvals = %+v, want one for id-1", pending)
+	}
+	// The run writes its requests when it ends.
+	written, err := approval.Open(store.Path())
+	if err != nil {
+		t.Fatalf("approval.Open() = %v", err)
+	}
+	if got := written.List(approval.StatePending); len(got) != 1 {
+		t.Fatalf("approval store file after the run holds %+v, want the pending request", got)
+	}
+
+	if err := store.Approve(pending[0].ID, "reviewer", ""); err != nil {
+		t.Fatalf("Approve() = %v", err)
+	}
+
+	second := run()
+	if !aud.fixed || !second.Remediated {
+		t.Fatalf("second run: fixed=%v result=%+v, want remediated", aud.fixed, second)
+	}
+	if item, _ := store.Get(pending[0].ID); item.State != approval.StateExecuted {
+		t.Fatalf("approval state = %q, want executed", item.State)
+	}
+}
+
+func TestOrchestrator_Run_MaintenanceWindow(t *testing.T) {
+	const (
+		svc = "orchestrator-test-window-svc"
+		res = "thing"
+	)
+
+	services.RegisterResource(svc, res)
+
+	aud := &fakeAuditor{resType: res}
+	disc := &fakeDiscoverer{service: svc, resType: res}
+	if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: aud}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+
+	p := &policy.Policy{
+		Version: "v1",
+		Defaults: policy.Defaults{
+			MaintenanceWindows: []policy.MaintenanceWindow{{Days: []string{"sat"}, Start: "02:00", End: "04:00"}},
+		},
+		Policies: []policy.ServicePolicy{{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:     "delete-things",
+				Service:  svc,
+				Resource: res,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "delete",
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	run := func(now time.Time) *audit.Result {
+		t.Helper()
+		o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, true, false)
+		o.SetClock(func() time.Time { return now })
+		results, err := o.Run()
+		if err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+		var got []*audit.Result
+		for r := range results {
+			got = append(got, r)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d results, want 1", len(got))
+		}
+		return got[0]
+	}
+
+	// 2026-03-06 is a Friday, 2026-03-07 a Saturday.
+	outside := run(time.Date(2026, 3, 6, 3, 0, 0, 0, time.UTC))
+	if aud.fixed || outside.RemediationSkipReason != "outside_maintenance_window" {
+		t.Fatalf("outside window: fixed=%v result=%+v, want skipped", aud.fixed, outside)
+	}
+
+	inside := run(time.Date(2026, 3, 7, 3, 0, 0, 0, time.UTC))
+	if !aud.fixed || !inside.Remediated {
+		t.Fatalf("inside window: fixed=%v result=%+v, want remediated", aud.fixed, inside)
+	}
+}
+
+func TestOrchestrator_Run_RolloutStage(t *testing.T) {
+	const (
+		svc = "orchestrator-test-rollout-svc"
+		res = "thing"
+	)
+
+	services.RegisterResource(svc, res)
+
+	aud := &fakeAuditor{resType: res}
+	disc := &fakeDiscoverer{service: svc, resType: res}
+	if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: aud}); err != nil {
+		t.Fatalf("services.Register() = %v", err)
+	}
+
+	// The fake discoverer reports project proj-1, which joins in stage two.
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:     "delete-things",
+				Service:  svc,
+				Resource: res,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "delete",
+				Rollout: &policy.RolloutOptions{Stages: []policy.RolloutStage{
+					{Projects: []string{"canary"}, MinSoak: "1d"},
+					{Projects: []string{"proj-1"}},
+				}},
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	state, err := rollout.Open(filepath.Join(t.TempDir(), "rollout.json"))
+	if err != nil {
+		t.Fatalf("rollout.Open() = %v", err)
+	}
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	run := func(now time.Time) *audit.Result {
+		t.Helper()
+		o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, true, false)
+		o.SetRolloutState(state)
+		o.SetClock(func() time.Time { return now })
+		results, err := o.Run()
+		if err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+		var got []*audit.Result
+		for r := range results {
+			got = append(got, r)
+		}
+		if len(got) != 1 {
+			t.Fatalf("got %d results, want 1", len(got))
+		}
+		return got[0]
+	}
+
+	canary := run(start)
+	if aud.fixed || canary.RemediationSkipReason != "outside_rollout_stage" {
+		t.Fatalf("canary stage: fixed=%v result=%+v, want skipped", aud.fixed, canary)
+	}
+
+	widened := run(start.Add(25 * time.Hour))
+	if !aud.fixed || !widened.Remediated {
+		t.Fatalf("second stage: fixed=%v result=%+v, want remediated", aud.fixed, widened)
+	}
+}
+
+func TestOrchestrator_Run_DiscoveredSkipsFailedDiscovery(t *testing.T) {
+	const res = "thing"
+	p := &policy.Policy{Version: "v1"}
+	for _, svc := range []string{"discovered-ok-svc", "discovered-failing-svc"} {
+		services.RegisterResource(svc, res)
+		disc := &fakeDiscoverer{service: svc, resType: res}
+		if svc == "discovered-failing-svc" {
+			disc.err = errors.New("list failed")
+		}
+		if err := services.Register(&fakeService{name: svc, resType: res, disc: disc, aud: &fakeAuditor{resType: res}}); err != nil {
+			t.Fatalf("services.Register() = %v", err)
+		}
+		p.Policies = append(p.Policies, policy.ServicePolicy{
+			Service: svc,
+			Rules: []policy.Rule{{
+				Name:     svc + "-rule",
+				Service:  svc,
+				Resource: res,
+				Check:    policy.CheckConditions{Status: "active"},
+				Action:   "log",
+			}},
+		})
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("policy.Validate() = %v", err)
+	}
+
+	o := orchestrator.NewOrchestrator(p, &auth.Session{CloudName: "test"}, 1, false, false)
+	defer o.Stop()
+	results, err := o.Run()
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	count := 0
+	for range results {
+		count++
+	}
+	// The resources sent before the failure are still audited.
+	if count != 2 {
+		t.Fatalf("results = %d, want 2", count)
+	}
+	if got := o.Discovered(); len(got) != 1 || got[0] != "discovered-ok-svc/thing" {
+		t.Fatalf("Discovered() = %v, want [discovered-ok-svc/thing]", got)
+	}
+
+	o.Stop()
+	if got := o.Discovered(); got != nil {
+		t.Errorf("Discovered() after cancellation = %v, want none", got)
+	}
 }
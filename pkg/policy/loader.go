@@ -2,18 +2,19 @@ package policy
 
 import (
 	"fmt"
-	"os"
 
 	"gopkg.in/yaml.v2"
 )
 
-// Load reads and parses a policy YAML file
-func Load(path string) (*Policy, error) {
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read policy file: %w", err)
-	}
+// Load reads, merges and validates the policy documents at source: a YAML
+// file, a directory of YAML files, a glob pattern or an HTTP(S) URL. See
+// Loader.Load.
+func Load(source string) (*Policy, error) {
+	return defaultLoader.Load(source)
+}
 
+// parse parses one policy document without validating it.
+func parse(b []byte) (*Policy, error) {
 	// First, unmarshal into a map to handle the service-keyed structure
 	var raw map[string]interface{}
 	if err := yaml.Unmarshal(b, &raw); err != nil {
@@ -101,9 +102,5 @@ func Load(path string) (*Policy, error) {
 		}
 	}
 
-	if err := p.Validate(); err != nil {
-		return nil, err
-	}
-
 	return &p, nil
 }
@@ -93,3 +93,42 @@ policies:
 		t.Fatalf("Load() error = %q, want contains %q", err.Error(), "duplicate rule name")
 	}
 }
+
+func TestLoad_OutputsAndNotificationFilters(t *testing.T) {
+	path := writeTempPolicy(t, `version: v1
+defaults:
+  outputs:
+    - format: json
+      path: all.json
+    - format: csv
+      path: cost.csv
+      filter:
+        categories: [cost]
+policies:
+  - nova:
+    - name: old-instances
+      resource: instance
+      check:
+        age_gt: "30d"
+      action: log
+notifications:
+  - name: sec
+    type: slack
+    url: https://hooks.example.com/x
+    filter:
+      severities: [critical]
+      include_errors: true
+`)
+
+	p, err := policy.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(p.Defaults.Outputs) != 2 || p.Defaults.Outputs[1].Filter.Categories[0] != "cost" {
+		t.Fatalf("Defaults.Outputs = %+v", p.Defaults.Outputs)
+	}
+	f := p.Notifications[0].Filter
+	if len(f.Severities) != 1 || f.Severities[0] != "critical" || !f.IncludeErrors {
+		t.Fatalf("Notifications[0].Filter = %+v", f)
+	}
+}
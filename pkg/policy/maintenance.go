@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // resolve window timezones on hosts without zoneinfo
+)
+
+// MaintenanceWindow is a recurring weekly time range during which
+// remediation may run. Start and End are "HH:MM" in Timezone (default UTC).
+// A window whose End is not after Start runs past midnight into the next
+// day; Days name the day the window starts. Start equal to End covers the
+// whole day.
+type MaintenanceWindow struct {
+	Days     []string `yaml:"days,omitempty"`
+	Start    string   `yaml:"start"`
+	End      string   `yaml:"end"`
+	Timezone string   `yaml:"timezone,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Contains reports whether t falls inside the window.
+func (w MaintenanceWindow) Contains(t time.Time) (bool, error) {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false, fmt.Errorf("start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false, fmt.Errorf("end: %w", err)
+	}
+	loc := time.UTC
+	if w.Timezone != "" {
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return false, fmt.Errorf("timezone: %w", err)
+		}
+	}
+	days := make(map[time.Weekday]bool, len(w.Days))
+	for _, d := range w.Days {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]
+		if !ok {
+			return false, fmt.Errorf("invalid day %q", d)
+		}
+		days[day] = true
+	}
+	onDay := func(d time.Weekday) bool { return len(days) == 0 || days[d] }
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	yesterday := (today + 6) % 7
+
+	switch {
+	case start == end:
+		return onDay(today), nil
+	case start < end:
+		return onDay(today) && minute >= start && minute < end, nil
+	default:
+		return (onDay(today) && minute >= start) || (onDay(yesterday) && minute < end), nil
+	}
+}
+
+// InMaintenanceWindow reports whether t falls inside any of windows. No
+// windows means remediation is always allowed.
+func InMaintenanceWindow(windows []MaintenanceWindow, t time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+	for i, w := range windows {
+		ok, err := w.Contains(t)
+		if err != nil {
+			return false, fmt.Errorf("maintenance_windows[%d]: %w", i, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EffectiveMaintenanceWindows returns the rule's windows, falling back to
+// the policy defaults.
+func (p *Policy) EffectiveMaintenanceWindows(rule *Rule) []MaintenanceWindow {
+	if rule != nil && len(rule.MaintenanceWindows) > 0 {
+		return rule.MaintenanceWindows
+	}
+	return p.Defaults.MaintenanceWindows
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func validateMaintenanceWindows(windows []MaintenanceWindow, field string) error {
+	for i, w := range windows {
+		// Evaluating a window checks every field.
+		if _, err := w.Contains(time.Time{}); err != nil {
+			return fmt.Errorf("%s[%d]: %w", field, i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow_Contains(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		t      time.Time
+		want   bool
+	}{
+		{"inside same-day range", MaintenanceWindow{Start: "09:00", End: "17:00"}, at(4, 12, 0), true},
+		{"end is exclusive", MaintenanceWindow{Start: "09:00", End: "17:00"}, at(4, 17, 0), false},
+		{"weekday matches", MaintenanceWindow{Days: []string{"wed"}, Start: "09:00", End: "17:00"}, at(4, 9, 0), true},
+		{"weekday does not match", MaintenanceWindow{Days: []string{"Saturday", "sun"}, Start: "09:00", End: "17:00"}, at(4, 12, 0), false},
+		{"overnight before midnight", MaintenanceWindow{Days: []string{"wed"}, Start: "22:00", End: "04:00"}, at(4, 23, 30), true},
+		{"overnight after midnight belongs to start day", MaintenanceWindow{Days: []string{"wed"}, Start: "22:00", End: "04:00"}, at(5, 3, 59), true},
+		{"overnight after midnight wrong start day", MaintenanceWindow{Days: []string{"thu"}, Start: "22:00", End: "04:00"}, at(5, 3, 0), false},
+		{"whole day", MaintenanceWindow{Days: []string{"wed"}, Start: "00:00", End: "00:00"}, at(4, 8, 0), true},
+		// 23:30 UTC on Wednesday is 00:30 Thursday in Berlin (UTC+1 in March).
+		{"timezone shifts day", MaintenanceWindow{Days: []string{"thu"}, Start: "00:00", End: "02:00", Timezone: "Europe/Berlin"}, at(4, 23, 30), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.Contains(tt.t)
+			if err != nil {
+				t.Fatalf("Contains() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInMaintenanceWindow_NoWindowsAlwaysOpen(t *testing.T) {
+	ok, err := InMaintenanceWindow(nil, time.Now())
+	if err != nil || !ok {
+		t.Fatalf("InMaintenanceWindow(nil) = %v, %v; want true, nil", ok, err)
+	}
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	for _, w := range []MaintenanceWindow{
+		{Start: "25:00", End: "01:00"},
+		{Start: "01:00", End: "2am"},
+		{Days: []string{"someday"}, Start: "01:00", End: "02:00"},
+		{Start: "01:00", End: "02:00", Timezone: "Mars/Olympus"},
+	} {
+		if err := validateMaintenanceWindows([]MaintenanceWindow{{Start: "01:00", End: "02:00"}, w}, "maintenance_windows"); err == nil {
+			t.Errorf("validateMaintenanceWindows(%+v) expected error", w)
+		}
+	}
+}
+
+func TestEffectiveMaintenanceWindows_RuleOverridesDefaults(t *testing.T) {
+	defaults := []MaintenanceWindow{{Start: "22:00", End: "06:00"}}
+	p := &Policy{Defaults: Defaults{MaintenanceWindows: defaults}}
+
+	if got := p.EffectiveMaintenanceWindows(&Rule{}); len(got) != 1 || got[0].Start != "22:00" {
+		t.Errorf("rule without windows = %+v, want defaults", got)
+	}
+	own := &Rule{MaintenanceWindows: []MaintenanceWindow{{Start: "10:00", End: "11:00"}}}
+	if got := p.EffectiveMaintenanceWindows(own); len(got) != 1 || got[0].Start != "10:00" {
+		t.Errorf("rule with windows = %+v, want rule windows", got)
+	}
+}
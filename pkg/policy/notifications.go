@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Notification destination types.
+const (
+	NotificationWebhook    = "webhook"
+	NotificationSlack      = "slack"
+	NotificationMattermost = "mattermost"
+	NotificationTeams      = "teams"
+)
+
+var notificationTypes = map[string]bool{
+	NotificationWebhook:    true,
+	NotificationSlack:      true,
+	NotificationMattermost: true,
+	NotificationTeams:      true,
+}
+
+// Notification sends matching findings to a chat channel or HTTP endpoint
+// (see pkg/notify). URL, Headers and Secret may reference environment
+// variables as ${VAR} so credentials stay out of the policy file.
+type Notification struct {
+	Name string `yaml:"name"`
+	// Type is webhook (generic JSON), slack, mattermost or teams.
+	Type    string            `yaml:"type"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	Filter NotificationFilter `yaml:"filter,omitempty"`
+
+	// Template is a Go text/template for the message. For chat types it
+	// renders the message text; for webhook it renders the whole request
+	// body. It is parsed by pkg/notify, which documents the template data.
+	Template string `yaml:"template,omitempty"`
+
+	// BatchSize groups up to this many findings per message (default 1).
+	BatchSize int `yaml:"batch_size,omitempty"`
+	// BatchInterval flushes a partial batch after this long (e.g. "30s").
+	BatchInterval string `yaml:"batch_interval,omitempty"`
+	// Digest sends a single message with every matching finding at the
+	// end of the run instead of batching.
+	Digest bool `yaml:"digest,omitempty"`
+
+	// Secret signs each request body with HMAC-SHA256 in the
+	// X-OSPA-Signature header.
+	Secret string `yaml:"secret,omitempty"`
+	// Retries is how many times a failed delivery is retried (default 3).
+	Retries *int `yaml:"retries,omitempty"`
+	// Timeout bounds each delivery attempt (default "10s").
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+// NotificationFilter selects the findings sent to a destination. Audit
+// errors are only sent with IncludeErrors.
+type NotificationFilter struct {
+	FindingFilter `yaml:",inline"`
+	IncludeErrors bool `yaml:"include_errors,omitempty"`
+}
+
+// BatchIntervalDuration parses BatchInterval.
+func (n Notification) BatchIntervalDuration() (time.Duration, error) {
+	return parseOptionalGoDuration(n.BatchInterval)
+}
+
+// TimeoutDuration parses Timeout.
+func (n Notification) TimeoutDuration() (time.Duration, error) {
+	return parseOptionalGoDuration(n.Timeout)
+}
+
+// RedactedValue replaces credentials in a policy shown by Redacted.
+const RedactedValue = "<redacted>"
+
+// Redacted returns a copy of p safe to show to others: notification URLs
+// keep only their scheme and host, and header values and secrets are
+// replaced by RedactedValue. Webhook URLs often embed their credential.
+func (p *Policy) Redacted() *Policy {
+	out := *p
+	out.Notifications = nil
+	for _, n := range p.Notifications {
+		n.URL = redactURL(n.URL)
+		if len(n.Headers) > 0 {
+			headers := make(map[string]string, len(n.Headers))
+			for k := range n.Headers {
+				headers[k] = RedactedValue
+			}
+			n.Headers = headers
+		}
+		if n.Secret != "" {
+			n.Secret = RedactedValue
+		}
+		out.Notifications = append(out.Notifications, n)
+	}
+	return &out
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return RedactedValue
+	}
+	return u.Scheme + "://" + u.Host + "/" + RedactedValue
+}
+
+func parseOptionalGoDuration(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return d, nil
+}
+
+func validateNotifications(notifications []Notification) error {
+	seen := make(map[string]bool, len(notifications))
+	for i, n := range notifications {
+		field := fmt.Sprintf("notifications[%d]", i)
+		if n.Name == "" {
+			return fmt.Errorf("%s: name is required", field)
+		}
+		field = fmt.Sprintf("notification %q", n.Name)
+		if seen[n.Name] {
+			return fmt.Errorf("duplicate notification name %q", n.Name)
+		}
+		seen[n.Name] = true
+
+		if !notificationTypes[strings.ToLower(n.Type)] {
+			return fmt.Errorf("%s: unsupported type %q (supported: webhook, slack, mattermost, teams)", field, n.Type)
+		}
+		if n.URL == "" {
+			return fmt.Errorf("%s: url is required", field)
+		}
+		if err := n.Filter.validate(); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if n.BatchSize < 0 {
+			return fmt.Errorf("%s: batch_size must not be negative", field)
+		}
+		if n.Digest && (n.BatchSize > 0 || n.BatchInterval != "") {
+			return fmt.Errorf("%s: digest cannot be combined with batch_size or batch_interval", field)
+		}
+		if _, err := n.BatchIntervalDuration(); err != nil {
+			return fmt.Errorf("%s: invalid batch_interval %q: %w", field, n.BatchInterval, err)
+		}
+		if _, err := n.TimeoutDuration(); err != nil {
+			return fmt.Errorf("%s: invalid timeout %q: %w", field, n.Timeout, err)
+		}
+		if n.Retries != nil && *n.Retries < 0 {
+			return fmt.Errorf("%s: retries must not be negative", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output is an additional findings writer configured in defaults.outputs.
+type Output struct {
+	// Format is any --out-format value (json, csv, sarif, ...).
+	Format string `yaml:"format"`
+	// Path is the file to write; "-" writes to stdout.
+	Path   string        `yaml:"path"`
+	Filter FindingFilter `yaml:"filter,omitempty"`
+}
+
+// FindingFilter selects findings by rule severity, category and service.
+// Empty lists match everything.
+type FindingFilter struct {
+	Severities []string `yaml:"severities,omitempty"`
+	Categories []string `yaml:"categories,omitempty"`
+	Services   []string `yaml:"services,omitempty"`
+}
+
+// IsEmpty reports whether the filter matches everything.
+func (f FindingFilter) IsEmpty() bool {
+	return len(f.Severities) == 0 && len(f.Categories) == 0 && len(f.Services) == 0
+}
+
+// Match reports whether a finding with these attributes passes the filter.
+func (f FindingFilter) Match(severity, category, service string) bool {
+	return matchAny(f.Severities, severity) &&
+		matchAny(f.Categories, category) &&
+		matchAny(f.Services, service)
+}
+
+func matchAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f FindingFilter) validate() error {
+	for _, s := range f.Severities {
+		if !validSeverities[strings.ToLower(s)] {
+			return fmt.Errorf("invalid filter severity %q", s)
+		}
+	}
+	for _, c := range f.Categories {
+		if !validCategories[strings.ToLower(c)] {
+			return fmt.Errorf("invalid filter category %q", c)
+		}
+	}
+	return nil
+}
+
+// Validate checks an output's fields. The format itself is checked when the
+// writer is created.
+func (o Output) Validate() error {
+	if strings.TrimSpace(o.Format) == "" {
+		return fmt.Errorf("format is required")
+	}
+	if strings.TrimSpace(o.Path) == "" {
+		return fmt.Errorf("path is required")
+	}
+	return o.Filter.validate()
+}
+
+func validateOutputs(outputs []Output) error {
+	for i, o := range outputs {
+		if err := o.Validate(); err != nil {
+			return fmt.Errorf("defaults.outputs[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package policy
+
+import "fmt"
+
+// Override changes a rule defined in another document, typically a built-in
+// pack, without copying it. Overrides are applied by the loader after all
+// documents are merged.
+type Override struct {
+	// Rule is the name of the rule or composite rule to change.
+	Rule string `yaml:"rule"`
+	// Disabled removes the rule from the policy.
+	Disabled bool   `yaml:"disabled,omitempty"`
+	Severity string `yaml:"severity,omitempty"`
+	Action   string `yaml:"action,omitempty"`
+	// TagName sets tag_name, for an action changed to tag or untag.
+	TagName string `yaml:"tag_name,omitempty"`
+}
+
+// applyOverrides applies overrides to the rules and composite rules of p.
+// The changed rules are validated with the rest of the policy afterwards.
+func (p *Policy) applyOverrides(overrides []Override) error {
+	for i, o := range overrides {
+		if o.Rule == "" {
+			return fmt.Errorf("overrides[%d]: rule is required", i)
+		}
+		if !o.Disabled && o.Severity == "" && o.Action == "" && o.TagName == "" {
+			return fmt.Errorf("overrides[%d] (%s): set disabled, severity, action or tag_name", i, o.Rule)
+		}
+		if !p.overrideRule(o) && !p.overrideComposite(o) {
+			return fmt.Errorf("overrides[%d]: unknown rule %q", i, o.Rule)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) overrideRule(o Override) bool {
+	for i := range p.Policies {
+		sp := &p.Policies[i]
+		for j := range sp.Rules {
+			r := &sp.Rules[j]
+			if r.Name != o.Rule {
+				continue
+			}
+			if o.Disabled {
+				sp.Rules = append(sp.Rules[:j], sp.Rules[j+1:]...)
+				if len(sp.Rules) == 0 {
+					p.Policies = append(p.Policies[:i], p.Policies[i+1:]...)
+				}
+				return true
+			}
+			override(&r.Severity, o.Severity)
+			override(&r.Action, o.Action)
+			override(&r.TagName, o.TagName)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) overrideComposite(o Override) bool {
+	for i := range p.Composites {
+		cp := &p.Composites[i]
+		for j := range cp.Rules {
+			r := &cp.Rules[j]
+			if r.Name != o.Rule {
+				continue
+			}
+			if o.Disabled {
+				cp.Rules = append(cp.Rules[:j], cp.Rules[j+1:]...)
+				if len(cp.Rules) == 0 {
+					p.Composites = append(p.Composites[:i], p.Composites[i+1:]...)
+				}
+				return true
+			}
+			override(&r.Severity, o.Severity)
+			override(&r.Action, o.Action)
+			override(&r.TagName, o.TagName)
+			return true
+		}
+	}
+	return false
+}
+
+func override(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
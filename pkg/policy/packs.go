@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// packPrefix marks a built-in pack as a policy source, e.g. in include:.
+const packPrefix = "pack:"
+
+//go:embed packs/*/*.yaml
+var packFiles embed.FS
+
+// PackInfo describes a built-in policy pack.
+type PackInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Ref returns the pack reference name@version.
+func (p PackInfo) Ref() string {
+	return p.Name + "@" + p.Version
+}
+
+// packCatalog lists the built-in packs. Each version is embedded from
+// packs/<name>/<version>.yaml; a published version is never changed, a new
+// one is added instead.
+var packCatalog = []PackInfo{
+	{
+		Name:        "security-baseline",
+		Version:     "v1",
+		Description: "High-impact misconfigurations: SSH/RDP open to the world, unused security groups",
+	},
+	{
+		Name:        "cost-optimization",
+		Version:     "v1",
+		Description: "Idle, orphaned and over-provisioned resources that waste cloud spend",
+	},
+	{
+		Name:        "openstack-security-guide",
+		Version:     "v1",
+		Description: "API-auditable checks of the OpenStack Security Guide and OSSN advisories",
+	},
+}
+
+// Packs returns the built-in packs sorted by name and version.
+func Packs() []PackInfo {
+	out := append([]PackInfo(nil), packCatalog...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return versionLess(out[i].Version, out[j].Version)
+	})
+	return out
+}
+
+// Pack returns the pack named by ref, name@version or just name for its
+// latest version, and its policy document.
+func Pack(ref string) (PackInfo, []byte, error) {
+	name, version, _ := strings.Cut(strings.TrimPrefix(ref, packPrefix), "@")
+	var found *PackInfo
+	var versions []string
+	for _, info := range Packs() {
+		if info.Name != name {
+			continue
+		}
+		versions = append(versions, info.Version)
+		if version == "" || info.Version == version {
+			info := info
+			found = &info
+		}
+	}
+	if len(versions) == 0 {
+		return PackInfo{}, nil, fmt.Errorf("unknown policy pack %q (available: %s)", name, packNames())
+	}
+	if found == nil {
+		return PackInfo{}, nil, fmt.Errorf("policy pack %s has no version %q (available: %s)", name, version, strings.Join(versions, ", "))
+	}
+	data, err := packFiles.ReadFile("packs/" + found.Name + "/" + found.Version + ".yaml")
+	if err != nil {
+		return PackInfo{}, nil, fmt.Errorf("read policy pack %s: %w", found.Ref(), err)
+	}
+	return *found, data, nil
+}
+
+// LoadPack loads and validates a single built-in pack.
+func LoadPack(ref string) (*Policy, error) {
+	l := NewLoader()
+	l.Packs = []string{ref}
+	return l.Load("")
+}
+
+// packDocument returns the document of a built-in pack, named pack:name@version.
+func packDocument(ref string) (document, error) {
+	info, data, err := Pack(ref)
+	if err != nil {
+		return document{}, err
+	}
+	return document{name: packPrefix + info.Ref(), data: data}, nil
+}
+
+func packNames() string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, info := range Packs() {
+		if !seen[info.Name] {
+			seen[info.Name] = true
+			names = append(names, info.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// versionLess orders versions such as v2 before v10.
+func versionLess(a, b string) bool {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "v"))
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return na < nb
+}
@@ -0,0 +1,170 @@
+package policy_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/services"
+)
+
+func TestPacks_LoadAndValidate(t *testing.T) {
+	packs := policy.Packs()
+	if len(packs) == 0 {
+		t.Fatal("no built-in packs")
+	}
+	for _, info := range packs {
+		p, err := policy.LoadPack(info.Ref())
+		if err != nil {
+			t.Errorf("LoadPack(%s) error = %v", info.Ref(), err)
+			continue
+		}
+		if len(p.RuleNames()) == 0 {
+			t.Errorf("pack %s has no rules", info.Ref())
+		}
+		if p.Defaults.Workers != 0 || p.Defaults.Output != "" {
+			t.Errorf("pack %s sets defaults %+v; packs must leave them to the user", info.Ref(), p.Defaults)
+		}
+	}
+
+	// All packs can be combined.
+	l := policy.NewLoader()
+	for _, info := range packs {
+		l.Packs = append(l.Packs, info.Ref())
+	}
+	if _, err := l.Load(""); err != nil {
+		t.Fatalf("Load(all packs) error = %v", err)
+	}
+}
+
+// Packs only ship rules whose checks are evaluated by the resource's auditor.
+func TestPacks_UseImplementedChecks(t *testing.T) {
+	for _, info := range policy.Packs() {
+		p, err := policy.LoadPack(info.Ref())
+		if err != nil {
+			t.Fatalf("LoadPack(%s) error = %v", info.Ref(), err)
+		}
+		for _, r := range p.GetAllRules() {
+			svc, err := services.Get(r.Service)
+			if err != nil {
+				t.Fatalf("%s: rule %s: %v", info.Ref(), r.Name, err)
+			}
+			auditor, err := svc.GetResourceAuditor(r.Resource)
+			if err != nil {
+				t.Fatalf("%s: rule %s: %v", info.Ref(), r.Name, err)
+			}
+			implemented := make(map[string]bool)
+			for _, c := range auditor.ImplementedChecks() {
+				implemented[c] = true
+			}
+			for _, used := range r.Check.UsedChecks() {
+				if !implemented[used] {
+					t.Errorf("%s: rule %s uses check %s, which %s/%s does not implement", info.Ref(), r.Name, used, r.Service, r.Resource)
+				}
+			}
+		}
+	}
+}
+
+func TestPack_Refs(t *testing.T) {
+	info, _, err := policy.Pack("security-baseline")
+	if err != nil || info.Ref() != "security-baseline@v1" {
+		t.Fatalf("Pack(security-baseline) = %v, %v; want the latest version", info.Ref(), err)
+	}
+	if _, _, err := policy.Pack("security-baseline@v9"); err == nil || !strings.Contains(err.Error(), "available: v1") {
+		t.Errorf("Pack(unknown version) error = %v", err)
+	}
+	if _, _, err := policy.Pack("nope@v1"); err == nil || !strings.Contains(err.Error(), "unknown policy pack") {
+		t.Errorf("Pack(unknown) error = %v", err)
+	}
+}
+
+func TestLoad_PackWithOverrides(t *testing.T) {
+	local := writeFiles(t, map[string]string{"policy.yaml": `
+version: v1
+defaults:
+  workers: 8
+overrides:
+  - rule: nova-instance-active
+    disabled: true
+  - rule: critical-ssh-open-to-world
+    severity: high
+    action: delete
+`})
+	path := filepath.Join(local, "policy.yaml")
+
+	l := policy.NewLoader()
+	l.Packs = []string{"security-baseline@v1"}
+	p, err := l.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.Defaults.Workers != 8 || len(p.Overrides) != 0 {
+		t.Errorf("defaults = %+v, overrides = %v", p.Defaults, p.Overrides)
+	}
+	var ssh *policy.Rule
+	for _, r := range p.GetAllRules() {
+		r := r
+		switch r.Name {
+		case "nova-instance-active":
+			t.Error("disabled rule nova-instance-active is still loaded")
+		case "critical-ssh-open-to-world":
+			ssh = &r
+		}
+	}
+	if ssh == nil || ssh.Severity != "high" || ssh.Action != "delete" {
+		t.Errorf("critical-ssh-open-to-world = %+v, want severity high and action delete", ssh)
+	}
+	for _, sp := range p.Policies {
+		if sp.Service == "nova" {
+			t.Error("service nova without rules was not removed")
+		}
+	}
+
+	// The same through include:.
+	included := writeFiles(t, map[string]string{"policy.yaml": "include: [\"pack:security-baseline@v1\"]\n" + `
+version: v1
+overrides:
+  - rule: cleanup-unused-security-groups
+    action: tag
+    tag_name: ospa-unused
+`})
+	p, err = policy.Load(filepath.Join(included, "policy.yaml"))
+	if err != nil {
+		t.Fatalf("Load(include pack) error = %v", err)
+	}
+	var tagged *policy.Rule
+	for _, r := range p.GetAllRules() {
+		r := r
+		if r.Name == "cleanup-unused-security-groups" {
+			tagged = &r
+		}
+	}
+	if tagged == nil || tagged.Action != "tag" || tagged.TagName != "ospa-unused" {
+		t.Errorf("cleanup-unused-security-groups = %+v, want action tag", tagged)
+	}
+}
+
+func TestLoad_OverrideErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		want     string
+	}{
+		{"unknown rule", "  - rule: no-such-rule\n    disabled: true\n", `policy.yaml: overrides[0]: unknown rule "no-such-rule"`},
+		{"nothing to change", "  - rule: cleanup-unused-security-groups\n", "set disabled, severity, action or tag_name"},
+		{"invalid action", "  - rule: cleanup-unused-security-groups\n    action: explode\n", "explode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"policy.yaml": "version: v1\noverrides:\n" + tt.override})
+			l := policy.NewLoader()
+			l.Packs = []string{"security-baseline"}
+			_, err := l.Load(filepath.Join(dir, "policy.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
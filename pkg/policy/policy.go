@@ -11,6 +11,13 @@ type Policy struct {
 	Defaults   Defaults                 `yaml:"defaults"`
 	Policies   []ServicePolicy          `yaml:"policies"`
 	Composites []CompositeServicePolicy `yaml:"composites,omitempty"`
+
+	// Notifications push matching findings to webhooks and chat channels.
+	Notifications []Notification `yaml:"notifications,omitempty"`
+
+	// Overrides change rules of other documents, such as built-in packs.
+	// The loader applies and clears them.
+	Overrides []Override `yaml:"overrides,omitempty"`
 }
 
 // Defaults contains default configuration values
@@ -18,6 +25,14 @@ type Defaults struct {
 	Workers int    `yaml:"workers"`
 	Days    int    `yaml:"days"`
 	Output  string `yaml:"output"`
+
+	// Outputs are additional findings writers, each with its own format and
+	// filter. Ignored when --out or --output is given.
+	Outputs []Output `yaml:"outputs,omitempty"`
+
+	// MaintenanceWindows limits remediation to these windows for rules that
+	// do not set their own. Empty means remediation may run at any time.
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
 }
 
 // ServicePolicy groups rules by OpenStack service
@@ -61,6 +76,77 @@ type Rule struct {
 	GuideRef      string          `yaml:"guide_ref,omitempty"`
 	ActionTagName string          `yaml:"action_tag_name,omitempty"`
 	TagName       string          `yaml:"tag_name,omitempty"`
+
+	// Quarantine configures the quarantine action. Optional; defaults apply
+	// when the action is quarantine and this block is omitted.
+	Quarantine *QuarantineOptions `yaml:"quarantine,omitempty"`
+
+	// Restrict configures the restrict action for security group rules.
+	Restrict *RestrictOptions `yaml:"restrict,omitempty"`
+
+	// PreAction runs before the action and aborts it on failure. The only
+	// supported value is PreActionSnapshot, for delete on cinder/volume and
+	// nova/instance.
+	PreAction string `yaml:"pre_action,omitempty"`
+
+	// Approval set to ApprovalRequired holds remediation until a reviewer
+	// approves it (see pkg/approval).
+	Approval string `yaml:"approval,omitempty"`
+
+	// MaintenanceWindows overrides defaults.maintenance_windows for this rule.
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
+
+	// Rollout limits remediation to the projects of the active rollout stage.
+	Rollout *RolloutOptions `yaml:"rollout,omitempty"`
+}
+
+// ApprovalRequired makes a rule's remediation wait for reviewer approval.
+const ApprovalRequired = "required"
+
+// PreActionSnapshot backs up a volume or images a server before it is
+// deleted.
+const PreActionSnapshot = "snapshot"
+
+// QuarantineOptions configures the quarantine action.
+type QuarantineOptions struct {
+	// SecurityGroup is the name of the per-project quarantine security group.
+	// It is created (with no rules) on first use. Default: "ospa-quarantine".
+	SecurityGroup string `yaml:"security_group,omitempty"`
+
+	// LockServer locks the Nova server owning the quarantined port(s) so
+	// project members cannot undo the quarantine.
+	LockServer bool `yaml:"lock_server,omitempty"`
+}
+
+// RestrictOptions configures the restrict action, which replaces an overly
+// permissive security group rule with equivalent rules scoped to an
+// allowlist of CIDRs.
+type RestrictOptions struct {
+	// AllowedCIDRs is the allowlist used for every project.
+	AllowedCIDRs []string `yaml:"allowed_cidrs,omitempty"`
+
+	// ProjectCIDRs overrides AllowedCIDRs for specific project IDs.
+	ProjectCIDRs map[string][]string `yaml:"project_cidrs,omitempty"`
+}
+
+// CIDRsFor returns the allowlist that applies to projectID.
+func (r *RestrictOptions) CIDRsFor(projectID string) []string {
+	if r == nil {
+		return nil
+	}
+	if cidrs, ok := r.ProjectCIDRs[projectID]; ok {
+		return cidrs
+	}
+	return r.AllowedCIDRs
+}
+
+// EffectiveTagName returns the tag used by the tag and untag actions.
+// tag_name takes precedence; action_tag_name is accepted as an alias.
+func (r *Rule) EffectiveTagName() string {
+	if r.TagName != "" {
+		return r.TagName
+	}
+	return r.ActionTagName
 }
 
 // CompositeRule represents a rule that evaluates multiple resource types together.
@@ -90,6 +176,7 @@ type CheckConditions struct {
 	AgeGT          string         `yaml:"age_gt,omitempty"`
 	Unused         bool           `yaml:"unused,omitempty"`
 	ExemptNames    []string       `yaml:"exempt_names,omitempty"`
+	ExemptTags     []string       `yaml:"exempt_tags,omitempty"`
 	ExemptMetadata *MetadataMatch `yaml:"exempt_metadata,omitempty"`
 
 	// --- Neutron checks ---
@@ -148,6 +235,9 @@ func (c *CheckConditions) UsedChecks() []string {
 	if len(c.ExemptNames) > 0 {
 		used = append(used, "exempt_names")
 	}
+	if len(c.ExemptTags) > 0 {
+		used = append(used, "exempt_tags")
+	}
 	if c.ExemptMetadata != nil {
 		used = append(used, "exempt_metadata")
 	}
@@ -225,14 +315,19 @@ func (c *CheckConditions) ParseAgeGT() (time.Duration, error) {
 	if c.AgeGT == "" {
 		return 0, nil
 	}
+	return parseDuration("age_gt", c.AgeGT)
+}
 
+// parseDuration parses durations such as "30d", "12h" or "15m". field names
+// the policy field in error messages.
+func parseDuration(field, raw string) (time.Duration, error) {
 	var duration time.Duration
 	var unit string
 	var value int
 
-	_, err := fmt.Sscanf(c.AgeGT, "%d%s", &value, &unit)
+	_, err := fmt.Sscanf(raw, "%d%s", &value, &unit)
 	if err != nil {
-		return 0, fmt.Errorf("invalid age_gt format %q: %w", c.AgeGT, err)
+		return 0, fmt.Errorf("invalid %s format %q: %w", field, raw, err)
 	}
 
 	switch unit {
@@ -243,7 +338,7 @@ func (c *CheckConditions) ParseAgeGT() (time.Duration, error) {
 	case "m", "min", "minute", "minutes":
 		duration = time.Duration(value) * time.Minute
 	default:
-		return 0, fmt.Errorf("unsupported age_gt unit %q (supported: d, h, m)", unit)
+		return 0, fmt.Errorf("unsupported %s unit %q (supported: d, h, m)", field, unit)
 	}
 
 	return duration, nil
@@ -275,6 +370,30 @@ func (p *Policy) GetAllRules() []Rule {
 	return allRules
 }
 
+// RequiresApproval reports whether any rule has approval: required.
+func (p *Policy) RequiresApproval() bool {
+	for _, sp := range p.Policies {
+		for _, rule := range sp.Rules {
+			if rule.Approval == ApprovalRequired {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasRollouts reports whether any rule has a rollout block.
+func (p *Policy) HasRollouts() bool {
+	for _, sp := range p.Policies {
+		for _, rule := range sp.Rules {
+			if rule.Rollout != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetAllCompositeRules returns all composite rules from all composite service policies.
 func (p *Policy) GetAllCompositeRules() []CompositeRule {
 	var allRules []CompositeRule
@@ -288,3 +407,64 @@ func (p *Policy) GetAllCompositeRules() []CompositeRule {
 	}
 	return allRules
 }
+
+// Scoped returns a copy of the policy limited to the rules of service and,
+// when resourceType is set, to rules on that resource type. Composite rules
+// are kept if they cover the resource type. Empty arguments match
+// everything.
+func (p *Policy) Scoped(service, resourceType string) *Policy {
+	scoped := *p
+	scoped.Policies = nil
+	scoped.Composites = nil
+
+	for _, sp := range p.Policies {
+		if service != "" && sp.Service != service {
+			continue
+		}
+		var rules []Rule
+		for _, rule := range sp.Rules {
+			if resourceType == "" || rule.Resource == resourceType {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) > 0 {
+			scoped.Policies = append(scoped.Policies, ServicePolicy{Service: sp.Service, Rules: rules})
+		}
+	}
+	for _, sp := range p.Composites {
+		if service != "" && sp.Service != service {
+			continue
+		}
+		var rules []CompositeRule
+		for _, rule := range sp.Rules {
+			if resourceType == "" || containsString(rule.Resources, resourceType) {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) > 0 {
+			scoped.Composites = append(scoped.Composites, CompositeServicePolicy{Service: sp.Service, Rules: rules})
+		}
+	}
+	return &scoped
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleNames returns the names of all rules and composite rules.
+func (p *Policy) RuleNames() []string {
+	var names []string
+	for _, rule := range p.GetAllRules() {
+		names = append(names, rule.Name)
+	}
+	for _, rule := range p.GetAllCompositeRules() {
+		names = append(names, rule.Name)
+	}
+	return names
+}
@@ -0,0 +1,52 @@
+package policy
+
+import "testing"
+
+func TestPolicy_Scoped(t *testing.T) {
+	p := &Policy{
+		Version: "v1",
+		Policies: []ServicePolicy{
+			{Service: "nova", Rules: []Rule{{Name: "shutoff", Resource: "instance"}}},
+			{Service: "neutron", Rules: []Rule{
+				{Name: "open-ssh", Resource: "security_group_rule"},
+				{Name: "unused-fip", Resource: "floating_ip"},
+			}},
+		},
+		Composites: []CompositeServicePolicy{
+			{Service: "neutron", Rules: []CompositeRule{{Name: "orphans", Resources: []string{"floating_ip", "port"}}}},
+		},
+	}
+
+	all := p.Scoped("", "")
+	if len(all.GetAllRules()) != 3 || len(all.GetAllCompositeRules()) != 1 {
+		t.Fatalf("unscoped copy lost rules: %+v", all)
+	}
+
+	neutron := p.Scoped("neutron", "")
+	if got := len(neutron.GetAllRules()); got != 2 {
+		t.Fatalf("neutron rules = %d, want 2", got)
+	}
+
+	fip := p.Scoped("neutron", "floating_ip")
+	rules := fip.GetAllRules()
+	if len(rules) != 1 || rules[0].Name != "unused-fip" {
+		t.Fatalf("floating_ip rules = %+v", rules)
+	}
+	if len(fip.GetAllCompositeRules()) != 1 {
+		t.Fatal("composite covering floating_ip should be kept")
+	}
+
+	sg := p.Scoped("neutron", "security_group_rule")
+	if len(sg.GetAllCompositeRules()) != 0 {
+		t.Fatal("composite not covering security_group_rule should be dropped")
+	}
+	if len(p.Scoped("cinder", "").GetAllRules()) != 0 {
+		t.Fatal("unknown service should have no rules")
+	}
+	if got := fip.RuleNames(); len(got) != 2 || got[0] != "unused-fip" || got[1] != "orphans" {
+		t.Fatalf("RuleNames() = %v", got)
+	}
+	if len(p.Policies) != 2 {
+		t.Fatal("Scoped must not modify the original policy")
+	}
+}
@@ -0,0 +1,469 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Keys of the policy document resolved by the loader before the policy is
+// decoded and validated.
+const (
+	keyInclude   = "include"
+	keyTemplates = "templates"
+	keyBaseRules = "base_rules"
+	keyTemplate  = "template"
+	keyParams    = "params"
+	keyExtends   = "extends"
+)
+
+// placeholder matches a template parameter reference such as {{port}}.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// includeList returns the include entries of a document.
+func includeList(doc document) ([]string, error) {
+	var head struct {
+		Include []string `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(doc.data, &head); err != nil {
+		return nil, fmt.Errorf("%s: parse policy yaml: %w", doc.name, err)
+	}
+	return head.Include, nil
+}
+
+// includePath resolves an include entry relative to the document that
+// includes it: a path relative to a file's directory, or a reference
+// relative to a URL.
+func includePath(from, include string) (string, error) {
+	if isURL(include) || strings.HasPrefix(include, packPrefix) {
+		return include, nil
+	}
+	if isURL(from) {
+		base, err := url.Parse(from)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(include)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	if filepath.IsAbs(include) {
+		return include, nil
+	}
+	return filepath.Join(filepath.Dir(from), include), nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// expandIncludes returns docs with the documents they include inserted
+// before them, depth first. A document included several times is read once;
+// an include cycle is an error.
+func (l *Loader) expandIncludes(docs []document, stack []string, seen map[string]bool) ([]document, error) {
+	var out []document
+	for _, doc := range docs {
+		for _, name := range stack {
+			if name == doc.name {
+				return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), doc.name)
+			}
+		}
+		if seen[doc.name] {
+			continue
+		}
+		seen[doc.name] = true
+
+		includes, err := includeList(doc)
+		if err != nil {
+			return nil, err
+		}
+		for _, inc := range includes {
+			source, err := includePath(doc.name, inc)
+			if err != nil {
+				return nil, fmt.Errorf("%s: include %q: %w", doc.name, inc, err)
+			}
+			included, err := l.documents(source)
+			if err != nil {
+				return nil, fmt.Errorf("%s: include %q: %w", doc.name, inc, err)
+			}
+			expanded, err := l.expandIncludes(included, append(stack, doc.name), seen)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, expanded...)
+		}
+		out = append(out, doc)
+	}
+	return out, nil
+}
+
+// rawDoc is a policy document decoded into generic YAML values.
+type rawDoc struct {
+	doc  document
+	data map[interface{}]interface{}
+	// resolve is set when the document uses templates, extends or
+	// directives that must be removed before decoding.
+	resolve bool
+}
+
+// ruleRef is a named rule and the document defining it.
+type ruleRef struct {
+	file string
+	data map[interface{}]interface{}
+}
+
+// template is a parameterised rule.
+type template struct {
+	file   string
+	params []string
+	rule   map[interface{}]interface{}
+}
+
+// resolver expands templates and extends across all documents.
+type resolver struct {
+	templates map[string]template
+	bases     map[string]ruleRef // base_rules, not evaluated themselves
+	rules     map[string]ruleRef // rules of the policies sections
+	resolved  map[string]map[interface{}]interface{}
+	visiting  map[string]bool
+}
+
+// resolveDocuments expands the templates and extends of docs and removes
+// the loader directives, so that the documents decode into plain policies.
+// Documents without any of them are returned unchanged.
+func resolveDocuments(docs []document) ([]document, error) {
+	raws := make([]*rawDoc, 0, len(docs))
+	needed := false
+	for _, doc := range docs {
+		var data map[interface{}]interface{}
+		if err := yaml.Unmarshal(doc.data, &data); err != nil {
+			return nil, fmt.Errorf("%s: parse policy yaml: %w", doc.name, err)
+		}
+		raw := &rawDoc{doc: doc, data: data}
+		for _, key := range []string{keyInclude, keyTemplates, keyBaseRules} {
+			if _, ok := data[key]; ok {
+				raw.resolve = true
+			}
+		}
+		forEachRule(data, func(_ string, rule map[interface{}]interface{}) {
+			if rule[keyTemplate] != nil || rule[keyExtends] != nil {
+				raw.resolve = true
+			}
+		})
+		needed = needed || raw.resolve
+		raws = append(raws, raw)
+	}
+	if !needed {
+		return docs, nil
+	}
+
+	r := &resolver{
+		templates: make(map[string]template),
+		bases:     make(map[string]ruleRef),
+		rules:     make(map[string]ruleRef),
+		resolved:  make(map[string]map[interface{}]interface{}),
+		visiting:  make(map[string]bool),
+	}
+	for _, raw := range raws {
+		if err := r.collect(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]document, len(raws))
+	for i, raw := range raws {
+		if !raw.resolve {
+			out[i] = raw.doc
+			continue
+		}
+		var err error
+		forEachRule(raw.data, func(_ string, rule map[interface{}]interface{}) {
+			if err != nil || (rule[keyTemplate] == nil && rule[keyExtends] == nil) {
+				return
+			}
+			name, _ := rule["name"].(string)
+			var expanded map[interface{}]interface{}
+			if expanded, err = r.resolve(name, raw.doc.name, rule); err != nil {
+				return
+			}
+			for k := range rule {
+				delete(rule, k)
+			}
+			for k, v := range expanded {
+				rule[k] = v
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		delete(raw.data, keyInclude)
+		delete(raw.data, keyTemplates)
+		delete(raw.data, keyBaseRules)
+		b, err := yaml.Marshal(raw.data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", raw.doc.name, err)
+		}
+		out[i] = document{name: raw.doc.name, data: b}
+	}
+	return out, nil
+}
+
+// forEachRule calls fn for every rule of the service-keyed policies section.
+func forEachRule(data map[interface{}]interface{}, fn func(service string, rule map[interface{}]interface{})) {
+	policies, _ := data["policies"].([]interface{})
+	for _, entry := range policies {
+		services, _ := entry.(map[interface{}]interface{})
+		for service, rules := range services {
+			list, _ := rules.([]interface{})
+			for _, item := range list {
+				if rule, ok := item.(map[interface{}]interface{}); ok {
+					fn(fmt.Sprint(service), rule)
+				}
+			}
+		}
+	}
+}
+
+// collect registers the templates, base rules and rules of a document.
+func (r *resolver) collect(raw *rawDoc) error {
+	file := raw.doc.name
+
+	templates, _ := raw.data[keyTemplates].([]interface{})
+	for i, item := range templates {
+		def, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return fmt.Errorf("%s: templates[%d]: must be a mapping", file, i)
+		}
+		name, _ := def["name"].(string)
+		if name == "" {
+			return fmt.Errorf("%s: templates[%d]: name is required", file, i)
+		}
+		if prev, ok := r.templates[name]; ok {
+			return fmt.Errorf("%s: template %q is already defined in %s", file, name, prev.file)
+		}
+		rule, ok := def["rule"].(map[interface{}]interface{})
+		if !ok {
+			return fmt.Errorf("%s: template %q: rule is required", file, name)
+		}
+		var params []string
+		list, _ := def[keyParams].([]interface{})
+		for _, p := range list {
+			params = append(params, fmt.Sprint(p))
+		}
+		r.templates[name] = template{file: file, params: params, rule: rule}
+	}
+
+	bases, _ := raw.data[keyBaseRules].([]interface{})
+	for i, item := range bases {
+		def, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return fmt.Errorf("%s: base_rules[%d]: must be a mapping", file, i)
+		}
+		name, _ := def["name"].(string)
+		if name == "" {
+			return fmt.Errorf("%s: base_rules[%d]: name is required", file, i)
+		}
+		if prev, ok := r.bases[name]; ok {
+			return fmt.Errorf("%s: base rule %q is already defined in %s", file, name, prev.file)
+		}
+		r.bases[name] = ruleRef{file: file, data: def}
+	}
+
+	forEachRule(raw.data, func(_ string, rule map[interface{}]interface{}) {
+		if name, ok := rule["name"].(string); ok && name != "" {
+			if _, dup := r.rules[name]; !dup {
+				r.rules[name] = ruleRef{file: file, data: rule}
+			}
+		}
+	})
+	return nil
+}
+
+// resolve returns the rule with its template instantiated and its base rule
+// merged in. file is the document defining the rule.
+// Unnamed rules, e.g. template instances named by the template, cannot be
+// extended and are not cached.
+func (r *resolver) resolve(name, file string, rule map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+	if rule[keyTemplate] == nil && rule[keyExtends] == nil {
+		return rule, nil
+	}
+	key := file + "\x00" + name
+	if name != "" {
+		if out, ok := r.resolved[key]; ok {
+			return out, nil
+		}
+		if r.visiting[key] {
+			return nil, fmt.Errorf("%s: rule %q: extends cycle", file, name)
+		}
+		r.visiting[key] = true
+		defer delete(r.visiting, key)
+	}
+
+	own := make(map[interface{}]interface{}, len(rule))
+	for k, v := range rule {
+		own[k] = v
+	}
+
+	var base map[interface{}]interface{}
+	switch {
+	case own[keyTemplate] != nil && own[keyExtends] != nil:
+		return nil, fmt.Errorf("%s: rule %q: template and extends are mutually exclusive", file, name)
+
+	case own[keyTemplate] != nil:
+		tmplName := fmt.Sprint(own[keyTemplate])
+		tmpl, ok := r.templates[tmplName]
+		if !ok {
+			return nil, fmt.Errorf("%s: rule %q: unknown template %q (defined templates: %s)", file, name, tmplName, r.templateNames())
+		}
+		params, _ := own[keyParams].(map[interface{}]interface{})
+		instance, err := tmpl.instantiate(params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %q: template %q: %w", file, name, tmplName, err)
+		}
+		base = instance
+
+	default:
+		baseName := fmt.Sprint(own[keyExtends])
+		ref, ok := r.bases[baseName]
+		if !ok {
+			ref, ok = r.rules[baseName]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: rule %q: extends unknown rule %q", file, name, baseName)
+		}
+		if baseName == name && ref.file == file {
+			return nil, fmt.Errorf("%s: rule %q: a rule cannot extend itself", file, name)
+		}
+		resolvedBase, err := r.resolve(baseName, ref.file, ref.data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %q: %w", file, name, err)
+		}
+		base = resolvedBase
+	}
+
+	delete(own, keyTemplate)
+	delete(own, keyParams)
+	delete(own, keyExtends)
+	out := deepMerge(base, own)
+	if name != "" {
+		r.resolved[key] = out
+	}
+	return out, nil
+}
+
+// templateNames lists the defined templates for error messages.
+func (r *resolver) templateNames() string {
+	if len(r.templates) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// instantiate substitutes params into the template's rule.
+func (t template) instantiate(params map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+	values := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		values[fmt.Sprint(k)] = v
+	}
+	declared := make(map[string]bool, len(t.params))
+	for _, p := range t.params {
+		declared[p] = true
+		if _, ok := values[p]; !ok {
+			return nil, fmt.Errorf("missing parameter %q", p)
+		}
+	}
+	for p := range values {
+		if !declared[p] {
+			return nil, fmt.Errorf("unknown parameter %q (template parameters: %s)", p, strings.Join(t.params, ", "))
+		}
+	}
+
+	out, err := substitute(t.rule, values)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[interface{}]interface{}), nil
+}
+
+// substitute replaces {{param}} references in v. A string consisting of a
+// single reference takes the parameter's value and type, so "{{port}}"
+// becomes the integer 22.
+func substitute(v interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			s, err := substitute(item, values)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = s
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			s, err := substitute(item, values)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = s
+		}
+		return out, nil
+	case string:
+		if m := placeholder.FindStringSubmatch(v); m != nil && m[0] == strings.TrimSpace(v) {
+			value, ok := values[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("undeclared parameter %q", m[1])
+			}
+			return value, nil
+		}
+		var missing string
+		out := placeholder.ReplaceAllStringFunc(v, func(ref string) string {
+			name := placeholder.FindStringSubmatch(ref)[1]
+			value, ok := values[name]
+			if !ok {
+				missing = name
+				return ref
+			}
+			return fmt.Sprint(value)
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("undeclared parameter %q", missing)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// deepMerge returns base overridden by override. Nested mappings are merged
+// key by key; any other value in override replaces the base value.
+func deepMerge(base, override map[interface{}]interface{}) map[interface{}]interface{} {
+	out := make(map[interface{}]interface{}, len(base)+len(override))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseOK := out[k].(map[interface{}]interface{})
+		overrideMap, overrideOK := v.(map[interface{}]interface{})
+		if baseOK && overrideOK {
+			out[k] = deepMerge(baseMap, overrideMap)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package policy_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+const sgTemplates = `
+templates:
+  - name: sg-open-port
+    params: [port]
+    rule:
+      name: "open-port-{{port}}"
+      description: "Port {{ port }} open to the internet"
+      resource: security_group_rule
+      check:
+        direction: ingress
+        port: "{{port}}"
+        remote_ip_prefix: 0.0.0.0/0
+      action: log
+      severity: high
+`
+
+func TestLoad_TemplatesAndInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"templates.yaml": sgTemplates,
+		"policy.yaml": `
+version: v1
+include:
+  - templates.yaml
+policies:
+  - neutron:
+    - template: sg-open-port
+      params: {port: 22}
+    - template: sg-open-port
+      params: {port: 3389}
+      severity: critical
+    - template: sg-open-port
+      params: {port: 5432}
+`,
+	})
+
+	p, err := policy.Load(filepath.Join(dir, "policy.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rules := p.GetAllRules()
+	if len(rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(rules))
+	}
+	for i, port := range []int{22, 3389, 5432} {
+		r := rules[i]
+		if r.Check.Port != port || r.Check.RemoteIPPrefix != "0.0.0.0/0" {
+			t.Errorf("rule %d check = %+v, want port %d", i, r.Check, port)
+		}
+		if want := fmt.Sprintf("open-port-%d", port); r.Name != want {
+			t.Errorf("rule %d name = %q, want %q", i, r.Name, want)
+		}
+	}
+	if rules[0].Severity != "high" || rules[1].Severity != "critical" {
+		t.Errorf("severities = %q, %q; want the override on the second rule", rules[0].Severity, rules[1].Severity)
+	}
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"policy.yaml": `
+version: v1
+base_rules:
+  - name: old-resource
+    description: Resource older than 90 days
+    check:
+      age_gt: 90d
+      exempt_names: [keep-*]
+    action: log
+    severity: low
+policies:
+  - nova:
+    - name: old-instances
+      extends: old-resource
+      resource: instance
+      check:
+        age_gt: 30d
+  - cinder:
+    - name: old-volumes
+      extends: old-instances
+      resource: volume
+      severity: medium
+`,
+	})
+
+	p, err := policy.Load(filepath.Join(dir, "policy.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rules := p.GetAllRules()
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2 (base rules are not evaluated)", len(rules))
+	}
+	nova, cinder := rules[0], rules[1]
+	if nova.Check.AgeGT != "30d" || len(nova.Check.ExemptNames) != 1 || nova.Severity != "low" || nova.Action != "log" {
+		t.Errorf("old-instances = %+v, want base fields with age_gt overridden", nova)
+	}
+	if cinder.Resource != "volume" || cinder.Check.AgeGT != "30d" || cinder.Severity != "medium" {
+		t.Errorf("old-volumes = %+v, want old-instances with resource and severity overridden", cinder)
+	}
+}
+
+func TestLoad_ResolveErrorsNameTheFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"policy.yaml": "include: [a.yaml]\n" + novaTeam,
+				"a.yaml":      "include: [policy.yaml]\n",
+			},
+			want: "include cycle: ",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"policy.yaml": "include: [missing.yaml]\n" + novaTeam,
+			},
+			want: `policy.yaml: include "missing.yaml"`,
+		},
+		{
+			name: "unknown template",
+			files: map[string]string{
+				"templates.yaml": sgTemplates,
+				"policy.yaml": `
+include: [templates.yaml]
+policies:
+  - neutron:
+    - name: ssh
+      template: sg-open-ports
+      params: {port: 22}
+`,
+			},
+			want: `policy.yaml: rule "ssh": unknown template "sg-open-ports" (defined templates: sg-open-port)`,
+		},
+		{
+			name: "missing parameter",
+			files: map[string]string{
+				"policy.yaml": sgTemplates + `
+policies:
+  - neutron:
+    - name: ssh
+      template: sg-open-port
+`,
+			},
+			want: `policy.yaml: rule "ssh": template "sg-open-port": missing parameter "port"`,
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				"policy.yaml": `
+policies:
+  - nova:
+    - name: a
+      extends: b
+    - name: b
+      extends: a
+`,
+			},
+			want: "extends cycle",
+		},
+		{
+			name: "invalid rule in included file",
+			files: map[string]string{
+				"policy.yaml": "include: [broken.yaml]\n" + novaTeam,
+				"broken.yaml": `
+policies:
+  - nova:
+    - name: broken
+      resource: instance
+      check:
+        age_gt: 30d
+      action: explode
+`,
+			},
+			want: "broken.yaml: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			_, err := policy.Load(filepath.Join(dir, "policy.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
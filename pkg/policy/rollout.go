@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// RolloutOptions applies a rule's remediation to a growing set of projects.
+// Stages are cumulative: a project included in one stage stays included in
+// every later stage. Which stage is active is tracked outside the policy
+// (see pkg/rollout).
+type RolloutOptions struct {
+	Stages []RolloutStage `yaml:"stages"`
+}
+
+// RolloutStage selects projects by ID, by percentage, or both. Percentage
+// selection hashes the project ID, so a project is picked consistently
+// across runs and rules.
+type RolloutStage struct {
+	Name     string   `yaml:"name,omitempty"`
+	Projects []string `yaml:"projects,omitempty"`
+	Percent  int      `yaml:"percent,omitempty"`
+
+	// MinSoak is how long the stage stays active before the next stage
+	// starts (e.g. "3d"). Required for every stage except the last.
+	MinSoak string `yaml:"min_soak,omitempty"`
+}
+
+// SoakDuration parses MinSoak.
+func (s RolloutStage) SoakDuration() (time.Duration, error) {
+	if s.MinSoak == "" {
+		return 0, nil
+	}
+	return parseDuration("min_soak", s.MinSoak)
+}
+
+// Includes reports whether projectID is covered once stage (0-based) is
+// active. A stage beyond the last one is treated as the last stage.
+func (r *RolloutOptions) Includes(stage int, projectID string) bool {
+	if r == nil || len(r.Stages) == 0 {
+		return true
+	}
+	if stage >= len(r.Stages) {
+		stage = len(r.Stages) - 1
+	}
+	bucket := projectBucket(projectID)
+	for _, s := range r.Stages[:stage+1] {
+		if projectID != "" && bucket < s.Percent {
+			return true
+		}
+		if s.Percent >= 100 {
+			return true
+		}
+		for _, p := range s.Projects {
+			if p == projectID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// projectBucket maps a project ID to a stable bucket in [0, 100).
+func projectBucket(projectID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(projectID))
+	return int(h.Sum32() % 100)
+}
+
+func validateRollout(r *RolloutOptions, ruleName string) error {
+	if r == nil {
+		return nil
+	}
+	if len(r.Stages) == 0 {
+		return fmt.Errorf("rule %q: rollout.stages must contain at least one stage", ruleName)
+	}
+	for i, s := range r.Stages {
+		if len(s.Projects) == 0 && s.Percent == 0 {
+			return fmt.Errorf("rule %q: rollout.stages[%d] must set projects or percent", ruleName, i)
+		}
+		if s.Percent < 0 || s.Percent > 100 {
+			return fmt.Errorf("rule %q: rollout.stages[%d].percent must be between 0 and 100", ruleName, i)
+		}
+		if _, err := s.SoakDuration(); err != nil {
+			return fmt.Errorf("rule %q: rollout.stages[%d]: %w", ruleName, i, err)
+		}
+		if s.MinSoak == "" && i < len(r.Stages)-1 {
+			return fmt.Errorf("rule %q: rollout.stages[%d].min_soak is required except on the last stage", ruleName, i)
+		}
+	}
+	return nil
+}
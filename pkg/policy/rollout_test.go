@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRolloutOptions_IncludesIsCumulative(t *testing.T) {
+	r := &RolloutOptions{Stages: []RolloutStage{
+		{Projects: []string{"canary"}, MinSoak: "1d"},
+		{Projects: []string{"wave-2"}, MinSoak: "2d"},
+		{Percent: 100},
+	}}
+
+	tests := []struct {
+		stage   int
+		project string
+		want    bool
+	}{
+		{0, "canary", true},
+		{0, "wave-2", false},
+		{1, "canary", true},
+		{1, "wave-2", true},
+		{1, "other", false},
+		{2, "other", true},
+		{7, "other", true},
+	}
+	for _, tt := range tests {
+		if got := r.Includes(tt.stage, tt.project); got != tt.want {
+			t.Errorf("Includes(%d, %q) = %v, want %v", tt.stage, tt.project, got, tt.want)
+		}
+	}
+}
+
+func TestRolloutOptions_PercentIsStable(t *testing.T) {
+	r := &RolloutOptions{Stages: []RolloutStage{{Percent: 30}}}
+
+	included := 0
+	for i := 0; i < 1000; i++ {
+		project := fmt.Sprintf("project-%d", i)
+		first := r.Includes(0, project)
+		if first != r.Includes(0, project) {
+			t.Fatalf("Includes(%q) is not stable", project)
+		}
+		if first {
+			included++
+		}
+	}
+	if included < 200 || included > 400 {
+		t.Errorf("30%% stage included %d of 1000 projects", included)
+	}
+	if r.Includes(0, "") {
+		t.Error("project-less resources should only be included at 100%")
+	}
+}
+
+func TestValidateRollout(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout *RolloutOptions
+		wantErr bool
+	}{
+		{"valid", &RolloutOptions{Stages: []RolloutStage{{Projects: []string{"p1"}, MinSoak: "3d"}, {Percent: 100}}}, false},
+		{"no stages", &RolloutOptions{}, true},
+		{"empty stage", &RolloutOptions{Stages: []RolloutStage{{MinSoak: "1d"}, {Percent: 100}}}, true},
+		{"percent out of range", &RolloutOptions{Stages: []RolloutStage{{Percent: 150}}}, true},
+		{"missing soak", &RolloutOptions{Stages: []RolloutStage{{Percent: 10}, {Percent: 100}}}, true},
+		{"bad soak", &RolloutOptions{Stages: []RolloutStage{{Percent: 10, MinSoak: "3w"}, {Percent: 100}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRollout(tt.rollout, "r1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRollout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRemoteSize limits the size of a policy fetched over HTTP.
+const maxRemoteSize = 10 << 20
+
+var defaultLoader = NewLoader()
+
+// document is one policy file as read from its source.
+type document struct {
+	name string
+	data []byte
+}
+
+// cachedResponse is the last successful response for a policy URL.
+type cachedResponse struct {
+	etag string
+	data []byte
+}
+
+// Loader loads policies from files, directories, glob patterns, HTTP(S)
+// URLs and built-in packs. Responses of URLs that send an ETag are cached, so reloading an
+// unchanged remote policy costs a conditional request. A Loader is safe for
+// concurrent use.
+type Loader struct {
+	// Client fetches policy URLs.
+	Client *http.Client
+	// Packs are built-in packs (name@version) loaded before the source.
+	Packs []string
+
+	mu         sync.Mutex
+	cache      map[string]cachedResponse
+	lastDigest string
+}
+
+// NewLoader returns a loader with an HTTP client that times out after 30
+// seconds.
+func NewLoader() *Loader {
+	return &Loader{
+		Client: &http.Client{Timeout: 30 * time.Second},
+		cache:  make(map[string]cachedResponse),
+	}
+}
+
+// Load reads every policy document of source, merges them and validates the
+// result. source is one of:
+//
+//   - an http:// or https:// URL,
+//   - a glob pattern such as policies/*.yaml,
+//   - a directory, whose *.yaml and *.yml files are read in name order,
+//   - a single file,
+//   - pack:name@version, a built-in pack (see Packs).
+//
+// Packs listed in l.Packs are loaded before source, which may then be empty.
+// Documents listed under include: are read before the including document.
+// Templates and extends are then resolved across all documents. Rules,
+// composites, notifications and outputs of all documents are combined.
+// Scalar settings (version, workers, days, output) may be set by several
+// documents only if they agree. Overrides are applied last.
+func (l *Loader) Load(source string) (*Policy, error) {
+	docs, err := l.read(source)
+	if err != nil {
+		return nil, err
+	}
+	return l.build(docs, digestOf(docs))
+}
+
+// LoadIfChanged is like Load but returns changed=false and a nil policy when
+// the documents are identical to those of the last successful load by l.
+func (l *Loader) LoadIfChanged(source string) (p *Policy, changed bool, err error) {
+	docs, err := l.read(source)
+	if err != nil {
+		return nil, false, err
+	}
+	digest := digestOf(docs)
+	l.mu.Lock()
+	unchanged := digest == l.lastDigest
+	l.mu.Unlock()
+	if unchanged {
+		return nil, false, nil
+	}
+	p, err = l.build(docs, digest)
+	if err != nil {
+		return nil, false, err
+	}
+	return p, true, nil
+}
+
+// read returns the documents of l.Packs and source and of everything they
+// include.
+func (l *Loader) read(source string) ([]document, error) {
+	var docs []document
+	for _, ref := range l.Packs {
+		doc, err := packDocument(ref)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	if source != "" {
+		more, err := l.documents(source)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, more...)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no policy source or pack given")
+	}
+	return l.expandIncludes(docs, nil, make(map[string]bool))
+}
+
+// build resolves, parses, merges and validates docs and records digest as
+// the last successful load.
+func (l *Loader) build(docs []document, digest string) (*Policy, error) {
+	docs, err := resolveDocuments(docs)
+	if err != nil {
+		return nil, err
+	}
+	parsed := make([]*Policy, len(docs))
+	for i, doc := range docs {
+		p, err := parse(doc.data)
+		if err != nil {
+			if len(docs) == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", doc.name, err)
+		}
+		parsed[i] = p
+	}
+	p, err := merge(docs, parsed)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range docs {
+		if err := p.applyOverrides(parsed[i].Overrides); err != nil {
+			if len(docs) == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", doc.name, err)
+		}
+	}
+	p.Overrides = nil
+	if err := p.Validate(); err != nil {
+		if len(docs) == 1 {
+			return nil, err
+		}
+		// Point to the document with the invalid rule where possible.
+		for i, doc := range docs {
+			if err := validateDocument(parsed[i], p); err != nil {
+				return nil, fmt.Errorf("%s: %w", doc.name, err)
+			}
+		}
+		return nil, fmt.Errorf("merged policy from %s: %w", documentNames(docs), err)
+	}
+	l.mu.Lock()
+	l.lastDigest = digest
+	l.mu.Unlock()
+	return p, nil
+}
+
+// documents reads the policy documents of source.
+func (l *Loader) documents(source string) ([]document, error) {
+	if strings.HasPrefix(source, packPrefix) {
+		doc, err := packDocument(source)
+		if err != nil {
+			return nil, err
+		}
+		return []document{doc}, nil
+	}
+	if isURL(source) {
+		data, err := l.fetch(source)
+		if err != nil {
+			return nil, err
+		}
+		return []document{{name: source, data: data}}, nil
+	}
+
+	var paths []string
+	if strings.ContainsAny(source, "*?[") {
+		matches, err := filepath.Glob(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy pattern %q: %w", source, err)
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && !info.IsDir() {
+				paths = append(paths, m)
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no policy files match %q", source)
+		}
+	} else if info, err := os.Stat(source); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return nil, fmt.Errorf("read policy directory: %w", err)
+		}
+		for _, e := range entries {
+			ext := strings.ToLower(filepath.Ext(e.Name()))
+			if !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				paths = append(paths, filepath.Join(source, e.Name()))
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no *.yaml or *.yml files in policy directory %q", source)
+		}
+	} else {
+		paths = []string{source}
+	}
+	sort.Strings(paths)
+
+	docs := make([]document, 0, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read policy file: %w", err)
+		}
+		docs = append(docs, document{name: path, data: b})
+	}
+	return docs, nil
+}
+
+// fetch downloads a policy URL, revalidating a cached copy with its ETag.
+func (l *Loader) fetch(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy %s: %w", url, err)
+	}
+	l.mu.Lock()
+	cached, ok := l.cache[url]
+	l.mu.Unlock()
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := l.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return cached.data, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("fetch policy %s: unexpected status %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch policy %s: %w", url, err)
+	}
+	if len(data) > maxRemoteSize {
+		return nil, fmt.Errorf("fetch policy %s: larger than %d bytes", url, maxRemoteSize)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		l.mu.Lock()
+		l.cache[url] = cachedResponse{etag: etag, data: data}
+		l.mu.Unlock()
+	}
+	return data, nil
+}
+
+// merge combines the parsed documents into one policy.
+func merge(docs []document, parsed []*Policy) (*Policy, error) {
+	if len(parsed) == 1 {
+		return parsed[0], nil
+	}
+	merged := &Policy{}
+	origin := make(map[string]string) // setting -> document that set it
+	for i, p := range parsed {
+		if err := mergeScalars(merged, p, origin, docs[i].name); err != nil {
+			return nil, err
+		}
+		merged.Policies = append(merged.Policies, p.Policies...)
+		merged.Composites = append(merged.Composites, p.Composites...)
+		merged.Notifications = append(merged.Notifications, p.Notifications...)
+		merged.Defaults.Outputs = append(merged.Defaults.Outputs, p.Defaults.Outputs...)
+	}
+	return merged, nil
+}
+
+// validateDocument validates the rules of one document with the settings of
+// the merged policy. Documents without rules are not checked on their own.
+func validateDocument(doc, merged *Policy) error {
+	if len(doc.Policies) == 0 {
+		return nil
+	}
+	p := *merged
+	p.Policies = doc.Policies
+	p.Composites = doc.Composites
+	p.Notifications = doc.Notifications
+	return p.Validate()
+}
+
+// mergeScalars copies the scalar settings of p into merged, failing when two
+// documents set one to different values. The default maintenance windows are
+// treated as one setting: since windows are a union, combining them would
+// let one document widen the restriction another one relies on.
+func mergeScalars(merged, p *Policy, origin map[string]string, name string) error {
+	check := func(setting string, set, conflict bool, apply func()) error {
+		if !set {
+			return nil
+		}
+		if prev, ok := origin[setting]; ok {
+			if conflict {
+				return fmt.Errorf("%s: %s conflicts with the value set in %s", name, setting, prev)
+			}
+			return nil
+		}
+		apply()
+		origin[setting] = name
+		return nil
+	}
+	if err := check("version", p.Version != "", p.Version != merged.Version,
+		func() { merged.Version = p.Version }); err != nil {
+		return err
+	}
+	if err := check("defaults.workers", p.Defaults.Workers != 0, p.Defaults.Workers != merged.Defaults.Workers,
+		func() { merged.Defaults.Workers = p.Defaults.Workers }); err != nil {
+		return err
+	}
+	if err := check("defaults.days", p.Defaults.Days != 0, p.Defaults.Days != merged.Defaults.Days,
+		func() { merged.Defaults.Days = p.Defaults.Days }); err != nil {
+		return err
+	}
+	if err := check("defaults.maintenance_windows", len(p.Defaults.MaintenanceWindows) > 0,
+		!reflect.DeepEqual(p.Defaults.MaintenanceWindows, merged.Defaults.MaintenanceWindows),
+		func() { merged.Defaults.MaintenanceWindows = p.Defaults.MaintenanceWindows }); err != nil {
+		return err
+	}
+	return check("defaults.output", p.Defaults.Output != "", p.Defaults.Output != merged.Defaults.Output,
+		func() { merged.Defaults.Output = p.Defaults.Output })
+}
+
+func digestOf(docs []document) string {
+	h := sha256.New()
+	for _, doc := range docs {
+		fmt.Fprintf(h, "%s\x00%d\x00", doc.name, len(doc.data))
+		h.Write(doc.data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func documentNames(docs []document) string {
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		names[i] = doc.name
+	}
+	return strings.Join(names, ", ")
+}
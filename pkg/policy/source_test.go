@@ -0,0 +1,174 @@
+package policy_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+const (
+	novaTeam = `
+version: v1
+defaults:
+  workers: 4
+policies:
+  - nova:
+    - name: old-instances
+      resource: instance
+      check:
+        age_gt: "30d"
+      action: log
+`
+	neutronTeam = `
+version: v1
+policies:
+  - neutron:
+    - name: open-ssh
+      resource: security_group_rule
+      check:
+        direction: ingress
+        port: 22
+        remote_ip_prefix: 0.0.0.0/0
+      action: log
+`
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_DirectoryAndGlob(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"nova.yaml":   novaTeam,
+		"neutron.yml": neutronTeam,
+		"README.md":   "not a policy",
+	})
+
+	for _, source := range []string{dir, filepath.Join(dir, "*.y*ml")} {
+		p, err := policy.Load(source)
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", source, err)
+		}
+		if got := p.RuleNames(); len(got) != 2 || got[0] != "open-ssh" || got[1] != "old-instances" {
+			t.Errorf("Load(%s) rules = %v, want neutron.yml before nova.yaml", source, got)
+		}
+		if p.Defaults.Workers != 4 {
+			t.Errorf("Load(%s) workers = %d, want 4", source, p.Defaults.Workers)
+		}
+	}
+
+	if _, err := policy.Load(filepath.Join(dir, "*.json")); err == nil || !strings.Contains(err.Error(), "no policy files match") {
+		t.Errorf("Load(no match) error = %v", err)
+	}
+}
+
+func TestLoad_MergeErrorsNameTheFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": novaTeam,
+		"b.yaml": strings.Replace(neutronTeam, "policies:", "defaults:\n  workers: 8\npolicies:", 1),
+	})
+	_, err := policy.Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "b.yaml: defaults.workers conflicts with the value set in "+filepath.Join(dir, "a.yaml")) {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	dup := writeFiles(t, map[string]string{"a.yaml": novaTeam, "b.yaml": novaTeam})
+	if _, err := policy.Load(dup); err == nil || !strings.Contains(err.Error(), "merged policy from") {
+		t.Fatalf("Load(duplicate rules) error = %v", err)
+	}
+}
+
+func TestLoad_DefaultMaintenanceWindowsAreNotCombined(t *testing.T) {
+	withWindow := func(doc, window string) string {
+		return strings.Replace(doc, "policies:", "defaults:\n  maintenance_windows:\n    - "+window+"\npolicies:", 1)
+	}
+	saturday := `{days: [sat], start: "02:00", end: "04:00"}`
+	sunday := `{days: [sun], start: "02:00", end: "04:00"}`
+
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": withWindow(neutronTeam, saturday),
+		"b.yaml": withWindow(novaTeam, sunday),
+	})
+	_, err := policy.Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "b.yaml: defaults.maintenance_windows conflicts with the value set in "+filepath.Join(dir, "a.yaml")) {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	same := writeFiles(t, map[string]string{
+		"a.yaml": withWindow(neutronTeam, saturday),
+		"b.yaml": withWindow(novaTeam, saturday),
+	})
+	p, err := policy.Load(same)
+	if err != nil {
+		t.Fatalf("Load(same windows) error = %v", err)
+	}
+	if got := p.Defaults.MaintenanceWindows; len(got) != 1 || got[0].Days[0] != "sat" {
+		t.Errorf("merged windows = %+v, want the Saturday window once", got)
+	}
+}
+
+func TestLoader_URLWithETag(t *testing.T) {
+	var requests, notModified atomic.Int32
+	var current atomic.Value
+	setBody := func(s string) { current.Store(s) }
+	setBody(novaTeam)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body := current.Load().(string)
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(body)))
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	l := policy.NewLoader()
+	if _, err := l.Load(srv.URL); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p, changed, err := l.LoadIfChanged(srv.URL)
+	if err != nil || changed || p != nil {
+		t.Fatalf("LoadIfChanged() = %v, %v, %v; want unchanged", p, changed, err)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("cached copy not revalidated: %d requests, %d not modified", requests.Load(), notModified.Load())
+	}
+
+	// An invalid policy is rejected and does not count as loaded.
+	setBody("version: v1\npolicies: []\n")
+	if _, _, err := l.LoadIfChanged(srv.URL); err == nil {
+		t.Fatal("expected validation error")
+	}
+	setBody(neutronTeam)
+	p, changed, err = l.LoadIfChanged(srv.URL)
+	if err != nil || !changed || p.RuleNames()[0] != "open-ssh" {
+		t.Fatalf("LoadIfChanged() = %v, %v, %v; want the new policy", p, changed, err)
+	}
+}
+
+func TestLoader_URLErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if _, err := policy.NewLoader().Load(srv.URL + "/policy.yaml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Load() error = %v", err)
+	}
+}
@@ -36,3 +36,21 @@ func (v *CinderValidator) ValidateResource(check *policy.CheckConditions, resour
 
 	return nil
 }
+
+// cinderAllowedActions lists the remediation actions each cinder resource type supports.
+var cinderAllowedActions = map[string][]string{
+	"volume":   {"log", "delete", "detach"},
+	"snapshot": {"log", "delete", "tag"},
+}
+
+func (v *CinderValidator) ValidateAction(action, resourceType, ruleName string) error {
+	allowed, ok := cinderAllowedActions[resourceType]
+	if !ok {
+		// Unknown resource types are reported by ValidateResource.
+		return nil
+	}
+	if err := validateAllowedAction(action, allowed); err != nil {
+		return fmt.Errorf("rule %q: %w", ruleName, err)
+	}
+	return nil
+}
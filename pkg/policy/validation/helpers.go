@@ -53,6 +53,17 @@ func validateAllowedChecks(check *policy.CheckConditions, allowed []string) erro
 	return nil
 }
 
+// validateAllowedAction validates that action is one of the actions the
+// resource type supports.
+func validateAllowedAction(action string, allowed []string) error {
+	for _, name := range allowed {
+		if action == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("action %q is not supported for this resource (supported: %s)", action, strings.Join(allowed, ", "))
+}
+
 // getSetChecks returns the yaml tag names of all non-zero fields in CheckConditions.
 // This dynamically discovers which checks are set based on the struct definition.
 func getSetChecks(check *policy.CheckConditions) []string {
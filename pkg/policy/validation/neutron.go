@@ -21,12 +21,12 @@ func (v *NeutronValidator) ValidateResource(check *policy.CheckConditions, resou
 	switch resourceType {
 
 	case "network":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "shared_network"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags", "shared_network"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
 	case "security_group":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
@@ -36,22 +36,22 @@ func (v *NeutronValidator) ValidateResource(check *policy.CheckConditions, resou
 		}
 
 	case "floating_ip":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "unassociated", "exempt_names"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "unassociated", "exempt_names", "exempt_tags"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
 	case "subnet":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
 	case "port":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "no_security_group"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags", "no_security_group"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
 	case "router":
-		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names"}); err != nil {
+		if err := validateAllowedChecks(check, []string{"status", "age_gt", "unused", "exempt_names", "exempt_tags"}); err != nil {
 			return fmt.Errorf("rule %q: %w", ruleName, err)
 		}
 
@@ -61,3 +61,26 @@ func (v *NeutronValidator) ValidateResource(check *policy.CheckConditions, resou
 
 	return nil
 }
+
+// neutronAllowedActions lists the remediation actions each neutron resource type supports.
+var neutronAllowedActions = map[string][]string{
+	"network":             {"log", "delete", "tag", "untag", "disable"},
+	"security_group":      {"log", "delete", "tag", "untag"},
+	"security_group_rule": {"log", "delete", "restrict"}, // Neutron cannot tag rules
+	"floating_ip":         {"log", "delete", "tag", "untag", "disassociate"},
+	"subnet":              {"log", "delete", "tag", "untag"},
+	"port":                {"log", "delete", "tag", "untag", "disable", "quarantine"},
+	"router":              {"log", "delete", "tag", "untag", "disable"},
+}
+
+func (v *NeutronValidator) ValidateAction(action, resourceType, ruleName string) error {
+	allowed, ok := neutronAllowedActions[resourceType]
+	if !ok {
+		// Unknown resource types are reported by ValidateResource.
+		return nil
+	}
+	if err := validateAllowedAction(action, allowed); err != nil {
+		return fmt.Errorf("rule %q: %w", ruleName, err)
+	}
+	return nil
+}
@@ -36,3 +36,21 @@ func (v *NovaValidator) ValidateResource(check *policy.CheckConditions, resource
 
 	return nil
 }
+
+// novaAllowedActions lists the remediation actions each nova resource type supports.
+var novaAllowedActions = map[string][]string{
+	"instance": {"log", "delete", "stop", "shelve", "quarantine"},
+	"keypair":  {"log", "delete"},
+}
+
+func (v *NovaValidator) ValidateAction(action, resourceType, ruleName string) error {
+	allowed, ok := novaAllowedActions[resourceType]
+	if !ok {
+		// Unknown resource types are reported by ValidateResource.
+		return nil
+	}
+	if err := validateAllowedAction(action, allowed); err != nil {
+		return fmt.Errorf("rule %q: %w", ruleName, err)
+	}
+	return nil
+}
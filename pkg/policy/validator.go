@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/catalog"
@@ -17,6 +18,16 @@ func (p *Policy) Validate() error {
 		return fmt.Errorf("policy.policies must contain at least one service policy")
 	}
 
+	if err := validateMaintenanceWindows(p.Defaults.MaintenanceWindows, "defaults.maintenance_windows"); err != nil {
+		return err
+	}
+	if err := validateNotifications(p.Notifications); err != nil {
+		return err
+	}
+	if err := validateOutputs(p.Defaults.Outputs); err != nil {
+		return err
+	}
+
 	seenRuleNames := make(map[string]struct{})
 
 	// Dynamically discover supported services and resources from the registry
@@ -33,9 +44,26 @@ func (p *Policy) Validate() error {
 	}
 
 	supportedActions := map[string]bool{
+		"log":          true,
+		"delete":       true,
+		"tag":          true,
+		"untag":        true,
+		"quarantine":   true,
+		"restrict":     true,
+		"stop":         true,
+		"shelve":       true,
+		"disable":      true,
+		"detach":       true,
+		"disassociate": true,
+	}
+
+	// Composite rules act on a group of resources at once, so only actions
+	// that make sense for every member are accepted.
+	compositeActions := map[string]bool{
 		"log":    true,
 		"delete": true,
 		"tag":    true,
+		"untag":  true,
 	}
 
 	for i, sp := range p.Policies {
@@ -85,31 +113,53 @@ func (p *Policy) Validate() error {
 				return fmt.Errorf("rule %q: action is required", ruleName)
 			}
 			if !supportedActions[action] {
-				return fmt.Errorf("rule %q: unsupported action %q (supported: log, delete, tag)", ruleName, rule.Action)
+				return fmt.Errorf("rule %q: unsupported action %q (supported: log, delete, tag, untag, quarantine, restrict, stop, shelve, disable, detach, disassociate)", ruleName, rule.Action)
+			}
+			if err := validateAction(service, action, resource, ruleName); err != nil {
+				return err
 			}
 
 			// Validate action-specific fields
-			if action == "tag" {
-				if rule.TagName == "" {
-					return fmt.Errorf("rule %q: tag_name is required when action is 'tag'", ruleName)
+			if action == "tag" || action == "untag" {
+				if rule.EffectiveTagName() == "" {
+					return fmt.Errorf("rule %q: tag_name is required when action is '%s'", ruleName, action)
 				}
 			}
+			if action == "restrict" {
+				if err := validateRestrict(rule.Restrict, ruleName); err != nil {
+					return err
+				}
+			}
+			if rule.PreAction != "" {
+				if err := validatePreAction(rule.PreAction, action, service, resource, ruleName); err != nil {
+					return err
+				}
+			}
+			if rule.Approval != "" && rule.Approval != ApprovalRequired {
+				return fmt.Errorf("rule %q: unsupported approval %q (supported: %s)", ruleName, rule.Approval, ApprovalRequired)
+			}
+			if err := validateMaintenanceWindows(rule.MaintenanceWindows, "maintenance_windows"); err != nil {
+				return fmt.Errorf("rule %q: %w", ruleName, err)
+			}
+			if err := validateRollout(rule.Rollout, ruleName); err != nil {
+				return err
+			}
 
-		if !hasAnyConstraint(&rule.Check) {
-			return fmt.Errorf("rule %q: check must specify at least one condition", ruleName)
-		}
+			if !hasAnyConstraint(&rule.Check) {
+				return fmt.Errorf("rule %q: check must specify at least one condition", ruleName)
+			}
 
-		if err := validateSeverity(rule.Severity, ruleName); err != nil {
-			return err
-		}
-		if err := validateCategory(rule.Category, ruleName); err != nil {
-			return err
-		}
+			if err := validateSeverity(rule.Severity, ruleName); err != nil {
+				return err
+			}
+			if err := validateCategory(rule.Category, ruleName); err != nil {
+				return err
+			}
 
-		// Validate check conditions using service-specific validator
-		if err := validateCheckConditions(service, &rule.Check, resource, ruleName); err != nil {
-			return err
-		}
+			// Validate check conditions using service-specific validator
+			if err := validateCheckConditions(service, &rule.Check, resource, ruleName); err != nil {
+				return err
+			}
 
 			// Validate age_gt format if present
 			if rule.Check.AgeGT != "" {
@@ -165,11 +215,11 @@ func (p *Policy) Validate() error {
 			if action == "" {
 				return fmt.Errorf("rule %q: action is required", ruleName)
 			}
-			if !supportedActions[action] {
-				return fmt.Errorf("rule %q: unsupported action %q (supported: log, delete, tag)", ruleName, rule.Action)
+			if !compositeActions[action] {
+				return fmt.Errorf("rule %q: unsupported action %q (supported: log, delete, tag, untag)", ruleName, rule.Action)
 			}
-			if action == "tag" && rule.TagName == "" {
-				return fmt.Errorf("rule %q: tag_name is required when action is 'tag'", ruleName)
+			if (action == "tag" || action == "untag") && rule.TagName == "" && rule.ActionTagName == "" {
+				return fmt.Errorf("rule %q: tag_name is required when action is '%s'", ruleName, action)
 			}
 
 			if !hasCompositeCheck(rule.Check) {
@@ -181,6 +231,53 @@ func (p *Policy) Validate() error {
 	return nil
 }
 
+// preActionResources lists the resources that support pre_action: snapshot.
+var preActionResources = map[string]bool{
+	"cinder/volume": true,
+	"nova/instance": true,
+}
+
+func validatePreAction(preAction, action, service, resource, ruleName string) error {
+	if preAction != PreActionSnapshot {
+		return fmt.Errorf("rule %q: unsupported pre_action %q (supported: %s)", ruleName, preAction, PreActionSnapshot)
+	}
+	if action != "delete" {
+		return fmt.Errorf("rule %q: pre_action is only supported with action 'delete'", ruleName)
+	}
+	if !preActionResources[service+"/"+resource] {
+		return fmt.Errorf("rule %q: pre_action %q is not supported for %s/%s (supported: cinder/volume, nova/instance)", ruleName, preAction, service, resource)
+	}
+	return nil
+}
+
+func validateRestrict(opts *RestrictOptions, ruleName string) error {
+	if opts == nil || (len(opts.AllowedCIDRs) == 0 && len(opts.ProjectCIDRs) == 0) {
+		return fmt.Errorf("rule %q: restrict.allowed_cidrs or restrict.project_cidrs is required when action is 'restrict'", ruleName)
+	}
+	check := func(cidr string) error {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("rule %q: invalid CIDR %q in restrict: %w", ruleName, cidr, err)
+		}
+		return nil
+	}
+	for _, cidr := range opts.AllowedCIDRs {
+		if err := check(cidr); err != nil {
+			return err
+		}
+	}
+	for project, cidrs := range opts.ProjectCIDRs {
+		if len(cidrs) == 0 {
+			return fmt.Errorf("rule %q: restrict.project_cidrs[%s] must not be empty", ruleName, project)
+		}
+		for _, cidr := range cidrs {
+			if err := check(cidr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func hasAnyConstraint(check *CheckConditions) bool {
 	if check == nil {
 		return false
@@ -237,6 +334,19 @@ func validateCheckConditions(serviceName string, check *CheckConditions, resourc
 	return nil
 }
 
+// validateAction applies the service validator's per-resource action list,
+// when the validator provides one.
+func validateAction(serviceName, action, resource, ruleName string) error {
+	validator, ok := GetValidator(serviceName)
+	if !ok {
+		return nil
+	}
+	if av, ok := validator.(ActionValidator); ok {
+		return av.ValidateAction(action, resource, ruleName)
+	}
+	return nil
+}
+
 var validSeverities = map[string]bool{
 	"":               true,
 	SeverityCritical: true,
@@ -13,6 +13,13 @@ type ResourceValidator interface {
 	ValidateResource(check *CheckConditions, resourceType, ruleName string) error
 }
 
+// ActionValidator is optionally implemented by a ResourceValidator to restrict
+// which remediation actions each resource type accepts. Actions are checked
+// against the global list of supported actions before this is called.
+type ActionValidator interface {
+	ValidateAction(action, resourceType, ruleName string) error
+}
+
 var (
 	validatorMu sync.RWMutex
 	validators  = make(map[string]ResourceValidator)
@@ -179,6 +179,161 @@ composites:
 	}
 }
 
+func TestValidate_UntagAcceptsActionTagName(t *testing.T) {
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: "neutron",
+			Rules: []policy.Rule{{
+				Name:          "untag-networks",
+				Resource:      "network",
+				Check:         policy.CheckConditions{Status: "ACTIVE"},
+				Action:        "untag",
+				ActionTagName: "ospa-flagged",
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	p.Policies[0].Rules[0].ActionTagName = ""
+	err := p.Validate()
+	if err == nil || !strings.Contains(err.Error(), "tag_name is required") {
+		t.Fatalf("Validate() error = %v, want tag_name required", err)
+	}
+}
+
+func TestValidate_RestrictRequiresValidCIDRs(t *testing.T) {
+	rule := policy.Rule{
+		Name:     "restrict-ssh",
+		Resource: "security_group_rule",
+		Check:    policy.CheckConditions{Port: 22, RemoteIPPrefix: "0.0.0.0/0"},
+		Action:   "restrict",
+	}
+	p := &policy.Policy{
+		Version:  "v1",
+		Policies: []policy.ServicePolicy{{Service: "neutron", Rules: []policy.Rule{rule}}},
+	}
+
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "allowed_cidrs") {
+		t.Fatalf("Validate() error = %v, want allowlist required", err)
+	}
+
+	p.Policies[0].Rules[0].Restrict = &policy.RestrictOptions{AllowedCIDRs: []string{"10.0.0.0/33"}}
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "invalid CIDR") {
+		t.Fatalf("Validate() error = %v, want invalid CIDR", err)
+	}
+
+	p.Policies[0].Rules[0].Restrict = &policy.RestrictOptions{
+		ProjectCIDRs: map[string][]string{"proj-1": {"10.0.0.0/8"}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestValidate_ActionsArePerResource(t *testing.T) {
+	tests := []struct {
+		resource string
+		check    policy.CheckConditions
+		action   string
+		wantErr  bool
+	}{
+		{resource: "port", check: policy.CheckConditions{Status: "DOWN"}, action: "disable"},
+		{resource: "router", check: policy.CheckConditions{Status: "DOWN"}, action: "disable"},
+		{resource: "floating_ip", check: policy.CheckConditions{Unassociated: true}, action: "disassociate"},
+		{resource: "floating_ip", check: policy.CheckConditions{Unassociated: true}, action: "disable", wantErr: true},
+		{resource: "subnet", check: policy.CheckConditions{Status: "ACTIVE"}, action: "disable", wantErr: true},
+		{resource: "security_group", check: policy.CheckConditions{Unused: true}, action: "stop", wantErr: true},
+		{resource: "security_group_rule", check: policy.CheckConditions{Port: 22}, action: "tag", wantErr: true},
+		{resource: "security_group_rule", check: policy.CheckConditions{Port: 22}, action: "untag", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource+"/"+tt.action, func(t *testing.T) {
+			p := &policy.Policy{
+				Version: "v1",
+				Policies: []policy.ServicePolicy{{
+					Service: "neutron",
+					Rules: []policy.Rule{{
+						Name:     "r1",
+						Resource: tt.resource,
+						Check:    tt.check,
+						Action:   tt.action,
+						TagName:  "ospa-flagged",
+					}},
+				}},
+			}
+			err := p.Validate()
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "not supported for this resource")) {
+				t.Fatalf("Validate() error = %v, want per-resource action error", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidate_PreAction(t *testing.T) {
+	newPolicy := func(service, resource, action, preAction string) *policy.Policy {
+		return &policy.Policy{
+			Version: "v1",
+			Policies: []policy.ServicePolicy{{
+				Service: service,
+				Rules: []policy.Rule{{
+					Name:      "r1",
+					Resource:  resource,
+					Check:     policy.CheckConditions{Status: "available"},
+					Action:    action,
+					PreAction: preAction,
+				}},
+			}},
+		}
+	}
+
+	if err := newPolicy("cinder", "volume", "delete", "snapshot").Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+	if err := newPolicy("cinder", "volume", "delete", "backup").Validate(); err == nil {
+		t.Fatal("Validate() expected error for unknown pre_action")
+	}
+	if err := newPolicy("cinder", "volume", "log", "snapshot").Validate(); err == nil {
+		t.Fatal("Validate() expected error for pre_action without delete")
+	}
+	if err := newPolicy("cinder", "snapshot", "delete", "snapshot").Validate(); err == nil {
+		t.Fatal("Validate() expected error for pre_action on cinder/snapshot")
+	}
+}
+
+func TestValidate_Approval(t *testing.T) {
+	p := &policy.Policy{
+		Version: "v1",
+		Policies: []policy.ServicePolicy{{
+			Service: "neutron",
+			Rules: []policy.Rule{{
+				Name:     "r1",
+				Resource: "port",
+				Check:    policy.CheckConditions{Status: "DOWN"},
+				Action:   "delete",
+				Approval: "required",
+			}},
+		}},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+	if !p.RequiresApproval() {
+		t.Error("RequiresApproval() = false, want true")
+	}
+
+	p.Policies[0].Rules[0].Approval = "maybe"
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "approval") {
+		t.Fatalf("Validate() error = %v, want unsupported approval", err)
+	}
+}
+
 type testValidator struct {
 	serviceName string
 	err         error
@@ -210,3 +365,50 @@ func policyTestSafeName(s string) string {
 	}
 	return string(out)
 }
+
+func TestValidate_Notifications(t *testing.T) {
+	newPolicy := func(n policy.Notification) *policy.Policy {
+		return &policy.Policy{
+			Version: "v1",
+			Policies: []policy.ServicePolicy{{
+				Service: "neutron",
+				Rules: []policy.Rule{{
+					Name:     "r1",
+					Resource: "port",
+					Check:    policy.CheckConditions{Status: "DOWN"},
+					Action:   "log",
+				}},
+			}},
+			Notifications: []policy.Notification{n},
+		}
+	}
+
+	valid := policy.Notification{
+		Name:          "sec",
+		Type:          "slack",
+		URL:           "${SLACK_WEBHOOK}",
+		Filter:        policy.NotificationFilter{FindingFilter: policy.FindingFilter{Severities: []string{"critical"}, Categories: []string{"security"}}},
+		BatchSize:     10,
+		BatchInterval: "30s",
+	}
+	if err := newPolicy(valid).Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	tests := map[string]func(n *policy.Notification){
+		"missing name":      func(n *policy.Notification) { n.Name = "" },
+		"unknown type":      func(n *policy.Notification) { n.Type = "pager" },
+		"missing url":       func(n *policy.Notification) { n.URL = "" },
+		"bad severity":      func(n *policy.Notification) { n.Filter.Severities = []string{"urgent"} },
+		"bad interval":      func(n *policy.Notification) { n.BatchInterval = "soon" },
+		"digest with batch": func(n *policy.Notification) { n.Digest = true },
+		"negative batch":    func(n *policy.Notification) { n.BatchSize = -1 },
+	}
+	for name, mutate := range tests {
+		n := valid
+		mutate(&n)
+		if err := newPolicy(n).Validate(); err == nil {
+			t.Errorf("%s: Validate() expected error", name)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package progress tracks how far a scan run has got, per service and
+// resource type. It is updated by the orchestrator and read by the status
+// endpoint and the progress Reporter.
+package progress
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase is the stage of a scan run.
+type Phase string
+
+const (
+	// PhaseIdle means no run is active.
+	PhaseIdle Phase = "idle"
+	// PhaseDiscovering means resources are still being discovered; discovered
+	// resources are audited concurrently.
+	PhaseDiscovering Phase = "discovering"
+	// PhaseAuditing means discovery finished and the remaining resources
+	// are being audited.
+	PhaseAuditing Phase = "auditing"
+)
+
+// Counts is the progress of one service and resource type.
+type Counts struct {
+	Service      string `json:"service"`
+	ResourceType string `json:"resource_type"`
+	// Discovered is the number of resources found by discovery.
+	Discovered int `json:"discovered"`
+	// Queued is the number of discovered resources handed to the workers.
+	Queued int `json:"queued"`
+	// Processed is the number of queued resources whose rules have all been
+	// evaluated.
+	Processed int `json:"processed"`
+	// Audited is the number of rule evaluations, i.e. one per rule and
+	// resource.
+	Audited int `json:"audited"`
+	// Remediated is the number of successful remediations.
+	Remediated int `json:"remediated"`
+}
+
+// add adds the counts of o to c.
+func (c *Counts) add(o Counts) {
+	c.Discovered += o.Discovered
+	c.Queued += o.Queued
+	c.Processed += o.Processed
+	c.Audited += o.Audited
+	c.Remediated += o.Remediated
+}
+
+// Snapshot is a point-in-time copy of a Tracker.
+type Snapshot struct {
+	Phase      Phase      `json:"phase"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Counts     []Counts   `json:"resource_types"`
+}
+
+// Total sums the counts of all resource types.
+func (s Snapshot) Total() Counts {
+	var total Counts
+	for _, c := range s.Counts {
+		total.add(c)
+	}
+	return total
+}
+
+type key struct {
+	service      string
+	resourceType string
+}
+
+// Tracker records the progress of the current run. It is safe for
+// concurrent use.
+type Tracker struct {
+	mu         sync.Mutex
+	phase      Phase
+	startedAt  time.Time
+	finishedAt time.Time
+	counts     map[key]*Counts
+}
+
+// NewTracker returns an idle tracker.
+func NewTracker() *Tracker {
+	return &Tracker{phase: PhaseIdle, counts: make(map[key]*Counts)}
+}
+
+// Start clears the counts of the previous run and enters PhaseDiscovering.
+func (t *Tracker) Start(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = PhaseDiscovering
+	t.startedAt = now
+	t.finishedAt = time.Time{}
+	t.counts = make(map[key]*Counts)
+}
+
+// SetPhase moves the run to phase. Entering PhaseIdle marks it finished.
+func (t *Tracker) SetPhase(phase Phase, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = phase
+	if phase == PhaseIdle {
+		t.finishedAt = now
+	}
+}
+
+// AddDiscovered counts a discovered resource.
+func (t *Tracker) AddDiscovered(service, resourceType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entry(service, resourceType).Discovered++
+}
+
+// AddQueued counts a discovered resource handed to the workers.
+func (t *Tracker) AddQueued(service, resourceType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entry(service, resourceType).Queued++
+}
+
+// AddProcessed counts a resource whose rules have all been evaluated.
+func (t *Tracker) AddProcessed(service, resourceType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entry(service, resourceType).Processed++
+}
+
+// AddAudited counts one rule evaluated against one resource.
+func (t *Tracker) AddAudited(service, resourceType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entry(service, resourceType).Audited++
+}
+
+// AddRemediated counts a successful remediation.
+func (t *Tracker) AddRemediated(service, resourceType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entry(service, resourceType).Remediated++
+}
+
+func (t *Tracker) entry(service, resourceType string) *Counts {
+	k := key{service, resourceType}
+	c, ok := t.counts[k]
+	if !ok {
+		c = &Counts{Service: service, ResourceType: resourceType}
+		t.counts[k] = c
+	}
+	return c
+}
+
+// Snapshot returns a copy of the current progress, sorted by service and
+// resource type.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := Snapshot{Phase: t.phase, Counts: make([]Counts, 0, len(t.counts))}
+	if !t.startedAt.IsZero() {
+		started := t.startedAt
+		s.StartedAt = &started
+	}
+	if !t.finishedAt.IsZero() {
+		finished := t.finishedAt
+		s.FinishedAt = &finished
+	}
+	for _, c := range t.counts {
+		s.Counts = append(s.Counts, *c)
+	}
+	sort.Slice(s.Counts, func(i, j int) bool {
+		if s.Counts[i].Service != s.Counts[j].Service {
+			return s.Counts[i].Service < s.Counts[j].Service
+		}
+		return s.Counts[i].ResourceType < s.Counts[j].ResourceType
+	})
+	return s
+}
@@ -0,0 +1,51 @@
+package progress
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTracker_Lifecycle(t *testing.T) {
+	tr := NewTracker()
+	if s := tr.Snapshot(); s.Phase != PhaseIdle || s.StartedAt != nil || len(s.Counts) != 0 {
+		t.Fatalf("new tracker = %+v", s)
+	}
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tr.Start(start)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr.AddDiscovered("nova", "instance")
+			tr.AddProcessed("nova", "instance")
+		}()
+	}
+	tr.AddDiscovered("cinder", "volume")
+	wg.Wait()
+	tr.SetPhase(PhaseAuditing, start)
+
+	s := tr.Snapshot()
+	if s.Phase != PhaseAuditing || !s.StartedAt.Equal(start) || s.FinishedAt != nil {
+		t.Fatalf("snapshot = %+v", s)
+	}
+	if len(s.Counts) != 2 || s.Counts[0].Service != "cinder" || s.Counts[1].Discovered != 10 {
+		t.Fatalf("counts = %+v", s.Counts)
+	}
+	if total := s.Total(); total.Discovered != 11 || total.Processed != 10 {
+		t.Fatalf("total = %+v", total)
+	}
+
+	tr.SetPhase(PhaseIdle, start.Add(time.Minute))
+	if s := tr.Snapshot(); s.FinishedAt == nil || !s.FinishedAt.Equal(start.Add(time.Minute)) {
+		t.Fatalf("finished_at = %v", s.FinishedAt)
+	}
+
+	// The next run starts from zero.
+	tr.Start(start.Add(time.Hour))
+	if s := tr.Snapshot(); len(s.Counts) != 0 || s.FinishedAt != nil || s.Phase != PhaseDiscovering {
+		t.Fatalf("after restart = %+v", s)
+	}
+}
@@ -0,0 +1,199 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Reporting modes accepted by ParseMode.
+const (
+	ModeAuto = "auto"
+	ModeTTY  = "tty"
+	ModeLog  = "log"
+	ModeOff  = "off"
+)
+
+// ParseMode validates a reporting mode and resolves "auto" to "tty" when out
+// is a terminal and to "log" otherwise.
+func ParseMode(mode string, out *os.File) (string, error) {
+	switch mode {
+	case ModeTTY, ModeLog, ModeOff:
+		return mode, nil
+	case "", ModeAuto:
+		if IsTerminal(out) {
+			return ModeTTY, nil
+		}
+		return ModeLog, nil
+	default:
+		return "", fmt.Errorf("unknown progress mode %q (want auto, tty, log or off)", mode)
+	}
+}
+
+// IsTerminal reports whether f is a character device such as a terminal.
+func IsTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Reporter periodically reports the progress of a tracker, either by
+// redrawing a table on a terminal or by logging slog events.
+type Reporter struct {
+	tracker  *Tracker
+	mode     string
+	out      io.Writer
+	logger   *slog.Logger
+	interval time.Duration
+	now      func() time.Time
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// lines is the number of lines drawn by the last terminal update.
+	lines int
+	// last holds the counts logged by the last event, per resource type.
+	last map[key]Counts
+}
+
+// NewReporter returns a reporter for mode "tty" or "log". Terminal output
+// goes to out; log events go to logger, or slog.Default() when nil.
+func NewReporter(tracker *Tracker, mode string, out io.Writer, logger *slog.Logger, interval time.Duration) *Reporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Reporter{
+		tracker:  tracker,
+		mode:     mode,
+		out:      out,
+		logger:   logger,
+		interval: interval,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		last:     make(map[key]Counts),
+	}
+}
+
+// Start reports in the background until Stop is called.
+func (r *Reporter) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.report()
+			}
+		}
+	}()
+}
+
+// Stop stops the reporter and reports the final progress once.
+func (r *Reporter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+		r.report()
+	})
+}
+
+func (r *Reporter) report() {
+	snapshot := r.tracker.Snapshot()
+	if r.mode == ModeTTY {
+		r.draw(snapshot)
+		return
+	}
+	r.log(snapshot)
+}
+
+// draw replaces the previously drawn table with the current one.
+func (r *Reporter) draw(s Snapshot) {
+	var buf bytes.Buffer
+	if r.lines > 0 {
+		// Move to the start of the previous table and clear to the end.
+		fmt.Fprintf(&buf, "\x1b[%dA\x1b[J", r.lines)
+	}
+	table := Render(s, r.now())
+	buf.WriteString(table)
+	r.lines = bytes.Count([]byte(table), []byte("\n"))
+	_, _ = r.out.Write(buf.Bytes())
+}
+
+// log emits one event per resource type that progressed since the last
+// event, followed by the run totals.
+func (r *Reporter) log(s Snapshot) {
+	for _, c := range s.Counts {
+		k := key{c.Service, c.ResourceType}
+		if r.last[k] == c {
+			continue
+		}
+		r.last[k] = c
+		r.logger.Info("scan progress", append([]any{
+			"service", c.Service,
+			"resource_type", c.ResourceType,
+		}, countAttrs(c)...)...)
+	}
+	r.logger.Info("scan progress total", append([]any{
+		"phase", s.Phase,
+		"elapsed", elapsed(s, r.now()).String(),
+	}, countAttrs(s.Total())...)...)
+}
+
+func countAttrs(c Counts) []any {
+	return []any{
+		"discovered", c.Discovered,
+		"queued", c.Queued,
+		"processed", c.Processed,
+		"audited", c.Audited,
+		"remediated", c.Remediated,
+	}
+}
+
+// elapsed returns the run time of s, up to now for an active run.
+func elapsed(s Snapshot, now time.Time) time.Duration {
+	if s.StartedAt == nil {
+		return 0
+	}
+	end := now
+	if s.FinishedAt != nil {
+		end = *s.FinishedAt
+	}
+	return end.Sub(*s.StartedAt).Round(time.Second)
+}
+
+// Render formats s as a summary line followed by a table with one row per
+// resource type.
+func Render(s Snapshot, now time.Time) string {
+	var buf bytes.Buffer
+	total := s.Total()
+	state := string(s.Phase)
+	if s.Phase == PhaseIdle && s.FinishedAt != nil {
+		state = "finished"
+	}
+	fmt.Fprintf(&buf, "Scan %s, %s elapsed: %d/%d resources processed, %d checks, %d remediated\n",
+		state, elapsed(s, now), total.Processed, total.Discovered, total.Audited, total.Remediated)
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tRESOURCE TYPE\tDISCOVERED\tQUEUED\tPROCESSED\tAUDITED\tREMEDIATED")
+	for _, c := range s.Counts {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			c.Service, c.ResourceType, c.Discovered, c.Queued, c.Processed, c.Audited, c.Remediated)
+	}
+	_ = tw.Flush()
+	return buf.String()
+}
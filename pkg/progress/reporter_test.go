@@ -0,0 +1,101 @@
+package progress
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMode(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got, err := ParseMode(ModeAuto, f); err != nil || got != ModeLog {
+		t.Errorf("ParseMode(auto, file) = %q, %v; want log", got, err)
+	}
+	if got, err := ParseMode(ModeOff, f); err != nil || got != ModeOff {
+		t.Errorf("ParseMode(off) = %q, %v", got, err)
+	}
+	if _, err := ParseMode("fancy", f); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func testTracker(start time.Time) *Tracker {
+	tr := NewTracker()
+	tr.Start(start)
+	tr.AddDiscovered("nova", "instance")
+	tr.AddQueued("nova", "instance")
+	tr.AddProcessed("nova", "instance")
+	tr.AddAudited("nova", "instance")
+	tr.AddAudited("nova", "instance")
+	tr.AddRemediated("nova", "instance")
+	tr.AddDiscovered("cinder", "volume")
+	return tr
+}
+
+func TestRender(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tr := testTracker(start)
+
+	out := Render(tr.Snapshot(), start.Add(65*time.Second))
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Render() = %q", out)
+	}
+	if lines[0] != "Scan discovering, 1m5s elapsed: 1/2 resources processed, 2 checks, 1 remediated" {
+		t.Errorf("summary = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[3]); strings.Join(fields, " ") != "nova instance 1 1 1 2 1" {
+		t.Errorf("nova row = %q", lines[3])
+	}
+
+	tr.SetPhase(PhaseIdle, start.Add(2*time.Minute))
+	if out := Render(tr.Snapshot(), start.Add(time.Hour)); !strings.HasPrefix(out, "Scan finished, 2m0s elapsed") {
+		t.Errorf("finished summary = %q", out)
+	}
+}
+
+func TestReporter_TTYRedraws(t *testing.T) {
+	var out bytes.Buffer
+	r := NewReporter(testTracker(time.Now()), ModeTTY, &out, nil, time.Hour)
+	r.report()
+	first := out.Len()
+	r.report()
+
+	if strings.Contains(out.String()[:first], "\x1b[") {
+		t.Error("first draw must not move the cursor")
+	}
+	if !strings.Contains(out.String()[first:], "\x1b[4A\x1b[J") {
+		t.Errorf("second draw does not replace the 4 table lines: %q", out.String()[first:])
+	}
+}
+
+func TestReporter_LogEvents(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, nil))
+	tr := testTracker(time.Now())
+	r := NewReporter(tr, ModeLog, nil, logger, time.Hour)
+	r.Start()
+	r.report()
+	tr.AddProcessed("cinder", "volume")
+	r.Stop() // reports the final counts
+	r.Stop()
+
+	logs := out.String()
+	if got := strings.Count(logs, "service=nova"); got != 1 {
+		t.Errorf("nova events = %d, want 1 since its counts did not change:\n%s", got, logs)
+	}
+	if got := strings.Count(logs, "service=cinder"); got != 2 {
+		t.Errorf("cinder events = %d, want 2:\n%s", got, logs)
+	}
+	if !strings.Contains(logs, `msg="scan progress total" phase=discovering`) {
+		t.Errorf("missing total event:\n%s", logs)
+	}
+}
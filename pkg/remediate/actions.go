@@ -47,6 +47,84 @@ func (r *TagRemediator) Execute(ctx context.Context, client interface{}, resourc
 	return nil
 }
 
+// UntagRemediator handles "untag" action
+// Note: Actual tag removal is handled by the specific auditor's Fix() method
+type UntagRemediator struct{}
+
+func (r *UntagRemediator) Action() string {
+	return "untag"
+}
+
+func (r *UntagRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Tag removal is handled by the auditor's Fix() method
+	return nil
+}
+
+// RestrictRemediator handles "restrict" action
+// Note: Actual rule replacement is handled by the security group rule auditor's Fix() method
+type RestrictRemediator struct{}
+
+func (r *RestrictRemediator) Action() string {
+	return "restrict"
+}
+
+func (r *RestrictRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Rule replacement is handled by the auditor's Fix() method
+	return nil
+}
+
+// StopRemediator handles "stop" action
+// Note: Actual server stop is handled by the instance auditor's Fix() method
+type StopRemediator struct{}
+
+func (r *StopRemediator) Action() string {
+	return "stop"
+}
+
+func (r *StopRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Stopping is handled by the auditor's Fix() method
+	return nil
+}
+
+// ShelveRemediator handles "shelve" action
+// Note: Actual shelving is handled by the instance auditor's Fix() method
+type ShelveRemediator struct{}
+
+func (r *ShelveRemediator) Action() string {
+	return "shelve"
+}
+
+func (r *ShelveRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Shelving is handled by the auditor's Fix() method
+	return nil
+}
+
+// DisableRemediator handles "disable" action
+// Note: Setting admin_state_up=false is handled by the specific auditor's Fix() method
+type DisableRemediator struct{}
+
+func (r *DisableRemediator) Action() string {
+	return "disable"
+}
+
+func (r *DisableRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Disabling is handled by the auditor's Fix() method
+	return nil
+}
+
+// DisassociateRemediator handles "disassociate" action
+// Note: Actual disassociation is handled by the floating IP auditor's Fix() method
+type DisassociateRemediator struct{}
+
+func (r *DisassociateRemediator) Action() string {
+	return "disassociate"
+}
+
+func (r *DisassociateRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	// Disassociation is handled by the auditor's Fix() method
+	return nil
+}
+
 // ExecuteRemediation executes remediation using the appropriate remediator or auditor
 func ExecuteRemediation(ctx context.Context, auditor audit.Auditor, client interface{}, resource interface{}, rule *policy.Rule) error {
 	// Use the auditor's Fix() method directly
@@ -57,4 +135,12 @@ func init() {
 	Register(&LogRemediator{})
 	Register(&DeleteRemediator{})
 	Register(&TagRemediator{})
+	Register(&UntagRemediator{})
+	Register(&QuarantineRemediator{})
+	Register(&RestrictRemediator{})
+	Register(&StopRemediator{})
+	Register(&ShelveRemediator{})
+	Register(&DisableRemediator{})
+	Register(&DisassociateRemediator{})
+	Register(&DetachRemediator{})
 }
@@ -0,0 +1,75 @@
+package remediate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/volumeattach"
+)
+
+// DetachRemediator handles the "detach" action for cinder/volume resources.
+//
+// Volumes are detached through the compute API, one request per attached
+// server, so the volume's data is preserved. Volumes without attachments are
+// left untouched.
+type DetachRemediator struct{}
+
+func (r *DetachRemediator) Action() string {
+	return "detach"
+}
+
+// Execute expects a ClientProvider as client and a discovery.Job as resource.
+func (r *DetachRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	clients, ok := client.(ClientProvider)
+	if !ok {
+		return fmt.Errorf("detach: expected ClientProvider, got %T", client)
+	}
+	job, ok := resource.(discovery.Job)
+	if !ok {
+		return fmt.Errorf("detach: expected discovery.Job, got %T", resource)
+	}
+	_, err := r.Remediate(ctx, clients, job, rule)
+	return err
+}
+
+// Remediate detaches the volume from every server it is attached to and
+// returns those servers as finding details.
+func (r *DetachRemediator) Remediate(_ context.Context, clients ClientProvider, job discovery.Job, _ *policy.Rule) (map[string]string, error) {
+	if job.Service != "cinder" || job.ResourceType != "volume" {
+		return nil, fmt.Errorf("detach: unsupported resource %s/%s (supported: cinder/volume)", job.Service, job.ResourceType)
+	}
+	volume, ok := job.Resource.(volumes.Volume)
+	if !ok {
+		return nil, fmt.Errorf("detach: expected volumes.Volume, got %T", job.Resource)
+	}
+	if len(volume.Attachments) == 0 {
+		return nil, nil
+	}
+
+	compute, err := clients.ClientFor("nova")
+	if err != nil {
+		return nil, fmt.Errorf("detach: nova client: %w", err)
+	}
+
+	var detached []string
+	for _, attachment := range volume.Attachments {
+		if err := volumeattach.Delete(compute, attachment.ServerID, volume.ID).ExtractErr(); err != nil {
+			return detachDetails(detached), fmt.Errorf("detach: detaching volume %s from server %s: %w", volume.ID, attachment.ServerID, err)
+		}
+		detached = append(detached, attachment.ServerID)
+	}
+	return detachDetails(detached), nil
+}
+
+// detachDetails records the servers a volume was detached from so the
+// attachment can be restored from the finding.
+func detachDetails(servers []string) map[string]string {
+	if len(servers) == 0 {
+		return nil
+	}
+	return map[string]string{"detached_from_servers": strings.Join(servers, ",")}
+}
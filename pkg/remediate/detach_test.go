@@ -0,0 +1,82 @@
+package remediate_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/remediate"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+)
+
+func TestDetach_DetachesEveryAttachment(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	var requested []string
+	clients := remediate.ClientProviderFunc(func(service string) (*gophercloud.ServiceClient, error) {
+		requested = append(requested, service)
+		return &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{},
+			Endpoint:       srv.URL + "/",
+		}, nil
+	})
+
+	job := discovery.Job{
+		Service:      "cinder",
+		ResourceType: "volume",
+		Resource: volumes.Volume{
+			ID:          "vol-1",
+			Attachments: []volumes.Attachment{{ServerID: "srv-1"}, {ServerID: "srv-2"}},
+		},
+	}
+	rule := &policy.Rule{Name: "detach-idle", Action: "detach"}
+
+	details, err := (&remediate.DetachRemediator{}).Remediate(context.Background(), clients, job, rule)
+	if err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "nova" {
+		t.Errorf("requested clients = %v, want [nova]", requested)
+	}
+
+	want := []string{
+		"DELETE /servers/srv-1/os-volume_attachments/vol-1",
+		"DELETE /servers/srv-2/os-volume_attachments/vol-1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	if details["detached_from_servers"] != "srv-1,srv-2" {
+		t.Errorf("detached_from_servers = %q, want srv-1,srv-2", details["detached_from_servers"])
+	}
+}
+
+func TestDetach_UnattachedVolumeIsNoop(t *testing.T) {
+	clients := remediate.ClientProviderFunc(func(service string) (*gophercloud.ServiceClient, error) {
+		t.Fatalf("unexpected client request for %q", service)
+		return nil, nil
+	})
+	job := discovery.Job{Service: "cinder", ResourceType: "volume", Resource: volumes.Volume{ID: "vol-1"}}
+
+	details, err := (&remediate.DetachRemediator{}).Remediate(context.Background(), clients, job, &policy.Rule{Action: "detach"})
+	if err != nil || details != nil {
+		t.Fatalf("Remediate() = %v, %v; want nil, nil", details, err)
+	}
+}
+
+func TestDetach_UnsupportedResource(t *testing.T) {
+	job := discovery.Job{Service: "neutron", ResourceType: "floating_ip"}
+	if _, err := (&remediate.DetachRemediator{}).Remediate(context.Background(), nil, job, &policy.Rule{Action: "detach"}); err == nil {
+		t.Fatal("Remediate() on floating_ip expected error")
+	}
+}
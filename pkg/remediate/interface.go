@@ -3,7 +3,9 @@ package remediate
 import (
 	"context"
 
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
 )
 
 // Remediator applies remediation actions to resources
@@ -14,3 +16,31 @@ type Remediator interface {
 	// Action returns the action name this remediator handles
 	Action() string
 }
+
+// ResourceRemediator is a Remediator that performs its action itself rather
+// than delegating to the auditor's Fix method. This lets a single action
+// (e.g. quarantine) be used from rules on different services, at the cost of
+// needing clients for more than one service.
+//
+// The returned details are attached to the finding so that operators can see
+// what was changed (e.g. the security groups a port had before quarantine).
+type ResourceRemediator interface {
+	Remediator
+
+	// Remediate applies the action to the resource carried by job.
+	Remediate(ctx context.Context, clients ClientProvider, job discovery.Job, rule *policy.Rule) (map[string]string, error)
+}
+
+// ClientProvider returns an authenticated client for a service name
+// (e.g. "neutron", "nova").
+type ClientProvider interface {
+	ClientFor(service string) (*gophercloud.ServiceClient, error)
+}
+
+// ClientProviderFunc adapts a function to the ClientProvider interface.
+type ClientProviderFunc func(service string) (*gophercloud.ServiceClient, error)
+
+// ClientFor calls f(service).
+func (f ClientProviderFunc) ClientFor(service string) (*gophercloud.ServiceClient, error) {
+	return f(service)
+}
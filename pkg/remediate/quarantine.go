@@ -0,0 +1,303 @@
+package remediate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/lockunlock"
+	servertags "github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/tags"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+)
+
+const (
+	// DefaultQuarantineGroup is the name of the per-project security group
+	// used when the rule does not configure one.
+	DefaultQuarantineGroup = "ospa-quarantine"
+
+	// DefaultQuarantineTag is applied to quarantined resources when the rule
+	// does not set tag_name.
+	DefaultQuarantineTag = "ospa-quarantined"
+
+	// QuarantineOriginalSGTagPrefix prefixes the port tags that record the
+	// security groups a port had before it was quarantined, one tag per group.
+	QuarantineOriginalSGTagPrefix = "ospa-orig-sg:"
+
+	// Nova server tags require compute API microversion 2.26.
+	serverTagsMicroversion = "2.26"
+)
+
+// QuarantineRemediator handles the "quarantine" action for neutron/port and
+// nova/instance resources.
+//
+// Every port of the resource has its security groups replaced by a single
+// quarantine security group that has no rules, which blocks all traffic.
+// The group is looked up by name in the resource's project and created on
+// first use. The original groups are recorded as port tags (see
+// QuarantineOriginalSGTagPrefix) and in the finding so they can be restored.
+// Optionally the owning server is locked, and the resource is tagged.
+type QuarantineRemediator struct{}
+
+func (r *QuarantineRemediator) Action() string {
+	return "quarantine"
+}
+
+// Execute expects a ClientProvider as client and a discovery.Job as resource.
+func (r *QuarantineRemediator) Execute(ctx context.Context, client interface{}, resource interface{}, rule *policy.Rule) error {
+	clients, ok := client.(ClientProvider)
+	if !ok {
+		return fmt.Errorf("quarantine: expected ClientProvider, got %T", client)
+	}
+	job, ok := resource.(discovery.Job)
+	if !ok {
+		return fmt.Errorf("quarantine: expected discovery.Job, got %T", resource)
+	}
+	_, err := r.Remediate(ctx, clients, job, rule)
+	return err
+}
+
+// Remediate quarantines the resource. gophercloud v1 calls take no context,
+// so cancellation is not propagated to the API requests.
+func (r *QuarantineRemediator) Remediate(_ context.Context, clients ClientProvider, job discovery.Job, rule *policy.Rule) (map[string]string, error) {
+	network, err := clients.ClientFor("neutron")
+	if err != nil {
+		return nil, fmt.Errorf("quarantine: neutron client: %w", err)
+	}
+
+	var (
+		portList  []ports.Port
+		projectID = job.ProjectID
+		serverID  string
+	)
+
+	switch {
+	case job.Service == "neutron" && job.ResourceType == "port":
+		port, ok := job.Resource.(ports.Port)
+		if !ok {
+			return nil, fmt.Errorf("quarantine: expected ports.Port, got %T", job.Resource)
+		}
+		portList = []ports.Port{port}
+		if port.TenantID != "" {
+			projectID = port.TenantID
+		}
+		if strings.HasPrefix(port.DeviceOwner, "compute:") {
+			serverID = port.DeviceID
+		}
+
+	case job.Service == "nova" && job.ResourceType == "instance":
+		server, ok := job.Resource.(servers.Server)
+		if !ok {
+			return nil, fmt.Errorf("quarantine: expected servers.Server, got %T", job.Resource)
+		}
+		serverID = server.ID
+		if server.TenantID != "" {
+			projectID = server.TenantID
+		}
+		pages, err := ports.List(network, ports.ListOpts{DeviceID: server.ID}).AllPages()
+		if err != nil {
+			return nil, fmt.Errorf("quarantine: listing ports for server %s: %w", server.ID, err)
+		}
+		portList, err = ports.ExtractPorts(pages)
+		if err != nil {
+			return nil, fmt.Errorf("quarantine: extracting ports: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("quarantine: unsupported resource %s/%s (supported: neutron/port, nova/instance)", job.Service, job.ResourceType)
+	}
+
+	groupName := DefaultQuarantineGroup
+	lockServer := false
+	if rule.Quarantine != nil {
+		if rule.Quarantine.SecurityGroup != "" {
+			groupName = rule.Quarantine.SecurityGroup
+		}
+		lockServer = rule.Quarantine.LockServer
+	}
+	tagName := rule.EffectiveTagName()
+	if tagName == "" {
+		tagName = DefaultQuarantineTag
+	}
+
+	quarantineID, err := ensureQuarantineGroup(network, groupName, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{"quarantine_security_group": quarantineID}
+	var original []string
+	for _, port := range portList {
+		changed, err := quarantinePort(network, port, quarantineID, tagName)
+		if err != nil {
+			return details, err
+		}
+		if !changed {
+			continue
+		}
+		groupsCopy := append([]string(nil), port.SecurityGroups...)
+		sort.Strings(groupsCopy)
+		original = append(original, fmt.Sprintf("%s=%s", port.ID, strings.Join(groupsCopy, ",")))
+	}
+	if len(original) > 0 {
+		details["original_security_groups"] = strings.Join(original, ";")
+	}
+
+	if serverID == "" {
+		return details, nil
+	}
+
+	compute, err := clients.ClientFor("nova")
+	if err != nil {
+		return details, fmt.Errorf("quarantine: nova client: %w", err)
+	}
+	if lockServer {
+		if err := lockunlock.Lock(compute, serverID).ExtractErr(); err != nil {
+			return details, fmt.Errorf("quarantine: locking server %s: %w", serverID, err)
+		}
+		details["server_locked"] = serverID
+	}
+	tagging := *compute
+	tagging.Microversion = serverTagsMicroversion
+	if err := servertags.Add(&tagging, serverID, tagName).ExtractErr(); err != nil {
+		return details, fmt.Errorf("quarantine: tagging server %s: %w", serverID, err)
+	}
+
+	return details, nil
+}
+
+// quarantineGroupLocks serialises the lookup and creation of a quarantine
+// group, so concurrent workers remediating resources of the same project do
+// not each create one.
+var quarantineGroupLocks = &keyedMutex{}
+
+// ensureQuarantineGroup returns the ID of the named security group in the
+// project, creating it if needed. A newly created group has its default
+// egress rules removed so that it blocks all traffic.
+//
+// Creation is serialised per project within the process. Another agent may
+// still create the group concurrently, so the groups are listed again after
+// a create: every agent then settles on the oldest group and a duplicate
+// created here is deleted.
+func ensureQuarantineGroup(c *gophercloud.ServiceClient, name, projectID string) (string, error) {
+	unlock := quarantineGroupLocks.Lock(c.Endpoint + "\x00" + projectID + "\x00" + name)
+	defer unlock()
+
+	id, err := findQuarantineGroup(c, name, projectID)
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	sg, err := groups.Create(c, groups.CreateOpts{
+		Name:        name,
+		ProjectID:   projectID,
+		Description: "Created by OSPA: blocks all traffic for quarantined resources",
+	}).Extract()
+	if err != nil {
+		var conflict gophercloud.ErrDefault409
+		if errors.As(err, &conflict) {
+			if id, lerr := findQuarantineGroup(c, name, projectID); lerr == nil && id != "" {
+				return id, nil
+			}
+		}
+		return "", fmt.Errorf("quarantine: creating security group %q: %w", name, err)
+	}
+	for _, sgRule := range sg.Rules {
+		if err := rules.Delete(c, sgRule.ID).ExtractErr(); err != nil {
+			return "", fmt.Errorf("quarantine: removing default rule %s from %s: %w", sgRule.ID, sg.ID, err)
+		}
+	}
+
+	id, err = findQuarantineGroup(c, name, projectID)
+	if err != nil || id == "" || id == sg.ID {
+		return sg.ID, nil
+	}
+	if err := groups.Delete(c, sg.ID).ExtractErr(); err != nil {
+		return "", fmt.Errorf("quarantine: deleting duplicate security group %s: %w", sg.ID, err)
+	}
+	return id, nil
+}
+
+// findQuarantineGroup returns the ID of the oldest security group with the
+// name in the project, or "" if there is none.
+func findQuarantineGroup(c *gophercloud.ServiceClient, name, projectID string) (string, error) {
+	pages, err := groups.List(c, groups.ListOpts{Name: name, ProjectID: projectID}).AllPages()
+	if err != nil {
+		return "", fmt.Errorf("quarantine: listing security groups: %w", err)
+	}
+	existing, err := groups.ExtractGroups(pages)
+	if err != nil {
+		return "", fmt.Errorf("quarantine: extracting security groups: %w", err)
+	}
+	if len(existing) == 0 {
+		return "", nil
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		if !existing[i].CreatedAt.Equal(existing[j].CreatedAt) {
+			return existing[i].CreatedAt.Before(existing[j].CreatedAt)
+		}
+		return existing[i].ID < existing[j].ID
+	})
+	return existing[0].ID, nil
+}
+
+// keyedMutex is a set of mutexes identified by key.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// Lock locks the mutex for key and returns the function that unlocks it.
+func (k *keyedMutex) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*sync.Mutex)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// quarantinePort records the port's current security groups as tags, swaps
+// them for the quarantine group and tags the port. Ports that are already
+// quarantined are left untouched (changed is false) so that the recorded
+// groups are not lost.
+func quarantinePort(c *gophercloud.ServiceClient, port ports.Port, quarantineID, tagName string) (changed bool, err error) {
+	if len(port.SecurityGroups) == 1 && port.SecurityGroups[0] == quarantineID {
+		return false, nil
+	}
+
+	for _, sgID := range port.SecurityGroups {
+		if sgID == quarantineID {
+			continue
+		}
+		if err := attributestags.Add(c, "ports", port.ID, QuarantineOriginalSGTagPrefix+sgID).ExtractErr(); err != nil {
+			return false, fmt.Errorf("quarantine: recording security group %s on port %s: %w", sgID, port.ID, err)
+		}
+	}
+
+	sgs := []string{quarantineID}
+	if _, err := ports.Update(c, port.ID, ports.UpdateOpts{SecurityGroups: &sgs}).Extract(); err != nil {
+		return false, fmt.Errorf("quarantine: updating security groups on port %s: %w", port.ID, err)
+	}
+
+	if err := attributestags.Add(c, "ports", port.ID, tagName).ExtractErr(); err != nil {
+		return true, fmt.Errorf("quarantine: tagging port %s: %w", port.ID, err)
+	}
+	return true, nil
+}
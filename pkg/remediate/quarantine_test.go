@@ -0,0 +1,258 @@
+package remediate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/remediate"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+)
+
+// fakeNeutron is a minimal Neutron API used to exercise the quarantine
+// remediator. It records every request as "METHOD path".
+type fakeNeutron struct {
+	mu         sync.Mutex
+	calls      []string
+	groupExist bool
+	portBody   string
+	// created counts the security groups created.
+	created int
+	// rivalGroup, if set, is an older group another agent created while
+	// this one created its own.
+	rivalGroup string
+	// listDelay delays every request, widening races between workers.
+	listDelay time.Duration
+}
+
+func (f *fakeNeutron) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(f.listDelay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/security-groups":
+		switch {
+		case f.groupExist && f.rivalGroup != "":
+			_, _ = io.WriteString(w, `{"security_groups":[`+
+				`{"id":"q-sg","name":"ospa-quarantine","created_at":"2026-01-02T00:00:00Z"},`+
+				`{"id":"`+f.rivalGroup+`","name":"ospa-quarantine","created_at":"2026-01-01T00:00:00Z"}]}`)
+		case f.groupExist:
+			_, _ = io.WriteString(w, `{"security_groups":[{"id":"q-sg","name":"ospa-quarantine"}]}`)
+		default:
+			_, _ = io.WriteString(w, `{"security_groups":[]}`)
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/security-groups":
+		f.created++
+		f.groupExist = true
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"security_group":{"id":"q-sg","name":"ospa-quarantine","security_group_rules":[{"id":"egress-v4"},{"id":"egress-v6"}]}}`)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/security-group-rules/"),
+		r.Method == http.MethodDelete && r.URL.Path == "/security-groups/q-sg":
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/tags/"):
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/ports/"):
+		body, _ := io.ReadAll(r.Body)
+		f.portBody = string(body)
+		_, _ = io.WriteString(w, `{"port":{"id":"port-1","security_groups":["q-sg"]}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeNeutron) snapshot() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func newClients(t *testing.T, handler http.Handler) remediate.ClientProvider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return remediate.ClientProviderFunc(func(service string) (*gophercloud.ServiceClient, error) {
+		if service != "neutron" {
+			return nil, fmt.Errorf("unexpected client request for %q", service)
+		}
+		return &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{},
+			Endpoint:       srv.URL + "/",
+		}, nil
+	})
+}
+
+func TestQuarantine_PortWithExistingGroup(t *testing.T) {
+	neutron := &fakeNeutron{groupExist: true}
+	clients := newClients(t, neutron)
+
+	job := discovery.Job{
+		Service:      "neutron",
+		ResourceType: "port",
+		ResourceID:   "port-1",
+		Resource:     ports.Port{ID: "port-1", TenantID: "proj-1", SecurityGroups: []string{"web", "default"}},
+	}
+	rule := &policy.Rule{Name: "quarantine-port", Action: "quarantine"}
+
+	details, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule)
+	if err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+
+	if details["quarantine_security_group"] != "q-sg" {
+		t.Errorf("quarantine_security_group = %q, want q-sg", details["quarantine_security_group"])
+	}
+	if details["original_security_groups"] != "port-1=default,web" {
+		t.Errorf("original_security_groups = %q, want port-1=default,web", details["original_security_groups"])
+	}
+
+	want := []string{
+		"GET /security-groups",
+		"PUT /ports/port-1/tags/ospa-orig-sg:web",
+		"PUT /ports/port-1/tags/ospa-orig-sg:default",
+		"PUT /ports/port-1",
+		"PUT /ports/port-1/tags/ospa-quarantined",
+	}
+	if got := neutron.snapshot(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	var body map[string]map[string][]string
+	if err := json.Unmarshal([]byte(neutron.portBody), &body); err != nil {
+		t.Fatalf("unmarshal port update: %v", err)
+	}
+	if sgs := body["port"]["security_groups"]; len(sgs) != 1 || sgs[0] != "q-sg" {
+		t.Errorf("port update security_groups = %v, want [q-sg]", sgs)
+	}
+}
+
+func TestQuarantine_CreatesGroupWithoutRules(t *testing.T) {
+	neutron := &fakeNeutron{}
+	clients := newClients(t, neutron)
+
+	job := discovery.Job{
+		Service:      "neutron",
+		ResourceType: "port",
+		Resource:     ports.Port{ID: "port-1", TenantID: "proj-1"},
+	}
+	rule := &policy.Rule{
+		Name:       "quarantine-port",
+		Action:     "quarantine",
+		TagName:    "incident-42",
+		Quarantine: &policy.QuarantineOptions{SecurityGroup: "isolate"},
+	}
+
+	if _, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule); err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+
+	calls := strings.Join(neutron.snapshot(), "\n")
+	for _, want := range []string{
+		"POST /security-groups",
+		"DELETE /security-group-rules/egress-v4",
+		"DELETE /security-group-rules/egress-v6",
+		"PUT /ports/port-1/tags/incident-42",
+	} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("missing call %q in:\n%s", want, calls)
+		}
+	}
+}
+
+func TestQuarantine_ConcurrentWorkersCreateOneGroup(t *testing.T) {
+	neutron := &fakeNeutron{listDelay: 10 * time.Millisecond}
+	clients := newClients(t, neutron)
+	rule := &policy.Rule{Name: "quarantine-port", Action: "quarantine"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			job := discovery.Job{
+				Service:      "neutron",
+				ResourceType: "port",
+				Resource:     ports.Port{ID: fmt.Sprintf("port-%d", i), TenantID: "proj-1", SecurityGroups: []string{"web"}},
+			}
+			if _, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Remediate() error = %v", err)
+	}
+
+	neutron.mu.Lock()
+	defer neutron.mu.Unlock()
+	if neutron.created != 1 {
+		t.Errorf("created %d quarantine groups, want 1", neutron.created)
+	}
+}
+
+func TestQuarantine_GroupCreatedByAnotherAgentWins(t *testing.T) {
+	neutron := &fakeNeutron{rivalGroup: "rival-sg"}
+	clients := newClients(t, neutron)
+
+	job := discovery.Job{
+		Service:      "neutron",
+		ResourceType: "port",
+		Resource:     ports.Port{ID: "port-1", TenantID: "proj-2", SecurityGroups: []string{"web"}},
+	}
+	rule := &policy.Rule{Name: "quarantine-port", Action: "quarantine"}
+
+	details, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule)
+	if err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+	if details["quarantine_security_group"] != "rival-sg" {
+		t.Errorf("quarantine_security_group = %q, want the older rival-sg", details["quarantine_security_group"])
+	}
+	if calls := strings.Join(neutron.snapshot(), "\n"); !strings.Contains(calls, "DELETE /security-groups/q-sg") {
+		t.Errorf("duplicate group q-sg was not deleted:\n%s", calls)
+	}
+}
+
+func TestQuarantine_AlreadyQuarantinedPortIsUntouched(t *testing.T) {
+	neutron := &fakeNeutron{groupExist: true}
+	clients := newClients(t, neutron)
+
+	job := discovery.Job{
+		Service:      "neutron",
+		ResourceType: "port",
+		Resource:     ports.Port{ID: "port-1", SecurityGroups: []string{"q-sg"}},
+	}
+	rule := &policy.Rule{Name: "quarantine-port", Action: "quarantine"}
+
+	if _, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule); err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+	if calls := neutron.snapshot(); len(calls) != 1 {
+		t.Fatalf("expected only the group lookup, got %v", calls)
+	}
+}
+
+func TestQuarantine_UnsupportedResource(t *testing.T) {
+	clients := newClients(t, &fakeNeutron{})
+	job := discovery.Job{Service: "neutron", ResourceType: "network"}
+	rule := &policy.Rule{Name: "r", Action: "quarantine"}
+
+	if _, err := (&remediate.QuarantineRemediator{}).Remediate(context.Background(), clients, job, rule); err == nil {
+		t.Fatal("Remediate() on network expected error")
+	}
+}
@@ -16,7 +16,7 @@ func (r *fakeRemediator) Execute(context.Context, interface{}, interface{}, *pol
 }
 
 func TestBuiltInRemediatorsRegistered(t *testing.T) {
-	for _, action := range []string{"log", "delete", "tag"} {
+	for _, action := range []string{"log", "delete", "tag", "untag", "quarantine", "restrict", "stop", "shelve", "disable", "disassociate", "detach"} {
 		if _, err := remediate.Get(action); err != nil {
 			t.Fatalf("Get(%q) error = %v", action, err)
 		}
@@ -0,0 +1,140 @@
+package remediate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/extensions/backups"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/images"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+)
+
+var (
+	// SnapshotTimeout bounds how long a pre-delete backup or image may take
+	// to become available before the delete is aborted.
+	SnapshotTimeout = 30 * time.Minute
+
+	// SnapshotPollInterval is how often the backup or image status is polled.
+	SnapshotPollInterval = 5 * time.Second
+)
+
+// RunPreAction runs the rule's pre_action for the resource in job. It returns
+// details to record in the finding. A non-nil error means the pre-action did
+// not complete and the main action must not run.
+func RunPreAction(ctx context.Context, clients ClientProvider, job discovery.Job, rule *policy.Rule) (map[string]string, error) {
+	switch rule.PreAction {
+	case "":
+		return nil, nil
+	case policy.PreActionSnapshot:
+		return snapshotBeforeDelete(ctx, clients, job)
+	default:
+		return nil, fmt.Errorf("unsupported pre_action %q", rule.PreAction)
+	}
+}
+
+// snapshotBeforeDelete backs up a Cinder volume or images a Nova server and
+// waits until the copy is usable.
+func snapshotBeforeDelete(ctx context.Context, clients ClientProvider, job discovery.Job) (map[string]string, error) {
+	name := fmt.Sprintf("ospa-pre-delete-%s-%s", job.ResourceID, time.Now().UTC().Format("20060102T150405Z"))
+
+	switch {
+	case job.Service == "cinder" && job.ResourceType == "volume":
+		volume, ok := job.Resource.(volumes.Volume)
+		if !ok {
+			return nil, fmt.Errorf("snapshot: expected volumes.Volume, got %T", job.Resource)
+		}
+		c, err := clients.ClientFor("cinder")
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: cinder client: %w", err)
+		}
+		backup, err := backups.Create(c, backups.CreateOpts{
+			VolumeID:    volume.ID,
+			Name:        name,
+			Description: "Created by OSPA before deleting the volume",
+			Force:       true,
+		}).Extract()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: backing up volume %s: %w", volume.ID, err)
+		}
+		details := map[string]string{"backup_id": backup.ID}
+		err = waitForStatus(ctx, func() (string, error) {
+			b, err := backups.Get(c, backup.ID).Extract()
+			if err != nil {
+				return "", err
+			}
+			return b.Status, nil
+		}, "available", "error")
+		if err != nil {
+			return details, fmt.Errorf("snapshot: backup %s of volume %s: %w", backup.ID, volume.ID, err)
+		}
+		return details, nil
+
+	case job.Service == "nova" && job.ResourceType == "instance":
+		server, ok := job.Resource.(servers.Server)
+		if !ok {
+			return nil, fmt.Errorf("snapshot: expected servers.Server, got %T", job.Resource)
+		}
+		c, err := clients.ClientFor("nova")
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: nova client: %w", err)
+		}
+		imageID, err := servers.CreateImage(c, server.ID, servers.CreateImageOpts{
+			Name:     name,
+			Metadata: map[string]string{"ospa_source_server": server.ID},
+		}).ExtractImageID()
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: creating image of server %s: %w", server.ID, err)
+		}
+		details := map[string]string{"image_id": imageID}
+		err = waitForStatus(ctx, func() (string, error) {
+			img, err := images.Get(c, imageID).Extract()
+			if err != nil {
+				return "", err
+			}
+			return img.Status, nil
+		}, "ACTIVE", "ERROR", "DELETED")
+		if err != nil {
+			return details, fmt.Errorf("snapshot: image %s of server %s: %w", imageID, server.ID, err)
+		}
+		return details, nil
+
+	default:
+		return nil, fmt.Errorf("snapshot: unsupported resource %s/%s (supported: cinder/volume, nova/instance)", job.Service, job.ResourceType)
+	}
+}
+
+// waitForStatus polls status until it returns want, one of failed, or
+// SnapshotTimeout elapses. Status comparison is case-insensitive.
+func waitForStatus(ctx context.Context, status func() (string, error), want string, failed ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, SnapshotTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(SnapshotPollInterval)
+	defer ticker.Stop()
+
+	for {
+		current, err := status()
+		if err != nil {
+			return fmt.Errorf("checking status: %w", err)
+		}
+		if strings.EqualFold(current, want) {
+			return nil
+		}
+		for _, f := range failed {
+			if strings.EqualFold(current, f) {
+				return fmt.Errorf("status is %s", current)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for status %s (last %q): %w", want, current, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package remediate_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit/cinder"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit/nova"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/discovery"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/remediate"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+)
+
+// fakeSnapshotAPI serves the Cinder backup and Nova image endpoints used by
+// the snapshot pre-action. Status polls return each entry of statuses in
+// turn, repeating the last one.
+type fakeSnapshotAPI struct {
+	mu       sync.Mutex
+	calls    []string
+	statuses []string
+}
+
+func (f *fakeSnapshotAPI) nextStatus() string {
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return status
+}
+
+func (f *fakeSnapshotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/backups":
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"backup":{"id":"backup-1","status":"creating"}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/backups/backup-1":
+		_, _ = io.WriteString(w, `{"backup":{"id":"backup-1","status":"`+f.nextStatus()+`"}}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/servers/srv-1/action":
+		w.Header().Set("Location", "http://"+r.Host+"/images/image-1")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodGet && r.URL.Path == "/images/image-1":
+		_, _ = io.WriteString(w, `{"image":{"id":"image-1","status":"`+f.nextStatus()+`"}}`)
+	case r.Method == http.MethodDelete && (r.URL.Path == "/volumes/vol-1" || r.URL.Path == "/servers/srv-1"):
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newSnapshotClients(t *testing.T, api *fakeSnapshotAPI) remediate.ClientProvider {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	oldInterval := remediate.SnapshotPollInterval
+	remediate.SnapshotPollInterval = time.Millisecond
+	t.Cleanup(func() { remediate.SnapshotPollInterval = oldInterval })
+
+	return remediate.ClientProviderFunc(func(string) (*gophercloud.ServiceClient, error) {
+		return &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{},
+			Endpoint:       srv.URL + "/",
+		}, nil
+	})
+}
+
+func TestRunPreAction_BacksUpVolume(t *testing.T) {
+	api := &fakeSnapshotAPI{statuses: []string{"creating", "available"}}
+	clients := newSnapshotClients(t, api)
+
+	job := discovery.Job{Service: "cinder", ResourceType: "volume", ResourceID: "vol-1", Resource: volumes.Volume{ID: "vol-1"}}
+	rule := &policy.Rule{Name: "safe-delete", Action: "delete", PreAction: policy.PreActionSnapshot}
+
+	details, err := remediate.RunPreAction(context.Background(), clients, job, rule)
+	if err != nil {
+		t.Fatalf("RunPreAction() error = %v", err)
+	}
+	if details["backup_id"] != "backup-1" {
+		t.Errorf("backup_id = %q, want backup-1", details["backup_id"])
+	}
+	if got := strings.Count(strings.Join(api.calls, "\n"), "GET /backups/backup-1"); got != 2 {
+		t.Errorf("polled backup %d times, want 2 (calls: %v)", got, api.calls)
+	}
+}
+
+func TestRunPreAction_ImagesServer(t *testing.T) {
+	api := &fakeSnapshotAPI{statuses: []string{"SAVING", "ACTIVE"}}
+	clients := newSnapshotClients(t, api)
+
+	job := discovery.Job{Service: "nova", ResourceType: "instance", ResourceID: "srv-1", Resource: servers.Server{ID: "srv-1"}}
+	rule := &policy.Rule{Name: "safe-delete", Action: "delete", PreAction: policy.PreActionSnapshot}
+
+	details, err := remediate.RunPreAction(context.Background(), clients, job, rule)
+	if err != nil {
+		t.Fatalf("RunPreAction() error = %v", err)
+	}
+	if details["image_id"] != "image-1" {
+		t.Errorf("image_id = %q, want image-1", details["image_id"])
+	}
+}
+
+// TestRunPreAction_ThenDelete runs the pre-action followed by the real
+// auditors' delete, as the orchestrator does.
+func TestRunPreAction_ThenDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      discovery.Job
+		auditor  audit.Auditor
+		statuses []string
+		want     string
+	}{
+		{
+			name:     "volume",
+			job:      discovery.Job{Service: "cinder", ResourceType: "volume", ResourceID: "vol-1", Resource: volumes.Volume{ID: "vol-1", Status: "available"}},
+			auditor:  &cinder.VolumeAuditor{},
+			statuses: []string{"available"},
+			want:     "POST /backups,GET /backups/backup-1,DELETE /volumes/vol-1",
+		},
+		{
+			name:     "instance",
+			job:      discovery.Job{Service: "nova", ResourceType: "instance", ResourceID: "srv-1", Resource: servers.Server{ID: "srv-1", Status: "SHUTOFF"}},
+			auditor:  &nova.InstanceAuditor{},
+			statuses: []string{"ACTIVE"},
+			want:     "POST /servers/srv-1/action,GET /images/image-1,DELETE /servers/srv-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeSnapshotAPI{statuses: tt.statuses}
+			clients := newSnapshotClients(t, api)
+			rule := &policy.Rule{Name: "safe-delete", Action: "delete", PreAction: policy.PreActionSnapshot}
+
+			if _, err := remediate.RunPreAction(context.Background(), clients, tt.job, rule); err != nil {
+				t.Fatalf("RunPreAction() error = %v", err)
+			}
+			client, _ := clients.ClientFor(tt.job.Service)
+			if err := tt.auditor.Fix(context.Background(), client, tt.job.Resource, rule); err != nil {
+				t.Fatalf("Fix(delete) error = %v", err)
+			}
+			if got := strings.Join(api.calls, ","); got != tt.want {
+				t.Errorf("calls = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPreAction_FailedBackupReturnsError(t *testing.T) {
+	api := &fakeSnapshotAPI{statuses: []string{"error"}}
+	clients := newSnapshotClients(t, api)
+
+	job := discovery.Job{Service: "cinder", ResourceType: "volume", ResourceID: "vol-1", Resource: volumes.Volume{ID: "vol-1"}}
+	rule := &policy.Rule{Name: "safe-delete", Action: "delete", PreAction: policy.PreActionSnapshot}
+
+	details, err := remediate.RunPreAction(context.Background(), clients, job, rule)
+	if err == nil {
+		t.Fatal("RunPreAction() expected error for failed backup")
+	}
+	if details["backup_id"] != "backup-1" {
+		t.Errorf("backup_id = %q, want backup-1 recorded even on failure", details["backup_id"])
+	}
+}
+
+func TestRunPreAction_TimesOut(t *testing.T) {
+	api := &fakeSnapshotAPI{statuses: []string{"SAVING"}}
+	clients := newSnapshotClients(t, api)
+
+	oldTimeout := remediate.SnapshotTimeout
+	remediate.SnapshotTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { remediate.SnapshotTimeout = oldTimeout })
+
+	job := discovery.Job{Service: "nova", ResourceType: "instance", ResourceID: "srv-1", Resource: servers.Server{ID: "srv-1"}}
+	rule := &policy.Rule{Name: "safe-delete", Action: "delete", PreAction: policy.PreActionSnapshot}
+
+	if _, err := remediate.RunPreAction(context.Background(), clients, job, rule); err == nil {
+		t.Fatal("RunPreAction() expected timeout error")
+	}
+}
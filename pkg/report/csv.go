@@ -3,6 +3,8 @@ package report
 import (
 	"encoding/csv"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
@@ -43,6 +45,9 @@ func (w *CSVWriter) WriteResult(r *audit.Result) error {
 			"remediation_error_kind",
 			"remediation_skipped",
 			"remediation_skip_reason",
+			"remediation_details",
+			"fingerprint",
+			"baseline_status",
 		}
 		if err := w.writer.Write(header); err != nil {
 			return err
@@ -107,6 +112,9 @@ func (w *CSVWriter) WriteResult(r *audit.Result) error {
 		remediationErrorKind,
 		boolToString(r.RemediationSkipped),
 		r.RemediationSkipReason,
+		formatDetails(r.RemediationDetails),
+		ResultFingerprint(r),
+		r.BaselineStatus,
 	}
 
 	return w.writer.Write(record)
@@ -117,6 +125,24 @@ func (w *CSVWriter) Close() error {
 	return w.writer.Error()
 }
 
+// formatDetails renders remediation details as sorted key=value pairs
+// separated by spaces.
+func formatDetails(details map[string]string) string {
+	if len(details) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+details[k])
+	}
+	return strings.Join(parts, " ")
+}
+
 func boolToString(v bool) string {
 	if v {
 		return "true"
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+// Baseline statuses.
+const (
+	BaselineNew      = "new"
+	BaselineExisting = "existing"
+	BaselineResolved = "resolved"
+)
+
+// Fingerprint identifies a finding across runs: the same rule failing on the
+// same resource in the same cloud and region always has the same
+// fingerprint.
+func Fingerprint(ruleID, service, resourceType, resourceID, cloud, region string) string {
+	h := sha256.New()
+	for _, part := range []string{ruleID, service, resourceType, resourceID, cloud, region} {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func (f Finding) computeFingerprint() string {
+	return Fingerprint(f.RuleID, f.Service, f.ResourceType, f.ResourceID, f.Cloud, f.Region)
+}
+
+// isViolation reports whether f is an open violation, as opposed to a
+// compliant result, an audit error or a resolved record.
+func (f Finding) isViolation() bool {
+	return !f.Compliant && f.Error == "" && f.BaselineStatus != BaselineResolved
+}
+
+// LoadFindings reads a JSON Lines findings file written by the json format.
+// Findings from files written before fingerprints existed are fingerprinted
+// on load.
+func LoadFindings(path string) ([]Finding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening findings %q: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var findings []Finding
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var f Finding
+		if err := json.Unmarshal([]byte(text), &f); err != nil {
+			return nil, fmt.Errorf("parsing findings %q line %d: %w", path, line, err)
+		}
+		if f.Fingerprint == "" {
+			f.Fingerprint = f.computeFingerprint()
+		}
+		findings = append(findings, f)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading findings %q: %w", path, err)
+	}
+	return findings, nil
+}
+
+// DiffResult splits two runs' violations by fingerprint.
+type DiffResult struct {
+	New      []Finding
+	Existing []Finding
+	Resolved []Finding
+}
+
+// Diff compares the violations in old and current. Compliant results, audit
+// errors and resolved records are ignored, except that, as with
+// BaselineWriter, a resource whose audit failed in current is not reported
+// resolved.
+func Diff(old, current []Finding) DiffResult {
+	oldByFP := violationsByFingerprint(old)
+	currentByFP := violationsByFingerprint(current)
+	errored := make(map[string]bool)
+	for _, f := range current {
+		if f.Error != "" {
+			errored[f.Fingerprint] = true
+		}
+	}
+
+	var d DiffResult
+	for fp, f := range currentByFP {
+		if _, ok := oldByFP[fp]; ok {
+			f.BaselineStatus = BaselineExisting
+			d.Existing = append(d.Existing, f)
+		} else {
+			f.BaselineStatus = BaselineNew
+			d.New = append(d.New, f)
+		}
+	}
+	for fp, f := range oldByFP {
+		if _, ok := currentByFP[fp]; !ok && !errored[fp] {
+			f.BaselineStatus = BaselineResolved
+			d.Resolved = append(d.Resolved, f)
+		}
+	}
+	for _, list := range [][]Finding{d.New, d.Existing, d.Resolved} {
+		sortFindings(list)
+	}
+	return d
+}
+
+func violationsByFingerprint(findings []Finding) map[string]Finding {
+	m := make(map[string]Finding, len(findings))
+	for _, f := range findings {
+		if f.isViolation() {
+			m[f.Fingerprint] = f
+		}
+	}
+	return m
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.ResourceID < b.ResourceID
+	})
+}
+
+// BaselineWriter compares a run with the violations of a previous run. It
+// marks each violation new or existing before passing it on and, on Close,
+// writes a resolved record for each baseline violation that no longer
+// occurs. A resource whose audit failed this run is not reported resolved.
+type BaselineWriter struct {
+	w        ResultWriter
+	baseline map[string]Finding
+	seen     map[string]bool
+
+	New, Existing, Resolved int
+}
+
+// NewBaselineWriter wraps w with the violations in baseline.
+func NewBaselineWriter(w ResultWriter, baseline []Finding) *BaselineWriter {
+	return &BaselineWriter{
+		w:        w,
+		baseline: violationsByFingerprint(baseline),
+		seen:     make(map[string]bool),
+	}
+}
+
+// IncludesCompliant reports whether the wrapped writer wants compliant
+// results.
+func (b *BaselineWriter) IncludesCompliant() bool {
+	return includesCompliant(b.w)
+}
+
+// SetCloud forwards the cloud name to the wrapped writer.
+func (b *BaselineWriter) SetCloud(name string) {
+	if cs, ok := b.w.(CloudSetter); ok {
+		cs.SetCloud(name)
+	}
+}
+
+func (b *BaselineWriter) WriteResult(r *audit.Result) error {
+	if !r.Compliant {
+		fp := ResultFingerprint(r)
+		b.seen[fp] = true
+		if r.Error == nil {
+			if _, ok := b.baseline[fp]; ok {
+				r.BaselineStatus = BaselineExisting
+				b.Existing++
+			} else {
+				r.BaselineStatus = BaselineNew
+				b.New++
+			}
+		}
+	}
+	return b.w.WriteResult(r)
+}
+
+// Close writes resolved records and closes the wrapped writer.
+func (b *BaselineWriter) Close() error {
+	var resolved []Finding
+	for fp, f := range b.baseline {
+		if !b.seen[fp] {
+			resolved = append(resolved, f)
+		}
+	}
+	sortFindings(resolved)
+
+	var firstErr error
+	for _, f := range resolved {
+		b.Resolved++
+		if err := b.w.WriteResult(resolvedResult(f)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := b.w.Close(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// ResultFingerprint returns the fingerprint of r's finding.
+func ResultFingerprint(r *audit.Result) string {
+	var service, resourceType string
+	if r.Rule != nil {
+		service, resourceType = r.Rule.Service, r.Rule.Resource
+	}
+	return Fingerprint(r.RuleID, service, resourceType, r.ResourceID, r.Cloud, r.Region)
+}
+
+// resolvedResult rebuilds a baseline finding as a compliant result marked
+// resolved.
+func resolvedResult(f Finding) *audit.Result {
+	return &audit.Result{
+		RuleID:         f.RuleID,
+		ResourceID:     f.ResourceID,
+		ResourceName:   f.ResourceName,
+		ProjectID:      f.ProjectID,
+		Cloud:          f.Cloud,
+		Region:         f.Region,
+		Compliant:      true,
+		Severity:       f.Severity,
+		Category:       f.Category,
+		GuideRef:       f.GuideRef,
+		Observation:    f.Observation,
+		BaselineStatus: BaselineResolved,
+		Rule: &policy.Rule{
+			Name:     f.RuleID,
+			Service:  f.Service,
+			Resource: f.ResourceType,
+			Action:   f.Action,
+			Severity: f.Severity,
+			Category: f.Category,
+			GuideRef: f.GuideRef,
+		},
+	}
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func TestFingerprint_StableAndDistinct(t *testing.T) {
+	base := Fingerprint("open-ssh", "neutron", "security_group_rule", "sgr-1", "prod", "RegionOne")
+	if base != Fingerprint("open-ssh", "neutron", "security_group_rule", "sgr-1", "prod", "RegionOne") {
+		t.Fatal("Fingerprint() is not deterministic")
+	}
+	if len(base) != 32 {
+		t.Fatalf("len(Fingerprint()) = %d, want 32", len(base))
+	}
+	for _, other := range []string{
+		Fingerprint("open-rdp", "neutron", "security_group_rule", "sgr-1", "prod", "RegionOne"),
+		Fingerprint("open-ssh", "neutron", "security_group_rule", "sgr-2", "prod", "RegionOne"),
+		Fingerprint("open-ssh", "neutron", "security_group_rule", "sgr-1", "staging", "RegionOne"),
+		Fingerprint("open-ssh", "neutron", "security_group_rule", "sgr-1", "prod", "RegionTwo"),
+		// Field boundaries matter.
+		Fingerprint("open-ssh", "neutron", "security_group_rul", "esgr-1", "prod", "RegionOne"),
+	} {
+		if other == base {
+			t.Errorf("fingerprint collision: %s", other)
+		}
+	}
+}
+
+func TestNewFinding_SetsFingerprint(t *testing.T) {
+	r := &audit.Result{RuleID: "r1", ResourceID: "vm-1", Cloud: "prod", Region: "RegionOne", Rule: &policy.Rule{Service: "nova", Resource: "instance"}}
+	f := NewFinding(r)
+	if f.Fingerprint != Fingerprint("r1", "nova", "instance", "vm-1", "prod", "RegionOne") || f.Fingerprint != ResultFingerprint(r) {
+		t.Fatalf("Fingerprint = %q", f.Fingerprint)
+	}
+}
+
+func violationResult(rule, id string) *audit.Result {
+	return &audit.Result{RuleID: rule, ResourceID: id, Cloud: "prod", Severity: "high", Rule: &policy.Rule{Name: rule, Service: "nova", Resource: "instance"}}
+}
+
+func TestDiff_SplitsByFingerprint(t *testing.T) {
+	old := []Finding{
+		NewFinding(violationResult("r1", "a")),
+		NewFinding(violationResult("r1", "b")),
+		NewFinding(&audit.Result{RuleID: "r1", ResourceID: "c", Compliant: true, Rule: &policy.Rule{Service: "nova", Resource: "instance"}}),
+	}
+	current := []Finding{
+		NewFinding(violationResult("r1", "b")),
+		NewFinding(violationResult("r1", "d")),
+	}
+
+	d := Diff(old, current)
+	if len(d.New) != 1 || d.New[0].ResourceID != "d" || d.New[0].BaselineStatus != BaselineNew {
+		t.Errorf("New = %+v", d.New)
+	}
+	if len(d.Existing) != 1 || d.Existing[0].ResourceID != "b" {
+		t.Errorf("Existing = %+v", d.Existing)
+	}
+	if len(d.Resolved) != 1 || d.Resolved[0].ResourceID != "a" || d.Resolved[0].BaselineStatus != BaselineResolved {
+		t.Errorf("Resolved = %+v", d.Resolved)
+	}
+}
+
+func TestDiff_ErroredAuditIsNotResolved(t *testing.T) {
+	old := []Finding{
+		NewFinding(violationResult("r1", "fixed")),
+		NewFinding(violationResult("r1", "unreachable")),
+	}
+	errored := violationResult("r1", "unreachable")
+	errored.Error = errors.New("timeout")
+	current := []Finding{NewFinding(errored)}
+
+	d := Diff(old, current)
+	if len(d.Resolved) != 1 || d.Resolved[0].ResourceID != "fixed" {
+		t.Errorf("Resolved = %+v, want only the fixed resource", d.Resolved)
+	}
+	if len(d.New) != 0 || len(d.Existing) != 0 {
+		t.Errorf("New = %+v, Existing = %+v, want none for an audit error", d.New, d.Existing)
+	}
+
+	// --baseline gives the same answer for the same inputs.
+	bw := NewBaselineWriter(NewJSONWriter(&bytes.Buffer{}), old)
+	if err := bw.WriteResult(errored); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if bw.Resolved != len(d.Resolved) {
+		t.Errorf("BaselineWriter resolved %d, Diff resolved %d", bw.Resolved, len(d.Resolved))
+	}
+}
+
+func TestBaselineWriter_AnnotatesAndEmitsResolved(t *testing.T) {
+	baseline := []Finding{
+		NewFinding(violationResult("r1", "fixed")),
+		NewFinding(violationResult("r1", "still-bad")),
+		NewFinding(violationResult("r1", "unreachable")),
+	}
+
+	var buf bytes.Buffer
+	bw := NewBaselineWriter(NewJSONWriter(&buf), baseline)
+
+	results := make(chan *audit.Result, 4)
+	results <- violationResult("r1", "still-bad")
+	results <- violationResult("r1", "brand-new")
+	errored := violationResult("r1", "unreachable")
+	errored.Error = errors.New("timeout")
+	results <- errored
+	fixed := violationResult("r1", "fixed")
+	fixed.Compliant = true
+	results <- fixed
+	close(results)
+	ConsumeResults(results, bw)
+
+	status := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var f Finding
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		status[f.ResourceID] = f.BaselineStatus
+	}
+	want := map[string]string{"still-bad": BaselineExisting, "brand-new": BaselineNew, "unreachable": "", "fixed": BaselineResolved}
+	for id, s := range want {
+		if got, ok := status[id]; !ok || got != s {
+			t.Errorf("%s baseline_status = %q (written=%v), want %q", id, got, ok, s)
+		}
+	}
+	if bw.New != 1 || bw.Existing != 1 || bw.Resolved != 1 {
+		t.Errorf("counts = new %d existing %d resolved %d", bw.New, bw.Existing, bw.Resolved)
+	}
+}
+
+func TestLoadFindings_FingerprintsLegacyFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	legacy := `{"rule_id":"r1","resource_id":"a","resource_name":"","resource_type":"instance","service":"nova","compliant":false}` + "\n\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := LoadFindings(path)
+	if err != nil {
+		t.Fatalf("LoadFindings() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Fingerprint != Fingerprint("r1", "nova", "instance", "a", "", "") {
+		t.Fatalf("findings = %+v", findings)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFindings(path); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("LoadFindings() error = %v, want line number", err)
+	}
+}
@@ -0,0 +1,203 @@
+package report
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+)
+
+//go:embed templates/report.html.tmpl
+var templateFS embed.FS
+
+var htmlTemplate = template.Must(template.New("report.html.tmpl").ParseFS(templateFS, "templates/report.html.tmpl"))
+
+// severityOrder ranks severities for display, most severe first.
+var severityOrder = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+// HTMLWriter renders a single self-contained HTML report. The report needs
+// totals before any row is printed, so findings are buffered and rendered
+// on Close. Styles and scripts are inlined; the file has no external assets.
+type HTMLWriter struct {
+	w     io.Writer
+	cloud string
+	now   func() time.Time
+
+	findings []Finding
+}
+
+func NewHTMLWriter(w io.Writer) *HTMLWriter {
+	return &HTMLWriter{w: w, now: time.Now}
+}
+
+// SetCloud sets the cloud name shown in the report title.
+func (w *HTMLWriter) SetCloud(name string) {
+	w.cloud = name
+}
+
+func (w *HTMLWriter) WriteResult(r *audit.Result) error {
+	w.findings = append(w.findings, NewFinding(r))
+	return nil
+}
+
+func (w *HTMLWriter) Close() error {
+	return htmlTemplate.Execute(w.w, w.buildReport())
+}
+
+type htmlReport struct {
+	Cloud       string
+	GeneratedAt string
+	Totals      htmlTotals
+	Groups      []htmlGroup
+	Findings    []htmlFinding
+	Errors      []htmlFinding
+}
+
+type htmlTotals struct {
+	Violations int
+	Errors     int
+	Remediated int
+	Skipped    int
+	Failed     int
+
+	// New and Resolved are set when the run is compared with a baseline.
+	New      int
+	Resolved int
+}
+
+// htmlGroup is one summary card: violation counts keyed by a dimension.
+type htmlGroup struct {
+	Title  string
+	Counts []htmlCount
+}
+
+type htmlCount struct {
+	Key   string
+	Count int
+}
+
+type htmlFinding struct {
+	Finding
+	RemediationStatus string
+	RemediationState  string
+	SeverityRank      int
+}
+
+func (w *HTMLWriter) buildReport() htmlReport {
+	rep := htmlReport{
+		Cloud:       w.cloud,
+		GeneratedAt: w.now().UTC().Format(time.RFC3339),
+	}
+
+	bySeverity := map[string]int{}
+	byCategory := map[string]int{}
+	byService := map[string]int{}
+	byProject := map[string]int{}
+
+	for _, f := range w.findings {
+		state, status := remediationStatus(f)
+		hf := htmlFinding{Finding: f, RemediationStatus: status, RemediationState: state, SeverityRank: severityRank(f.Severity)}
+		if f.BaselineStatus == BaselineResolved {
+			rep.Totals.Resolved++
+			continue
+		}
+		if f.BaselineStatus == BaselineNew {
+			rep.Totals.New++
+		}
+		if f.Error != "" {
+			rep.Totals.Errors++
+			rep.Errors = append(rep.Errors, hf)
+			continue
+		}
+		if f.RemediationError != "" {
+			rep.Totals.Errors++
+			rep.Totals.Failed++
+		}
+		if f.Compliant {
+			// A compliant result carrying a remediation error; list it but
+			// do not count it as a violation.
+			rep.Findings = append(rep.Findings, hf)
+			continue
+		}
+
+		rep.Totals.Violations++
+		if f.Remediated {
+			rep.Totals.Remediated++
+		}
+		if f.RemediationSkipped {
+			rep.Totals.Skipped++
+		}
+		bySeverity[orDash(f.Severity)]++
+		byCategory[orDash(f.Category)]++
+		byService[orDash(f.Service)]++
+		byProject[orDash(f.ProjectID)]++
+		rep.Findings = append(rep.Findings, hf)
+	}
+
+	rep.Groups = []htmlGroup{
+		{Title: "By severity", Counts: sortedCounts(bySeverity, func(a, b string) bool { return severityRank(a) < severityRank(b) })},
+		{Title: "By category", Counts: sortedCounts(byCategory, nil)},
+		{Title: "By service", Counts: sortedCounts(byService, nil)},
+		{Title: "By project", Counts: sortedCounts(byProject, nil)},
+	}
+
+	sort.SliceStable(rep.Findings, func(i, j int) bool {
+		return rep.Findings[i].SeverityRank < rep.Findings[j].SeverityRank
+	})
+	return rep
+}
+
+// remediationStatus summarises what happened to a finding's remediation as
+// a short state (used for styling) and a display label.
+func remediationStatus(f Finding) (state, label string) {
+	switch {
+	case f.Remediated:
+		return "remediated", "remediated"
+	case f.RemediationError != "":
+		return "failed", "failed"
+	case f.RemediationSkipped && f.RemediationSkipReason != "":
+		return "skipped", "skipped (" + f.RemediationSkipReason + ")"
+	case f.RemediationSkipped:
+		return "skipped", "skipped"
+	case f.Action == "" || f.Action == "log":
+		return "report-only", "report only"
+	default:
+		return "not-attempted", "not attempted"
+	}
+}
+
+func severityRank(severity string) int {
+	if rank, ok := severityOrder[severity]; ok {
+		return rank
+	}
+	return len(severityOrder)
+}
+
+// sortedCounts orders counts with less, or by descending count and then key
+// when less is nil.
+func sortedCounts(m map[string]int, less func(a, b string) bool) []htmlCount {
+	counts := make([]htmlCount, 0, len(m))
+	for k, v := range m {
+		counts = append(counts, htmlCount{Key: k, Count: v})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if less != nil {
+			return less(counts[i].Key, counts[j].Key)
+		}
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+	return counts
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func TestHTMLWriter_RendersSummaryAndFindings(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewHTMLWriter(&buf)
+	w.SetCloud("prod")
+	w.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	sg := &policy.Rule{Name: "open-ssh", Service: "neutron", Resource: "security_group_rule", Action: "log"}
+	vm := &policy.Rule{Name: "stopped-vm", Service: "nova", Resource: "instance", Action: "delete"}
+	results := []*audit.Result{
+		{RuleID: "open-ssh", ResourceID: "sgr-1", ProjectID: "proj-1", Severity: "critical", Category: "security", GuideRef: "OSSN-0011", Observation: "<tcp/22 open>", Rule: sg},
+		{RuleID: "stopped-vm", ResourceID: "vm-1", ProjectID: "proj-2", Severity: "low", Category: "cost", Rule: vm, RemediationAttempted: true, Remediated: true},
+		{RuleID: "stopped-vm", ResourceID: "vm-2", ProjectID: "proj-2", Error: errors.New("boom"), ErrorKind: audit.ErrorKindAudit, Rule: vm},
+	}
+	for _, r := range results {
+		if err := w.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<title>OSPA report - prod</title>",
+		"Generated 2026-01-02T03:04:05Z",
+		"By severity", "By category", "By service", "By project",
+		`<span class="sev sev-critical">critical</span>`,
+		"OSSN-0011",
+		"&lt;tcp/22 open&gt;",
+		`<td class="status-remediated">remediated`,
+		"report only",
+		"Audit errors",
+		"boom",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<link") || strings.Contains(out, "src=\"http") {
+		t.Error("report references external assets")
+	}
+}
+
+func TestHTMLWriter_BuildReportCounts(t *testing.T) {
+	w := NewHTMLWriter(&bytes.Buffer{})
+	rule := &policy.Rule{Name: "r", Service: "neutron", Resource: "network", Action: "disable"}
+	for _, r := range []*audit.Result{
+		{RuleID: "r", ResourceID: "a", ProjectID: "p1", Severity: "medium", Rule: rule, RemediationSkipped: true, RemediationSkipReason: "dry_run"},
+		{RuleID: "r", ResourceID: "b", ProjectID: "p1", Severity: "high", Rule: rule, RemediationError: errors.New("denied")},
+		{RuleID: "r", ResourceID: "c", ProjectID: "p2", Severity: "high", Rule: rule},
+	} {
+		_ = w.WriteResult(r)
+	}
+
+	rep := w.buildReport()
+	if rep.Totals.Violations != 3 || rep.Totals.Skipped != 1 || rep.Totals.Failed != 1 || rep.Totals.Errors != 1 {
+		t.Fatalf("totals = %+v", rep.Totals)
+	}
+	sev := rep.Groups[0].Counts
+	if len(sev) != 2 || sev[0] != (htmlCount{Key: "high", Count: 2}) || sev[1] != (htmlCount{Key: "medium", Count: 1}) {
+		t.Errorf("severity counts = %+v", sev)
+	}
+	proj := rep.Groups[3].Counts
+	if len(proj) != 2 || proj[0] != (htmlCount{Key: "p1", Count: 2}) {
+		t.Errorf("project counts = %+v", proj)
+	}
+	if rep.Findings[0].Severity != "high" || rep.Findings[2].RemediationStatus != "skipped (dry_run)" {
+		t.Errorf("findings order = %+v", rep.Findings)
+	}
+}
@@ -0,0 +1,168 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+)
+
+// JUnitWriter writes a JUnit XML report so CI systems can show a policy run
+// as test results. Each rule is a testsuite and each evaluated resource a
+// testcase: violations are failures and audit errors are errors. The
+// document needs per-suite totals, so it is written on Close.
+type JUnitWriter struct {
+	w io.Writer
+
+	suites     []*junitSuite
+	suiteIndex map[string]*junitSuite
+}
+
+func NewJUnitWriter(w io.Writer) *JUnitWriter {
+	return &JUnitWriter{w: w, suiteIndex: make(map[string]*junitSuite)}
+}
+
+// IncludesCompliant implements CompliantWriter; compliant resources are
+// passing testcases.
+func (w *JUnitWriter) IncludesCompliant() bool {
+	return true
+}
+
+func (w *JUnitWriter) WriteResult(r *audit.Result) error {
+	if r.BaselineStatus == BaselineResolved {
+		// Resolved baseline records are not evaluated resources.
+		return nil
+	}
+	f := NewFinding(r)
+	suite := w.suite(f.RuleID)
+
+	name := f.ResourceID
+	if f.ResourceName != "" && f.ResourceName != f.ResourceID {
+		name = fmt.Sprintf("%s (%s)", f.ResourceName, f.ResourceID)
+	}
+	tc := junitTestCase{
+		Name:      name,
+		Classname: junitClassname(f),
+	}
+
+	switch {
+	case f.Error != "":
+		tc.Error = &junitProblem{Message: f.Error, Type: orDefault(f.ErrorKind, "error"), Text: junitDetails(f)}
+		suite.Errors++
+	case f.RemediationError != "":
+		tc.Error = &junitProblem{Message: "remediation failed: " + f.RemediationError, Type: orDefault(f.RemediationErrorKind, "remediation"), Text: junitDetails(f)}
+		suite.Errors++
+	case !f.Compliant:
+		message := f.Observation
+		if message == "" {
+			message = "resource does not comply with rule " + f.RuleID
+		}
+		tc.Failure = &junitProblem{Message: message, Type: orDefault(f.Severity, "violation"), Text: junitDetails(f)}
+		suite.Failures++
+	}
+
+	suite.Tests++
+	suite.TestCases = append(suite.TestCases, tc)
+	return nil
+}
+
+func (w *JUnitWriter) Close() error {
+	doc := junitTestSuites{Name: toolName}
+	for _, s := range w.suites {
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Suites = append(doc.Suites, *s)
+	}
+
+	if _, err := io.WriteString(w.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+func (w *JUnitWriter) suite(ruleID string) *junitSuite {
+	if s, ok := w.suiteIndex[ruleID]; ok {
+		return s
+	}
+	s := &junitSuite{Name: ruleID}
+	w.suites = append(w.suites, s)
+	w.suiteIndex[ruleID] = s
+	return s
+}
+
+// junitClassname groups testcases by service and resource type, which CI
+// systems display as the test's class.
+func junitClassname(f Finding) string {
+	parts := []string{"ospa"}
+	if f.Service != "" {
+		parts = append(parts, f.Service)
+	}
+	if f.ResourceType != "" {
+		parts = append(parts, f.ResourceType)
+	}
+	return strings.Join(parts, ".")
+}
+
+// junitDetails is the body of a failure or error element.
+func junitDetails(f Finding) string {
+	var b strings.Builder
+	line := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	line("resource_id", f.ResourceID)
+	line("project_id", f.ProjectID)
+	line("severity", f.Severity)
+	line("category", f.Category)
+	line("action", f.Action)
+	line("remediation_skip_reason", f.RemediationSkipReason)
+	line("guide_ref", f.GuideRef)
+	return b.String()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func TestJUnitWriter_SuitePerRuleCasePerResource(t *testing.T) {
+	sg := &policy.Rule{Name: "open-ssh", Service: "neutron", Resource: "security_group_rule", Action: "log"}
+	vm := &policy.Rule{Name: "stopped-vm", Service: "nova", Resource: "instance", Action: "delete"}
+
+	results := make(chan *audit.Result, 4)
+	results <- &audit.Result{RuleID: "open-ssh", ResourceID: "sgr-1", Compliant: true, Rule: sg}
+	results <- &audit.Result{RuleID: "open-ssh", ResourceID: "sgr-2", Severity: "critical", Observation: "tcp/22 from 0.0.0.0/0", Rule: sg}
+	results <- &audit.Result{RuleID: "stopped-vm", ResourceID: "vm-1", ResourceName: "web", Compliant: true, Rule: vm}
+	results <- &audit.Result{RuleID: "stopped-vm", ResourceID: "vm-2", Error: errors.New("boom"), ErrorKind: audit.ErrorKindAudit, Rule: vm}
+	close(results)
+
+	var buf bytes.Buffer
+	summary := ConsumeResults(results, NewJUnitWriter(&buf))
+	if summary.Written != 4 {
+		t.Fatalf("Written = %d, want compliant results written too", summary.Written)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Fatalf("missing XML header:\n%s", buf.String())
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal JUnit: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 4 || doc.Failures != 1 || doc.Errors != 1 || len(doc.Suites) != 2 {
+		t.Fatalf("testsuites = tests:%d failures:%d errors:%d suites:%d", doc.Tests, doc.Failures, doc.Errors, len(doc.Suites))
+	}
+
+	ssh := doc.Suites[0]
+	if ssh.Name != "open-ssh" || ssh.Tests != 2 || ssh.Failures != 1 {
+		t.Errorf("open-ssh suite = %+v", ssh)
+	}
+	if ssh.TestCases[0].Failure != nil || ssh.TestCases[0].Classname != "ospa.neutron.security_group_rule" {
+		t.Errorf("compliant testcase = %+v", ssh.TestCases[0])
+	}
+	if f := ssh.TestCases[1].Failure; f == nil || f.Message != "tcp/22 from 0.0.0.0/0" || f.Type != "critical" {
+		t.Errorf("failure = %+v", f)
+	}
+
+	vms := doc.Suites[1]
+	if vms.TestCases[0].Name != "web (vm-1)" {
+		t.Errorf("testcase name = %q", vms.TestCases[0].Name)
+	}
+	if e := vms.TestCases[1].Error; e == nil || e.Message != "boom" || e.Type != string(audit.ErrorKindAudit) {
+		t.Errorf("error = %+v", e)
+	}
+}
+
+func TestConsumeResults_SkipsCompliantForOtherWriters(t *testing.T) {
+	results := make(chan *audit.Result, 2)
+	results <- &audit.Result{RuleID: "r1", ResourceID: "a", Compliant: true}
+	results <- &audit.Result{RuleID: "r1", ResourceID: "b"}
+	close(results)
+
+	var buf bytes.Buffer
+	if summary := ConsumeResults(results, NewJSONWriter(&buf)); summary.Written != 1 {
+		t.Fatalf("Written = %d, want 1", summary.Written)
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"errors"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+// MultiWriter fans each result out to several writers, such as findings
+// files, a syslog stream and notifiers. Compliant results only reach writers
+// that implement CompliantWriter.
+type MultiWriter struct {
+	writers []ResultWriter
+}
+
+// NewMultiWriter returns a writer that writes to every non-nil writer.
+func NewMultiWriter(writers ...ResultWriter) *MultiWriter {
+	m := &MultiWriter{}
+	for _, w := range writers {
+		if w != nil {
+			m.writers = append(m.writers, w)
+		}
+	}
+	return m
+}
+
+// IncludesCompliant reports whether any writer wants compliant results.
+func (m *MultiWriter) IncludesCompliant() bool {
+	for _, w := range m.writers {
+		if includesCompliant(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCloud forwards the cloud name to writers that record it.
+func (m *MultiWriter) SetCloud(name string) {
+	for _, w := range m.writers {
+		if cs, ok := w.(CloudSetter); ok {
+			cs.SetCloud(name)
+		}
+	}
+}
+
+// WriteResult writes r to every writer that accepts it. A failing writer
+// does not stop the others; the errors are joined.
+func (m *MultiWriter) WriteResult(r *audit.Result) error {
+	var errs []error
+	for _, w := range m.writers {
+		if !wantsResult(w, r) {
+			continue
+		}
+		if err := w.WriteResult(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiWriter) Close() error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func includesCompliant(w ResultWriter) bool {
+	cw, ok := w.(CompliantWriter)
+	return ok && cw.IncludesCompliant()
+}
+
+// wantsResult applies ConsumeResults' default filter for writer w. Resolved
+// baseline records are compliant but always written.
+func wantsResult(w ResultWriter, r *audit.Result) bool {
+	return includesCompliant(w) || !r.Compliant || r.Error != nil || r.RemediationError != nil ||
+		r.BaselineStatus == BaselineResolved
+}
+
+// FilteredWriter passes only results matching Filter to the wrapped writer.
+type FilteredWriter struct {
+	w      ResultWriter
+	filter policy.FindingFilter
+}
+
+// NewFilteredWriter wraps w with filter. An empty filter returns w as is.
+func NewFilteredWriter(w ResultWriter, filter policy.FindingFilter) ResultWriter {
+	if filter.IsEmpty() {
+		return w
+	}
+	return &FilteredWriter{w: w, filter: filter}
+}
+
+// IncludesCompliant reports whether the wrapped writer wants compliant
+// results.
+func (f *FilteredWriter) IncludesCompliant() bool {
+	return includesCompliant(f.w)
+}
+
+// SetCloud forwards the cloud name to the wrapped writer.
+func (f *FilteredWriter) SetCloud(name string) {
+	if cs, ok := f.w.(CloudSetter); ok {
+		cs.SetCloud(name)
+	}
+}
+
+func (f *FilteredWriter) WriteResult(r *audit.Result) error {
+	var service string
+	if r.Rule != nil {
+		service = r.Rule.Service
+	}
+	if !f.filter.Match(r.Severity, r.Category, service) {
+		return nil
+	}
+	return f.w.WriteResult(r)
+}
+
+func (f *FilteredWriter) Close() error {
+	return f.w.Close()
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func TestMultiWriter_FiltersPerWriter(t *testing.T) {
+	var jsonBuf, junitBuf strings.Builder
+	m := NewMultiWriter(NewJSONWriter(&jsonBuf), nil, NewJUnitWriter(&junitBuf))
+	if !m.IncludesCompliant() {
+		t.Fatal("IncludesCompliant() = false, want true with a JUnit writer")
+	}
+
+	results := make(chan *audit.Result, 2)
+	results <- &audit.Result{RuleID: "r1", ResourceID: "ok", Compliant: true}
+	results <- &audit.Result{RuleID: "r1", ResourceID: "bad"}
+	close(results)
+	ConsumeResults(results, m)
+
+	if strings.Contains(jsonBuf.String(), `"ok"`) || !strings.Contains(jsonBuf.String(), `"bad"`) {
+		t.Errorf("JSON writer got %q, want only the violation", jsonBuf.String())
+	}
+	if !strings.Contains(junitBuf.String(), `name="ok"`) || !strings.Contains(junitBuf.String(), `name="bad"`) {
+		t.Errorf("JUnit writer got %q, want both results", junitBuf.String())
+	}
+}
+
+func TestFilteredWriter_AppliesFilterPerOutput(t *testing.T) {
+	var all, cost strings.Builder
+	m := NewMultiWriter(
+		NewJSONWriter(&all),
+		NewFilteredWriter(NewCSVWriter(&cost), policy.FindingFilter{Categories: []string{"cost"}}),
+	)
+
+	results := make(chan *audit.Result, 2)
+	results <- &audit.Result{RuleID: "old-vm", ResourceID: "vm-1", Category: "cost", Rule: &policy.Rule{Service: "nova"}}
+	results <- &audit.Result{RuleID: "open-ssh", ResourceID: "sgr-1", Category: "security", Rule: &policy.Rule{Service: "neutron"}}
+	close(results)
+	ConsumeResults(results, m)
+
+	if !strings.Contains(all.String(), "vm-1") || !strings.Contains(all.String(), "sgr-1") {
+		t.Errorf("unfiltered output = %q, want both findings", all.String())
+	}
+	if !strings.Contains(cost.String(), "vm-1") || strings.Contains(cost.String(), "sgr-1") {
+		t.Errorf("cost output = %q, want only the cost finding", cost.String())
+	}
+}
+
+func TestNewFilteredWriter_EmptyFilterReturnsWriter(t *testing.T) {
+	w := NewJSONWriter(&strings.Builder{})
+	if got := NewFilteredWriter(w, policy.FindingFilter{}); got != ResultWriter(w) {
+		t.Fatalf("NewFilteredWriter() = %T, want the writer unchanged", got)
+	}
+}
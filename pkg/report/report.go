@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
@@ -16,6 +17,12 @@ type ResultWriter interface {
 	Close() error
 }
 
+// CompliantWriter is implemented by writers that also record compliant
+// results. Other writers only receive violations and errors.
+type CompliantWriter interface {
+	IncludesCompliant() bool
+}
+
 // JSONWriter writes one JSON object per line.
 type JSONWriter struct {
 	enc *json.Encoder
@@ -28,12 +35,15 @@ func NewJSONWriter(w io.Writer) *JSONWriter {
 }
 
 type Finding struct {
+	Fingerprint       string `json:"fingerprint"`
 	RuleID            string `json:"rule_id"`
 	ResourceID        string `json:"resource_id"`
 	ResourceName      string `json:"resource_name"`
 	ResourceType      string `json:"resource_type,omitempty"`
 	Service           string `json:"service,omitempty"`
 	ProjectID         string `json:"project_id,omitempty"`
+	Cloud             string `json:"cloud,omitempty"`
+	Region            string `json:"region,omitempty"`
 	Status            string `json:"status,omitempty"`
 	UpdatedAt         string `json:"updated_at,omitempty"`
 	Compliant         bool   `json:"compliant"`
@@ -53,14 +63,22 @@ type Finding struct {
 	RemediationErrorKind  string `json:"remediation_error_kind,omitempty"`
 	RemediationSkipped    bool   `json:"remediation_skipped,omitempty"`
 	RemediationSkipReason string `json:"remediation_skip_reason,omitempty"`
+
+	RemediationDetails map[string]string `json:"remediation_details,omitempty"`
+
+	// BaselineStatus is new, existing or resolved when --baseline is set.
+	BaselineStatus string `json:"baseline_status,omitempty"`
 }
 
-func (w *JSONWriter) WriteResult(r *audit.Result) error {
+// NewFinding converts an audit result into its reported form.
+func NewFinding(r *audit.Result) Finding {
 	f := Finding{
 		RuleID:                r.RuleID,
 		ResourceID:            r.ResourceID,
 		ResourceName:          r.ResourceName,
 		ProjectID:             r.ProjectID,
+		Cloud:                 r.Cloud,
+		Region:                r.Region,
 		Status:                r.Status,
 		Compliant:             r.Compliant,
 		Severity:              r.Severity,
@@ -71,6 +89,8 @@ func (w *JSONWriter) WriteResult(r *audit.Result) error {
 		RemediationSkipReason: r.RemediationSkipReason,
 		RemediationAttempted:  r.RemediationAttempted,
 		Remediated:            r.Remediated,
+		RemediationDetails:    r.RemediationDetails,
+		BaselineStatus:        r.BaselineStatus,
 	}
 
 	if r.Rule != nil {
@@ -79,6 +99,8 @@ func (w *JSONWriter) WriteResult(r *audit.Result) error {
 		f.Service = r.Rule.Service
 	}
 
+	f.Fingerprint = f.computeFingerprint()
+
 	if !r.UpdatedAt.IsZero() {
 		f.UpdatedAt = r.UpdatedAt.UTC().Format(time.RFC3339)
 	}
@@ -94,7 +116,11 @@ func (w *JSONWriter) WriteResult(r *audit.Result) error {
 			f.RemediationErrorKind = string(r.RemediationErrorKind)
 		}
 	}
-	return w.enc.Encode(f)
+	return f
+}
+
+func (w *JSONWriter) WriteResult(r *audit.Result) error {
+	return w.enc.Encode(NewFinding(r))
 }
 
 func (w *JSONWriter) Close() error {
@@ -110,42 +136,81 @@ type Summary struct {
 	RemediationAttempted int
 	Remediated           int
 	RemediationSkipped   int
+
+	// RemediatedByAction counts successful remediations per action
+	// (e.g. delete, stop, disable).
+	RemediatedByAction map[string]int
+
+	// CloseErr is the error returned when closing the writer. The written
+	// findings may then be incomplete, e.g. an unflushed file.
+	CloseErr error
 }
 
 // ConsumeResults reads results, updates metrics, and writes output (if writer provided).
 func ConsumeResults(results <-chan *audit.Result, writer ResultWriter) Summary {
 	var summary Summary
+	ruleViolations := make(map[metrics.RuleLabels]int)
 
 	for result := range results {
 		summary.Scanned++
 		metrics.IncScanned()
 
+		labels := ruleLabels(result)
+		var action string
+		if result.Rule != nil {
+			action = result.Rule.Action
+			// Rules evaluated without violations, or whose evaluation
+			// failed, are reported as zero.
+			if _, ok := ruleViolations[labels]; !ok {
+				ruleViolations[labels] = 0
+			}
+		}
+
 		if result.Error != nil {
 			summary.Errors++
 			metrics.IncErrors()
+			metrics.RecordError(fmt.Errorf("%s on %s: %w", result.RuleID, result.ResourceID, result.Error))
 		}
 		if result.RemediationError != nil {
 			summary.Errors++
 			metrics.IncErrors()
+			metrics.RecordError(fmt.Errorf("remediating %s on %s: %w", result.RuleID, result.ResourceID, result.RemediationError))
 		}
 		if !result.Compliant {
 			summary.Violations++
 			metrics.IncViolations()
+			// An audit error is not a violation of the rule.
+			if result.Rule != nil && result.Error == nil {
+				ruleViolations[labels]++
+				labels.Project = result.ProjectID
+				metrics.IncRuleViolation(labels)
+			}
 		}
 		if result.RemediationAttempted {
 			summary.RemediationAttempted++
 			metrics.IncRemediationAttempted()
 		}
+		if result.RemediationError != nil {
+			metrics.IncRemediation(action, metrics.OutcomeFailed)
+		}
 		if result.Remediated {
 			summary.Remediated++
 			metrics.IncRemediated()
+			metrics.IncRemediation(action, metrics.OutcomeRemediated)
+			if result.Rule != nil {
+				if summary.RemediatedByAction == nil {
+					summary.RemediatedByAction = make(map[string]int)
+				}
+				summary.RemediatedByAction[result.Rule.Action]++
+			}
 		}
 		if result.RemediationSkipped {
 			summary.RemediationSkipped++
 			metrics.IncRemediationSkipped()
+			metrics.IncRemediation(action, metrics.OutcomeSkipped)
 		}
 
-		if writer != nil && (!result.Compliant || result.Error != nil || result.RemediationError != nil) {
+		if writer != nil && wantsResult(writer, result) {
 			if err := writer.WriteResult(result); err == nil {
 				summary.Written++
 			}
@@ -153,15 +218,41 @@ func ConsumeResults(results <-chan *audit.Result, writer ResultWriter) Summary {
 	}
 
 	if writer != nil {
-		_ = writer.Close()
+		summary.CloseErr = writer.Close()
 	}
+	metrics.SetRuleViolations(ruleViolations)
 
 	return summary
 }
 
+// ruleLabels returns the metric labels of the rule behind r, without the
+// project.
+func ruleLabels(r *audit.Result) metrics.RuleLabels {
+	l := metrics.RuleLabels{
+		Rule:     r.RuleID,
+		Severity: r.Severity,
+		Category: r.Category,
+	}
+	if r.Rule != nil {
+		l.Service = r.Rule.Service
+		l.ResourceType = r.Rule.Resource
+	}
+	return l
+}
+
 func PrintSummary(out io.Writer, summary Summary) {
 	_, _ = fmt.Fprintln(out, "---- Summary ----")
 	_, _ = fmt.Fprintf(out, "Scanned: %d\nViolations: %d\nErrors: %d\n", summary.Scanned, summary.Violations, summary.Errors)
 	_, _ = fmt.Fprintf(out, "Remediation attempted: %d\nRemediated: %d\nRemediation skipped: %d\n",
 		summary.RemediationAttempted, summary.Remediated, summary.RemediationSkipped)
+	if len(summary.RemediatedByAction) > 0 {
+		actions := make([]string, 0, len(summary.RemediatedByAction))
+		for action := range summary.RemediatedByAction {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			_, _ = fmt.Fprintf(out, "  %s: %d\n", action, summary.RemediatedByAction[action])
+		}
+	}
 }
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/metrics"
 	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestJSONWriter_WriteResult_EmitsExpectedFields(t *testing.T) {
@@ -102,3 +104,73 @@ func TestJSONWriter_WriteResult_IncludesErrors(t *testing.T) {
 type errString string
 
 func (e errString) Error() string { return string(e) }
+
+func TestConsumeResults_CountsRemediationsByAction(t *testing.T) {
+	results := make(chan *audit.Result, 3)
+	results <- &audit.Result{RuleID: "r1", Remediated: true, Rule: &policy.Rule{Name: "r1", Action: "stop"}}
+	results <- &audit.Result{RuleID: "r2", Remediated: true, Rule: &policy.Rule{Name: "r2", Action: "stop"}}
+	results <- &audit.Result{RuleID: "r3", Remediated: true, Rule: &policy.Rule{Name: "r3", Action: "disable"}}
+	close(results)
+
+	summary := ConsumeResults(results, nil)
+	if summary.RemediatedByAction["stop"] != 2 || summary.RemediatedByAction["disable"] != 1 {
+		t.Fatalf("RemediatedByAction = %v, want stop=2 disable=1", summary.RemediatedByAction)
+	}
+
+	var buf bytes.Buffer
+	PrintSummary(&buf, summary)
+	if !strings.Contains(buf.String(), "  disable: 1\n  stop: 2\n") {
+		t.Fatalf("summary output missing per-action counts:\n%s", buf.String())
+	}
+}
+
+type failingCloseWriter struct{ written int }
+
+func (w *failingCloseWriter) WriteResult(*audit.Result) error { w.written++; return nil }
+func (w *failingCloseWriter) Close() error                    { return errString("disk full") }
+
+func TestConsumeResults_ReportsCloseError(t *testing.T) {
+	results := make(chan *audit.Result, 1)
+	results <- &audit.Result{RuleID: "r1", Rule: &policy.Rule{Name: "r1", Action: "log"}}
+	close(results)
+
+	w := &failingCloseWriter{}
+	summary := ConsumeResults(results, w)
+	if summary.CloseErr == nil || summary.CloseErr.Error() != "disk full" {
+		t.Fatalf("CloseErr = %v, want the writer's Close error", summary.CloseErr)
+	}
+	if summary.Written != w.written {
+		t.Errorf("Written = %d, want %d", summary.Written, w.written)
+	}
+}
+
+func TestConsumeResults_ErrorsAreNotRuleViolations(t *testing.T) {
+	metrics.Enable()
+	rule := &policy.Rule{Name: "errored-rule", Service: "nova", Resource: "instance", Action: "log"}
+	results := make(chan *audit.Result, 2)
+	results <- &audit.Result{RuleID: rule.Name, Rule: rule, Error: errString("boom")}
+	results <- &audit.Result{RuleID: rule.Name, Rule: rule, Error: errString("boom")}
+	close(results)
+
+	ConsumeResults(results, nil)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "ospa_rule_violations" && mf.GetName() != "ospa_rule_violations_total" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() != "rule" || l.GetValue() != rule.Name {
+					continue
+				}
+				if v := m.GetGauge().GetValue() + m.GetCounter().GetValue(); v != 0 {
+					t.Errorf("%s{rule=%q} = %v, want 0 for errored results", mf.GetName(), rule.Name, v)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "OSPA"
+	toolURI      = "https://github.com/OpenStack-Policy-Agent/OSPA"
+)
+
+// CloudSetter is implemented by writers that include the audited cloud's
+// name in their output.
+type CloudSetter interface {
+	SetCloud(name string)
+}
+
+// SARIFWriter writes a SARIF 2.1.0 log. SARIF is a single JSON document, so
+// results are buffered and written on Close. Each rule becomes a
+// reportingDescriptor; each non-compliant result becomes a SARIF result
+// located at cloud/project/service/resource_type/resource_id. Audit errors
+// are reported as tool execution notifications.
+type SARIFWriter struct {
+	w     io.Writer
+	cloud string
+
+	rules         []sarifRule
+	ruleIndex     map[string]int
+	results       []sarifResult
+	notifications []sarifNotification
+}
+
+func NewSARIFWriter(w io.Writer) *SARIFWriter {
+	return &SARIFWriter{w: w, ruleIndex: make(map[string]int)}
+}
+
+// SetCloud sets the cloud name used as the root of logical locations.
+func (w *SARIFWriter) SetCloud(name string) {
+	w.cloud = name
+}
+
+func (w *SARIFWriter) WriteResult(r *audit.Result) error {
+	f := NewFinding(r)
+	idx := w.addRule(r)
+
+	if r.Error != nil {
+		w.notifications = append(w.notifications, sarifNotification{
+			Level:      "error",
+			Message:    sarifMessage{Text: f.Error},
+			Descriptor: &sarifReference{ID: f.RuleID, Index: idx},
+			Properties: dropEmpty(map[string]string{"resource_id": f.ResourceID, "error_kind": f.ErrorKind}),
+		})
+		return nil
+	}
+	if r.Compliant && r.RemediationError == nil && r.BaselineStatus != BaselineResolved {
+		return nil
+	}
+
+	message := f.Observation
+	if message == "" {
+		message = "Resource does not comply with rule " + f.RuleID
+	}
+	name := f.ResourceName
+	if name == "" {
+		name = f.ResourceID
+	}
+
+	w.results = append(w.results, sarifResult{
+		RuleID:    f.RuleID,
+		RuleIndex: idx,
+		Level:     sarifLevel(f.Severity),
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{{
+			LogicalLocations: []sarifLogicalLocation{{
+				Name:               name,
+				FullyQualifiedName: w.locationName(f),
+				Kind:               "resource",
+			}},
+		}},
+		Properties:    sarifResultProperties(f),
+		BaselineState: sarifBaselineState[f.BaselineStatus],
+		Fingerprints:  map[string]string{"ospa/v1": f.Fingerprint},
+	})
+	return nil
+}
+
+func (w *SARIFWriter) Close() error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          w.rules,
+		}},
+		Results: w.results,
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful:        len(w.notifications) == 0,
+			ToolExecutionNotifications: w.notifications,
+		}},
+	}
+	if run.Tool.Driver.Rules == nil {
+		run.Tool.Driver.Rules = []sarifRule{}
+	}
+	if run.Results == nil {
+		run.Results = []sarifResult{}
+	}
+
+	enc := json.NewEncoder(w.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// addRule registers the result's rule as a reportingDescriptor on first
+// sight and returns its index.
+func (w *SARIFWriter) addRule(r *audit.Result) int {
+	if idx, ok := w.ruleIndex[r.RuleID]; ok {
+		return idx
+	}
+
+	rule := sarifRule{
+		ID:                   r.RuleID,
+		Name:                 r.RuleID,
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		Properties:           map[string]string{},
+	}
+	description := r.RuleID
+	if r.Rule != nil {
+		if r.Rule.Description != "" {
+			description = r.Rule.Description
+		}
+		rule.Properties["service"] = r.Rule.Service
+		rule.Properties["resource"] = r.Rule.Resource
+		rule.Properties["action"] = r.Rule.Action
+	}
+	rule.ShortDescription = sarifMessage{Text: description}
+	if r.Severity != "" {
+		rule.Properties["severity"] = r.Severity
+		if score, ok := securitySeverity[r.Severity]; ok {
+			rule.Properties["security-severity"] = score
+		}
+	}
+	if r.Category != "" {
+		rule.Properties["category"] = r.Category
+	}
+	if r.GuideRef != "" {
+		rule.Properties["guide_ref"] = r.GuideRef
+	}
+
+	idx := len(w.rules)
+	w.rules = append(w.rules, rule)
+	w.ruleIndex[r.RuleID] = idx
+	return idx
+}
+
+func (w *SARIFWriter) locationName(f Finding) string {
+	parts := []string{w.cloud, f.ProjectID, f.Service, f.ResourceType, f.ResourceID}
+	for i, p := range parts {
+		if p == "" {
+			parts[i] = "-"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// sarifLevel maps OSPA severities to SARIF levels.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "low":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// securitySeverity holds the CVSS-like scores code-scanning dashboards use
+// to rank security findings.
+var securitySeverity = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "3.0",
+}
+
+func sarifResultProperties(f Finding) map[string]string {
+	props := map[string]string{
+		"project_id":    f.ProjectID,
+		"service":       f.Service,
+		"resource_type": f.ResourceType,
+		"resource_id":   f.ResourceID,
+		"status":        f.Status,
+		"action":        f.Action,
+	}
+	if f.RemediationSkipReason != "" {
+		props["remediation_skip_reason"] = f.RemediationSkipReason
+	}
+	if f.Remediated {
+		props["remediated"] = "true"
+	}
+	if f.RemediationError != "" {
+		props["remediation_error"] = f.RemediationError
+	}
+	return dropEmpty(props)
+}
+
+// dropEmpty removes keys with empty values.
+func dropEmpty(m map[string]string) map[string]string {
+	for k, v := range m {
+		if v == "" {
+			delete(m, k)
+		}
+	}
+	return m
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID        string            `json:"ruleId"`
+	RuleIndex     int               `json:"ruleIndex"`
+	Level         string            `json:"level"`
+	Message       sarifMessage      `json:"message"`
+	Locations     []sarifLocation   `json:"locations"`
+	Fingerprints  map[string]string `json:"fingerprints,omitempty"`
+	BaselineState string            `json:"baselineState,omitempty"`
+	Properties    map[string]string `json:"properties,omitempty"`
+}
+
+// sarifBaselineState maps baseline statuses to SARIF baselineState values.
+var sarifBaselineState = map[string]string{
+	BaselineNew:      "new",
+	BaselineExisting: "unchanged",
+	BaselineResolved: "absent",
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Descriptor *sarifReference   `json:"associatedRule,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifReference struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func TestSARIFWriter_WritesRulesResultsAndNotifications(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter("sarif", &buf)
+	if err != nil {
+		t.Fatalf("NewWriter(sarif) error = %v", err)
+	}
+	w.(CloudSetter).SetCloud("prod")
+
+	rule := &policy.Rule{Name: "open-ssh", Description: "SSH open to the world", Service: "neutron", Resource: "security_group_rule", Action: "log"}
+	results := []*audit.Result{
+		{RuleID: "open-ssh", ResourceID: "sgr-1", ProjectID: "proj-1", Severity: "critical", Category: "security", GuideRef: "OSSN-0011", Observation: "tcp/22 from 0.0.0.0/0", Rule: rule},
+		{RuleID: "open-ssh", ResourceID: "sgr-2", ProjectID: "proj-2", Severity: "critical", Rule: rule},
+		{RuleID: "open-ssh", ResourceID: "sgr-3", Error: errors.New("boom"), ErrorKind: audit.ErrorKindAudit, Rule: rule},
+	}
+	for _, r := range results {
+		if err := w.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal SARIF: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version=%q runs=%d, want 2.1.0 with one run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 1 {
+		t.Fatalf("rules = %+v, want one descriptor", run.Tool.Driver.Rules)
+	}
+	desc := run.Tool.Driver.Rules[0]
+	if desc.ID != "open-ssh" || desc.ShortDescription.Text != "SSH open to the world" || desc.DefaultConfiguration.Level != "error" {
+		t.Errorf("descriptor = %+v", desc)
+	}
+	if desc.Properties["guide_ref"] != "OSSN-0011" || desc.Properties["security-severity"] != "9.5" {
+		t.Errorf("descriptor properties = %v", desc.Properties)
+	}
+
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(run.Results))
+	}
+	loc := run.Results[0].Locations[0].LogicalLocations[0]
+	if loc.FullyQualifiedName != "prod/proj-1/neutron/security_group_rule/sgr-1" {
+		t.Errorf("fullyQualifiedName = %q", loc.FullyQualifiedName)
+	}
+	if run.Results[0].Message.Text != "tcp/22 from 0.0.0.0/0" || run.Results[0].RuleIndex != 0 {
+		t.Errorf("result = %+v", run.Results[0])
+	}
+
+	inv := run.Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 1 {
+		t.Fatalf("invocation = %+v, want one error notification", inv)
+	}
+	if inv.ToolExecutionNotifications[0].Message.Text != "boom" {
+		t.Errorf("notification = %+v", inv.ToolExecutionNotifications[0])
+	}
+}
+
+func TestSARIFWriter_EmptyRunIsValid(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewSARIFWriter(&buf)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var log map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal SARIF: %v", err)
+	}
+	run := log["runs"].([]any)[0].(map[string]any)
+	if results, ok := run["results"].([]any); !ok || len(results) != 0 {
+		t.Errorf("results = %#v, want empty array", run["results"])
+	}
+}
@@ -0,0 +1,266 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+)
+
+// Version is reported as the product version in CEF headers. It can be set
+// at build time with -ldflags "-X .../pkg/report.Version=...".
+var Version = "dev"
+
+const cefVendor = "OpenStack-Policy-Agent"
+
+// CEFWriter writes one ArcSight Common Event Format line per finding.
+type CEFWriter struct {
+	w     io.Writer
+	cloud string
+	now   func() time.Time
+}
+
+func NewCEFWriter(w io.Writer) *CEFWriter {
+	return &CEFWriter{w: w, now: time.Now}
+}
+
+// SetCloud sets the cloud name recorded with each event.
+func (w *CEFWriter) SetCloud(name string) {
+	w.cloud = name
+}
+
+func (w *CEFWriter) WriteResult(r *audit.Result) error {
+	_, err := io.WriteString(w.w, formatCEF(NewFinding(r), w.cloud, w.now())+"\n")
+	return err
+}
+
+func (w *CEFWriter) Close() error {
+	return nil
+}
+
+// ECSWriter writes one Elastic Common Schema JSON document per line.
+type ECSWriter struct {
+	enc   *json.Encoder
+	cloud string
+	now   func() time.Time
+}
+
+func NewECSWriter(w io.Writer) *ECSWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &ECSWriter{enc: enc, now: time.Now}
+}
+
+// SetCloud sets the cloud name recorded with each event.
+func (w *ECSWriter) SetCloud(name string) {
+	w.cloud = name
+}
+
+func (w *ECSWriter) WriteResult(r *audit.Result) error {
+	return w.enc.Encode(ecsEvent(NewFinding(r), w.cloud, w.now()))
+}
+
+func (w *ECSWriter) Close() error {
+	return nil
+}
+
+// cefSeverity maps OSPA severities to the CEF 0-10 scale.
+func cefSeverity(f Finding) int {
+	if f.Error != "" {
+		return 5
+	}
+	switch f.Severity {
+	case "critical":
+		return 10
+	case "high":
+		return 8
+	case "medium":
+		return 5
+	case "low":
+		return 3
+	default:
+		return 1
+	}
+}
+
+// findingMessage is the human-readable summary of a finding.
+func findingMessage(f Finding) string {
+	switch {
+	case f.Error != "":
+		return f.Error
+	case f.BaselineStatus == BaselineResolved:
+		return "violation of rule " + f.RuleID + " resolved"
+	case f.Compliant:
+		return "resource complies with rule " + f.RuleID
+	case f.Observation != "":
+		return f.Observation
+	default:
+		return "resource does not comply with rule " + f.RuleID
+	}
+}
+
+// formatCEF renders f as a CEF:0 event. Rule and resource identifiers use
+// the custom string extensions so SIEM parsers keep them as fields.
+func formatCEF(f Finding, cloud string, t time.Time) string {
+	name := "Policy violation"
+	switch {
+	case f.Error != "":
+		name = "Audit error"
+	case f.BaselineStatus == BaselineResolved:
+		name = "Resolved violation"
+	case f.Compliant:
+		name = "Compliant resource"
+	}
+	state, _ := remediationStatus(f)
+
+	header := []string{
+		"CEF:0",
+		cefHeader(cefVendor),
+		cefHeader(toolName),
+		cefHeader(Version),
+		cefHeader(f.RuleID),
+		cefHeader(name),
+		strconv.Itoa(cefSeverity(f)),
+	}
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtension(value))
+		}
+	}
+	add("rt", strconv.FormatInt(t.UnixMilli(), 10))
+	add("act", f.Action)
+	add("cat", f.Category)
+	add("msg", findingMessage(f))
+	add("outcome", state)
+	add("cs1Label", "rule")
+	add("cs1", f.RuleID)
+	add("cs2Label", "project_id")
+	add("cs2", f.ProjectID)
+	add("cs3Label", "service")
+	add("cs3", f.Service)
+	add("cs4Label", "resource_type")
+	add("cs4", f.ResourceType)
+	if f.GuideRef != "" {
+		add("cs5Label", "guide_ref")
+		add("cs5", f.GuideRef)
+	}
+	if cloud != "" {
+		add("cs6Label", "cloud")
+		add("cs6", cloud)
+	}
+	add("flexString1Label", "resource_id")
+	add("flexString1", f.ResourceID)
+	if f.ResourceName != "" {
+		add("flexString2Label", "resource_name")
+		add("flexString2", f.ResourceName)
+	}
+	if f.RemediationError != "" {
+		add("reason", f.RemediationError)
+	}
+
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " ")
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func cefHeader(s string) string    { return cefHeaderEscaper.Replace(s) }
+func cefExtension(s string) string { return cefExtensionEscaper.Replace(s) }
+
+// ecsEvent maps f onto Elastic Common Schema fields. Fields with no ECS
+// equivalent live under the "ospa" namespace.
+func ecsEvent(f Finding, cloud string, t time.Time) map[string]any {
+	outcome := "failure"
+	kind := "alert"
+	switch {
+	case f.Error != "":
+		outcome = "unknown"
+		kind = "event"
+	case f.Compliant:
+		outcome = "success"
+		kind = "event"
+	}
+	state, _ := remediationStatus(f)
+
+	cloudFields := map[string]any{"provider": "openstack"}
+	if f.ProjectID != "" {
+		cloudFields["project"] = map[string]any{"id": f.ProjectID}
+	}
+	if f.Service != "" {
+		cloudFields["service"] = map[string]any{"name": f.Service}
+	}
+
+	doc := map[string]any{
+		"@timestamp": t.UTC().Format(time.RFC3339Nano),
+		"message":    findingMessage(f),
+		"ecs":        map[string]any{"version": "8.11.0"},
+		"event": map[string]any{
+			"kind":     kind,
+			"category": []string{"configuration"},
+			"type":     []string{"info"},
+			"outcome":  outcome,
+			"module":   "ospa",
+			"dataset":  "ospa.findings",
+			"severity": cefSeverity(f),
+		},
+		"observer": map[string]any{"vendor": cefVendor, "product": toolName, "version": Version},
+		"rule": dropEmptyAny(map[string]any{
+			"name":      f.RuleID,
+			"id":        f.RuleID,
+			"category":  f.Category,
+			"reference": f.GuideRef,
+			"ruleset":   f.Service,
+		}),
+		"cloud": cloudFields,
+		"ospa": dropEmptyAny(map[string]any{
+			"resource": dropEmptyAny(map[string]any{
+				"id":     f.ResourceID,
+				"name":   f.ResourceName,
+				"type":   f.ResourceType,
+				"status": f.Status,
+			}),
+			"fingerprint":     f.Fingerprint,
+			"baseline_status": f.BaselineStatus,
+			"severity":        f.Severity,
+			"action":          f.Action,
+			"compliant":       f.Compliant,
+			"remediation":     dropEmptyAny(map[string]any{"status": state, "skip_reason": f.RemediationSkipReason, "error": f.RemediationError}),
+		}),
+	}
+	if cloud != "" {
+		doc["labels"] = map[string]any{"cloud": cloud}
+	}
+	if f.Error != "" {
+		doc["error"] = dropEmptyAny(map[string]any{"message": f.Error, "type": f.ErrorKind})
+	}
+	return doc
+}
+
+// dropEmptyAny removes empty-string values.
+func dropEmptyAny(m map[string]any) map[string]any {
+	for k, v := range m {
+		if s, ok := v.(string); ok && s == "" {
+			delete(m, k)
+		}
+	}
+	return m
+}
+
+// marshalJSON encodes v on a single line without HTML escaping.
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,265 @@
+package report
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+)
+
+// Syslog message formats.
+const (
+	SyslogFormatRFC5424 = "rfc5424"
+	SyslogFormatCEF     = "cef"
+	SyslogFormatECS     = "ecs"
+)
+
+const (
+	syslogFacilityLocal0 = 16
+	syslogAppName        = "ospa"
+	// syslogSDID is the structured data ID; 32473 is the enterprise number
+	// RFC 5612 reserves for documentation and private use.
+	syslogSDID = "ospa@32473"
+)
+
+// SyslogConfig configures a SyslogWriter.
+type SyslogConfig struct {
+	// Network is "udp", "tcp" or "tls".
+	Network string
+	// Address is the collector's host:port.
+	Address string
+	// Format is the message body: rfc5424 (structured data plus a text
+	// message), cef or ecs. Defaults to rfc5424.
+	Format string
+	// Hostname is reported in the syslog header. Defaults to os.Hostname.
+	Hostname string
+	// TLSConfig is used when Network is "tls".
+	TLSConfig *tls.Config
+	// Timeout bounds dialing and each write. Defaults to 10s.
+	Timeout time.Duration
+}
+
+// SyslogWriter streams each result as an RFC 5424 syslog message. TCP and
+// TLS use octet-counted framing (RFC 6587); UDP sends one datagram per
+// message.
+type SyslogWriter struct {
+	cfg      SyslogConfig
+	conn     net.Conn
+	hostname string
+	procID   string
+	cloud    string
+	now      func() time.Time
+}
+
+// NewSyslogWriter validates cfg and connects to the collector.
+func NewSyslogWriter(cfg SyslogConfig) (*SyslogWriter, error) {
+	cfg.Network = strings.ToLower(strings.TrimSpace(cfg.Network))
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	switch cfg.Network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q (want udp, tcp or tls)", cfg.Network)
+	}
+	cfg.Format = strings.ToLower(strings.TrimSpace(cfg.Format))
+	if cfg.Format == "" {
+		cfg.Format = SyslogFormatRFC5424
+	}
+	switch cfg.Format {
+	case SyslogFormatRFC5424, SyslogFormatCEF, SyslogFormatECS:
+	default:
+		return nil, fmt.Errorf("unsupported syslog format %q (want rfc5424, cef or ecs)", cfg.Format)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	w := &SyslogWriter{
+		cfg:      cfg,
+		hostname: syslogHeaderField(hostname),
+		procID:   strconv.Itoa(os.Getpid()),
+		now:      time.Now,
+	}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetCloud sets the cloud name recorded with each message.
+func (w *SyslogWriter) SetCloud(name string) {
+	w.cloud = name
+}
+
+func (w *SyslogWriter) WriteResult(r *audit.Result) error {
+	msg, err := w.format(NewFinding(r))
+	if err != nil {
+		return err
+	}
+	if err := w.send(msg); err != nil {
+		// Stream connections may have been dropped by the collector;
+		// reconnect once before giving up on this message.
+		if w.cfg.Network == "udp" {
+			return err
+		}
+		_ = w.conn.Close()
+		if derr := w.dial(); derr != nil {
+			return fmt.Errorf("syslog write: %w (reconnect: %v)", err, derr)
+		}
+		return w.send(msg)
+	}
+	return nil
+}
+
+func (w *SyslogWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+func (w *SyslogWriter) dial() error {
+	var (
+		conn net.Conn
+		err  error
+	)
+	dialer := &net.Dialer{Timeout: w.cfg.Timeout}
+	if w.cfg.Network == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.cfg.Address, w.cfg.TLSConfig)
+	} else {
+		conn, err = dialer.Dial(w.cfg.Network, w.cfg.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to syslog %s://%s: %w", w.cfg.Network, w.cfg.Address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *SyslogWriter) send(msg []byte) error {
+	if w.cfg.Network != "udp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(w.cfg.Timeout))
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// format renders an RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (w *SyslogWriter) format(f Finding) ([]byte, error) {
+	t := w.now()
+	msgID := "finding"
+	if f.Error != "" {
+		msgID = "error"
+	}
+
+	sd := "-"
+	var body string
+	switch w.cfg.Format {
+	case SyslogFormatCEF:
+		body = formatCEF(f, w.cloud, t)
+	case SyslogFormatECS:
+		data, err := marshalJSON(ecsEvent(f, w.cloud, t))
+		if err != nil {
+			return nil, fmt.Errorf("encoding ECS event: %w", err)
+		}
+		body = string(data)
+	default:
+		sd = w.structuredData(f)
+		body = findingMessage(f)
+	}
+
+	pri := syslogFacilityLocal0*8 + syslogSeverity(f)
+	header := fmt.Sprintf("<%d>1 %s %s %s %s %s %s",
+		pri, t.UTC().Format(time.RFC3339Nano), w.hostname, syslogAppName, w.procID, msgID, sd)
+	return []byte(header + " " + body), nil
+}
+
+func (w *SyslogWriter) structuredData(f Finding) string {
+	state, _ := remediationStatus(f)
+	params := []struct{ key, value string }{
+		{"rule_id", f.RuleID},
+		{"cloud", w.cloud},
+		{"project_id", f.ProjectID},
+		{"service", f.Service},
+		{"resource_type", f.ResourceType},
+		{"resource_id", f.ResourceID},
+		{"resource_name", f.ResourceName},
+		{"compliant", strconv.FormatBool(f.Compliant)},
+		{"severity", f.Severity},
+		{"category", f.Category},
+		{"guide_ref", f.GuideRef},
+		{"action", f.Action},
+		{"remediation", state},
+		{"error_kind", f.ErrorKind},
+		{"fingerprint", f.Fingerprint},
+		{"baseline", f.BaselineStatus},
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ` %s="%s"`, p.key, sdEscaper.Replace(p.value))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogSeverity maps a finding to an RFC 5424 severity.
+func syslogSeverity(f Finding) int {
+	if f.Error != "" {
+		return 3 // error
+	}
+	if f.Compliant {
+		return 6 // informational
+	}
+	switch f.Severity {
+	case "critical":
+		return 2
+	case "high":
+		return 3
+	case "medium":
+		return 4
+	case "low":
+		return 5
+	default:
+		return 4
+	}
+}
+
+// syslogHeaderField returns s as a header field: printable ASCII without
+// spaces, or "-" when empty.
+func syslogHeaderField(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	return s
+}
@@ -0,0 +1,269 @@
+package report
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/audit"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testViolation() *audit.Result {
+	return &audit.Result{
+		RuleID:      "open-ssh",
+		ResourceID:  "sgr-1",
+		ProjectID:   "proj-1",
+		Severity:    "critical",
+		Category:    "security",
+		GuideRef:    "OSSN-0011",
+		Observation: `tcp/22 open to "0.0.0.0/0"`,
+		Rule:        &policy.Rule{Name: "open-ssh", Service: "neutron", Resource: "security_group_rule", Action: "log"},
+	}
+}
+
+func TestSyslogWriter_UDP_RFC5424(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Hostname: "agent host"})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	w.now = func() time.Time { return testNow }
+	w.SetCloud("prod")
+	if err := w.WriteResult(testViolation()); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+	_ = w.Close()
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// local0 (16) * 8 + critical (2) = 130
+	wantPrefix := "<130>1 2026-01-02T03:04:05Z agenthost ospa " + w.procID + " finding [ospa@32473 rule_id=\"open-ssh\" cloud=\"prod\""
+	if !strings.HasPrefix(msg, wantPrefix) {
+		t.Fatalf("message = %q\nwant prefix %q", msg, wantPrefix)
+	}
+	if !strings.HasSuffix(msg, `] tcp/22 open to "0.0.0.0/0"`) {
+		t.Fatalf("message body = %q", msg)
+	}
+	if !strings.Contains(msg, `guide_ref="OSSN-0011"`) || !strings.Contains(msg, `remediation="report-only"`) {
+		t.Errorf("structured data missing fields: %q", msg)
+	}
+}
+
+func TestSyslogWriter_TCP_OctetCountedCEF(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for {
+			lenField, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(lenField))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				break
+			}
+			msgs = append(msgs, string(msg))
+		}
+		received <- msgs
+	}()
+
+	w, err := NewSyslogWriter(SyslogConfig{Network: "tcp", Address: ln.Addr().String(), Format: SyslogFormatCEF})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	w.now = func() time.Time { return testNow }
+	errResult := &audit.Result{RuleID: "open-ssh", ResourceID: "sgr-2", Error: errors.New("boom"), ErrorKind: audit.ErrorKindAudit}
+	for _, r := range []*audit.Result{testViolation(), errResult} {
+		if err := w.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult() error = %v", err)
+		}
+	}
+	_ = w.Close()
+
+	var msgs []string
+	select {
+	case msgs = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("received %d messages, want 2: %q", len(msgs), msgs)
+	}
+	if !strings.Contains(msgs[0], " finding - CEF:0|OpenStack-Policy-Agent|OSPA|") ||
+		!strings.Contains(msgs[0], "|open-ssh|Policy violation|10|") {
+		t.Errorf("CEF message = %q", msgs[0])
+	}
+	if !strings.HasPrefix(msgs[1], "<131>1 ") || !strings.Contains(msgs[1], "|Audit error|5|") || !strings.Contains(msgs[1], "msg=boom") {
+		t.Errorf("error message = %q", msgs[1])
+	}
+}
+
+func TestSyslogWriter_TLS(t *testing.T) {
+	// Borrow httptest's self-signed certificate for the listener.
+	certSrv := httptest.NewUnstartedServer(nil)
+	certSrv.StartTLS()
+	cert := certSrv.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(certSrv.Certificate())
+	certSrv.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	w, err := NewSyslogWriter(SyslogConfig{
+		Network:   "tls",
+		Address:   ln.Addr().String(),
+		Format:    SyslogFormatECS,
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogWriter() error = %v", err)
+	}
+	if err := w.WriteResult(testViolation()); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+	_ = w.Close()
+
+	select {
+	case got := <-received:
+		if !strings.Contains(got, ` finding - {"@timestamp":`) || !strings.Contains(got, `"rule":{`) {
+			t.Errorf("TLS message = %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestNewSyslogWriter_RejectsBadConfig(t *testing.T) {
+	for _, cfg := range []SyslogConfig{
+		{Network: "sctp", Address: "127.0.0.1:514"},
+		{Network: "udp", Address: "127.0.0.1:514", Format: "gelf"},
+		{Network: "udp"},
+	} {
+		if _, err := NewSyslogWriter(cfg); err == nil {
+			t.Errorf("NewSyslogWriter(%+v) expected error", cfg)
+		}
+	}
+}
+
+func TestFormatCEF_EscapesAndMapsFields(t *testing.T) {
+	f := NewFinding(testViolation())
+	f.RuleID = "a|b"
+	f.Observation = "x=y\nz"
+	got := formatCEF(f, "prod", testNow)
+
+	for _, want := range []string{
+		"CEF:0|OpenStack-Policy-Agent|OSPA|" + Version + `|a\|b|Policy violation|10|`,
+		"rt=1767323045000",
+		`msg=x\=y\nz`,
+		"cs2Label=project_id cs2=proj-1",
+		"cs5Label=guide_ref cs5=OSSN-0011",
+		"cs6Label=cloud cs6=prod",
+		"flexString1Label=resource_id flexString1=sgr-1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CEF %q missing %q", got, want)
+		}
+	}
+}
+
+func TestECSWriter_MapsFields(t *testing.T) {
+	var buf strings.Builder
+	w := NewECSWriter(&buf)
+	w.now = func() time.Time { return testNow }
+	w.SetCloud("prod")
+	if err := w.WriteResult(testViolation()); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+
+	var doc struct {
+		Timestamp string `json:"@timestamp"`
+		Message   string `json:"message"`
+		Event     struct {
+			Kind    string `json:"kind"`
+			Outcome string `json:"outcome"`
+		} `json:"event"`
+		Rule struct {
+			Name      string `json:"name"`
+			Reference string `json:"reference"`
+		} `json:"rule"`
+		Cloud struct {
+			Provider string `json:"provider"`
+			Project  struct {
+				ID string `json:"id"`
+			} `json:"project"`
+		} `json:"cloud"`
+		Labels map[string]string `json:"labels"`
+		OSPA   struct {
+			Resource struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"resource"`
+		} `json:"ospa"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &doc); err != nil {
+		t.Fatalf("unmarshal ECS: %v\n%s", err, buf.String())
+	}
+	if doc.Timestamp != "2026-01-02T03:04:05Z" || doc.Event.Kind != "alert" || doc.Event.Outcome != "failure" {
+		t.Errorf("event = %+v timestamp=%q", doc.Event, doc.Timestamp)
+	}
+	if doc.Rule.Name != "open-ssh" || doc.Rule.Reference != "OSSN-0011" {
+		t.Errorf("rule = %+v", doc.Rule)
+	}
+	if doc.Cloud.Provider != "openstack" || doc.Cloud.Project.ID != "proj-1" || doc.Labels["cloud"] != "prod" {
+		t.Errorf("cloud = %+v labels = %v", doc.Cloud, doc.Labels)
+	}
+	if doc.OSPA.Resource.ID != "sgr-1" || doc.OSPA.Resource.Type != "security_group_rule" {
+		t.Errorf("ospa = %+v", doc.OSPA)
+	}
+}
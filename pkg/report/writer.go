@@ -13,6 +13,16 @@ func NewWriter(format string, w io.Writer) (ResultWriter, error) {
 		return NewJSONWriter(w), nil
 	case "csv":
 		return NewCSVWriter(w), nil
+	case "sarif":
+		return NewSARIFWriter(w), nil
+	case "html":
+		return NewHTMLWriter(w), nil
+	case "junit":
+		return NewJUnitWriter(w), nil
+	case "cef":
+		return NewCEFWriter(w), nil
+	case "ecs":
+		return NewECSWriter(w), nil
 	default:
 		return nil, fmt.Errorf("unsupported output format: %s", format)
 	}
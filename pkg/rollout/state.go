@@ -0,0 +1,124 @@
+package rollout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/atomicfile"
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+// RuleState is the rollout progress of one rule.
+type RuleState struct {
+	Stage          int       `json:"stage"`
+	StageStartedAt time.Time `json:"stage_started_at"`
+}
+
+// State tracks the active rollout stage of each rule in a JSON file.
+type State struct {
+	path  string
+	mu    sync.Mutex
+	rules map[string]*RuleState
+}
+
+// Open loads the state at path. A missing file yields an empty state.
+func Open(path string) (*State, error) {
+	s := &State{path: path, rules: make(map[string]*RuleState)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading rollout state %q: %w", path, err)
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.rules); err != nil {
+		return nil, fmt.Errorf("parsing rollout state %q: %w", path, err)
+	}
+	return s, nil
+}
+
+// Advance starts the first stage of new rollouts and moves each rollout on
+// by one stage once the active stage's min_soak has elapsed. The state is
+// saved when anything changed.
+func (s *State) Advance(rules []policy.Rule, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, rule := range rules {
+		if rule.Rollout == nil || len(rule.Rollout.Stages) == 0 {
+			continue
+		}
+		stages := rule.Rollout.Stages
+
+		rs, ok := s.rules[rule.Name]
+		if !ok {
+			s.rules[rule.Name] = &RuleState{Stage: 0, StageStartedAt: now.UTC()}
+			changed = true
+			continue
+		}
+		if rs.Stage >= len(stages) {
+			// The rollout lost stages since the last run.
+			rs.Stage = len(stages) - 1
+			changed = true
+		}
+		if rs.Stage == len(stages)-1 {
+			continue
+		}
+
+		soak, err := stages[rs.Stage].SoakDuration()
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if soak > 0 && now.Sub(rs.StageStartedAt) >= soak {
+			rs.Stage++
+			rs.StageStartedAt = now.UTC()
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+// ActiveStage returns the 0-based active stage for ruleName. Rules without
+// recorded state are in their first stage.
+func (s *State) ActiveStage(ruleName string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rs, ok := s.rules[ruleName]; ok {
+		return rs.Stage
+	}
+	return 0
+}
+
+// Get returns the recorded state for ruleName.
+func (s *State) Get(ruleName string) (RuleState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, ok := s.rules[ruleName]
+	if !ok {
+		return RuleState{}, false
+	}
+	return *rs, true
+}
+
+// saveLocked writes the state atomically. The caller must hold s.mu.
+func (s *State) saveLocked() error {
+	if err := atomicfile.WriteJSON(s.path, s.rules); err != nil {
+		return fmt.Errorf("writing rollout state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package rollout
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OpenStack-Policy-Agent/OSPA/pkg/policy"
+)
+
+func testRules() []policy.Rule {
+	return []policy.Rule{
+		{Name: "no-rollout"},
+		{Name: "staged", Rollout: &policy.RolloutOptions{Stages: []policy.RolloutStage{
+			{Projects: []string{"canary"}, MinSoak: "1d"},
+			{Percent: 25, MinSoak: "2d"},
+			{Percent: 100},
+		}}},
+	}
+}
+
+func TestState_AdvanceAfterSoak(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rollout.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		at   time.Duration
+		want int
+	}{
+		{0, 0},
+		{23 * time.Hour, 0},
+		{24 * time.Hour, 1},
+		{48 * time.Hour, 1}, // second stage soaks from when it started
+		{72 * time.Hour, 2},
+		{30 * 24 * time.Hour, 2},
+	}
+	for _, step := range steps {
+		if err := s.Advance(testRules(), start.Add(step.at)); err != nil {
+			t.Fatalf("Advance() error = %v", err)
+		}
+		if got := s.ActiveStage("staged"); got != step.want {
+			t.Fatalf("after %s: stage = %d, want %d", step.at, got, step.want)
+		}
+	}
+	if _, ok := s.Get("no-rollout"); ok {
+		t.Error("rules without rollout should not be tracked")
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := reopened.ActiveStage("staged"); got != 2 {
+		t.Fatalf("reopened stage = %d, want 2", got)
+	}
+}
+
+func TestState_ClampsRemovedStages(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "rollout.json"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.rules["staged"] = &RuleState{Stage: 5, StageStartedAt: time.Now()}
+
+	if err := s.Advance(testRules(), time.Now()); err != nil {
+		t.Fatalf("Advance() error = %v", err)
+	}
+	if got := s.ActiveStage("staged"); got != 2 {
+		t.Fatalf("stage = %d, want clamped to 2", got)
+	}
+}
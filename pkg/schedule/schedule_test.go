@@ -0,0 +1,87 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvery_Next(t *testing.T) {
+	s := Every(90 * time.Minute)
+	start := time.Date(2026, 5, 1, 10, 7, 0, 0, time.UTC)
+	if got, want := s.Next(start), start.Add(90*time.Minute); !got.Equal(want) {
+		t.Fatalf("Next() = %s, want %s", got, want)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	// 2026-05-01 is a Friday.
+	start := time.Date(2026, 5, 1, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 5, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 5, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 5, 2, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1-5", time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches.
+		{"0 0 13 * 6", time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error = %v", tt.expr, err)
+		}
+		if got := c.Next(start); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next() = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCron_NextNever(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	if got := c.Next(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Fatalf("Next() = %s, want zero time", got)
+	}
+}
+
+func TestCron_Location(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	c, err := ParseCron("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	got := c.Next(time.Date(2026, 5, 1, 10, 0, 0, 0, loc))
+	if want := time.Date(2026, 5, 2, 3, 0, 0, 0, loc); !got.Equal(want) {
+		t.Fatalf("Next() = %s, want %s", got, want)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
@@ -16,8 +16,8 @@ import (
 //
 // Supported resources:
 //   - volume: Block storage volumes
-//     Checks: status, age_gt, unused, exempt_names
-//     Actions: log, delete, tag
+//     Checks: status, age_gt, unused, exempt_names, encrypted, attached
+//     Actions: log, delete, detach
 //   - snapshot: Volume snapshots
 //     Checks: status, age_gt, unused, exempt_names
 //     Actions: log, delete, tag
@@ -16,8 +16,8 @@ import (
 //
 // Supported resources:
 //   - instance: Server instances
-//     Checks: status, age_gt, unused, exempt_names
-//     Actions: log, delete, tag
+//     Checks: status, age_gt, unused, exempt_names, no_keypair
+//     Actions: log, delete, stop, shelve, quarantine
 //   - keypair: SSH keypairs
 //     Checks: status, age_gt, unused, exempt_names
 //     Actions: log, delete, tag
@@ -0,0 +1,217 @@
+// Package tracing configures OpenTelemetry tracing for the agent. By default
+// no exporter is installed and every span is a no-op.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gophercloud/gophercloud"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/OpenStack-Policy-Agent/OSPA"
+
+// Exporter names accepted by Config.Exporter.
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+// Span attribute keys shared by the instrumented packages.
+const (
+	AttrService      = attribute.Key("ospa.service")
+	AttrResourceType = attribute.Key("ospa.resource_type")
+	AttrResourceID   = attribute.Key("ospa.resource_id")
+	AttrRule         = attribute.Key("ospa.rule")
+	AttrAction       = attribute.Key("ospa.action")
+)
+
+// Config selects where spans are exported.
+type Config struct {
+	// Exporter is "none" (default), "otlp" or "file".
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector URL, e.g.
+	// http://localhost:4318. When empty the exporter honours the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variables.
+	Endpoint string
+	// File is the path spans are appended to as JSON with the file exporter.
+	File string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+}
+
+// Setup installs the global tracer provider described by cfg. The returned
+// function flushes pending spans and must be called before exiting.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return noop, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exp, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+		}
+		exporter = exp
+	case ExporterFile:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("file trace exporter requires a file path")
+		}
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("creating file exporter: %w", err)
+		}
+		exporter = fileExporter{SpanExporter: exp, file: f}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want none, otlp or file)", cfg.Exporter)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "ospa"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// fileExporter closes the trace file when the exporter shuts down.
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (e fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if closeErr := e.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Tracer returns the agent's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InstrumentClient wraps the HTTP transport of client's provider so every
+// OpenStack API request is traced. Clients that are already instrumented are
+// left unchanged.
+//
+// gophercloud v1 does not pass a context to its requests, so these spans are
+// the roots of their own traces rather than children of the run.
+func InstrumentClient(client *gophercloud.ServiceClient) {
+	if client == nil || client.ProviderClient == nil {
+		return
+	}
+	if _, ok := client.HTTPClient.Transport.(*Transport); ok {
+		return
+	}
+	client.HTTPClient.Transport = &Transport{Base: client.HTTPClient.Transport}
+}
+
+// Handler wraps h so every request it serves is traced as a server span
+// named operation. A traceparent header sent by the caller is continued.
+func Handler(h http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(h, operation)
+}
+
+// Transport is an http.RoundTripper that records a client span per request
+// and propagates the trace context in the request headers.
+type Transport struct {
+	// Base performs the request; http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := Tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", redactedURL(req)),
+			attribute.String("server.address", req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// redactedURL returns the request URL without user info and query string,
+// which may carry credentials or tokens.
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	return u.String()
+}
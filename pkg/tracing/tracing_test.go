@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useRecorder installs an in-memory tracer provider for the test.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return rec
+}
+
+func TestTransport_RecordsSpanAndPropagates(t *testing.T) {
+	rec := useRecorder(t)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Get(srv.URL + "/v2.0/ports?token=secret")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "HTTP GET" || span.Status().Code != codes.Error {
+		t.Errorf("span = %s, status %v", span.Name(), span.Status())
+	}
+	if traceparent == "" || !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace %s", traceparent, span.SpanContext().TraceID())
+	}
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if got := attrs["url.full"]; got != srv.URL+"/v2.0/ports" {
+		t.Errorf("url.full = %q, want query string removed", got)
+	}
+	if got := attrs["http.response.status_code"]; got != "404" {
+		t.Errorf("http.response.status_code = %q", got)
+	}
+}
+
+func TestHandler_RecordsServerSpan(t *testing.T) {
+	rec := useRecorder(t)
+
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), "ospa.api"))
+	defer srv.Close()
+
+	// The client span's context is propagated to the server span.
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Get(srv.URL + "/v1/findings")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	server, clientSpan := spans[0], spans[1]
+	if server.Name() != "ospa.api" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span = %s (%v)", server.Name(), server.SpanKind())
+	}
+	if server.Parent().SpanID() != clientSpan.SpanContext().SpanID() {
+		t.Errorf("server span parent = %s, want the client span %s", server.Parent().SpanID(), clientSpan.SpanContext().SpanID())
+	}
+}
+
+func TestStartEnd_ChildSpanWithError(t *testing.T) {
+	rec := useRecorder(t)
+
+	ctx, parent := Start(context.Background(), "ospa.run")
+	_, child := Start(ctx, "ospa.check", AttrRule.String("open-ssh"), AttrResourceID.String("sg-1"))
+	End(child, os.ErrNotExist)
+	parent.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	check := spans[0]
+	if check.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("check span is not a child of the run span")
+	}
+	if check.Status().Code != codes.Error || len(check.Events()) != 1 {
+		t.Errorf("error not recorded: %v, %d events", check.Status(), len(check.Events()))
+	}
+}
+
+func TestSetup(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup(none) error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	if otel.GetTracerProvider() != prev {
+		t.Error("the default exporter must not install a tracer provider")
+	}
+
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("expected error for unknown exporter")
+	}
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterFile}); err == nil {
+		t.Error("expected error for file exporter without a path")
+	}
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err = Setup(context.Background(), Config{Exporter: ExporterFile, File: path})
+	if err != nil {
+		t.Fatalf("Setup(file) error = %v", err)
+	}
+	_, span := Start(context.Background(), "ospa.run")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"Name":"ospa.run"`) {
+		t.Errorf("trace file = %s", data)
+	}
+}